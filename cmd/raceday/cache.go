@@ -0,0 +1,169 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+	"github.com/jfmyers/tmux-raceday/internal/config"
+)
+
+// autoPruneInterval is how often status and TUI runs enforce cache limits.
+const autoPruneInterval = time.Hour
+
+const cacheUsage = `usage: raceday cache <command>
+
+Commands:
+  ls      list cached files, oldest first
+  stats   show totals per kind of data
+  clear   remove every cached file
+  prune   remove files beyond the configured age and size limits
+`
+
+func cachePolicy(cfg config.Config) cache.Policy {
+	return cache.Policy{
+		MaxAge:  time.Duration(cfg.Cache.MaxAge),
+		MaxSize: int64(cfg.Cache.MaxSizeMB) << 20,
+	}
+}
+
+// runCacheCmd implements `raceday cache ls|stats|clear|prune`.
+func runCacheCmd(cfg config.Config, args []string, out io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, cacheUsage)
+		return 2
+	}
+
+	c := cache.New("")
+	switch args[0] {
+	case "ls":
+		entries, err := c.List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "raceday: %v\n", err)
+			return 1
+		}
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "KEY\tSIZE\tAGE")
+		for _, e := range entries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", e.Key, formatBytes(e.Size), formatAge(time.Since(e.ModTime)))
+		}
+		tw.Flush()
+
+	case "stats":
+		st, err := c.Stats()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "raceday: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(out, "Directory: %s\n", c.Dir())
+		fmt.Fprintf(out, "Files:     %d\n", st.Files)
+		fmt.Fprintf(out, "Size:      %s\n", formatBytes(st.Bytes))
+		if st.Files > 0 {
+			fmt.Fprintf(out, "Oldest:    %s ago\n", formatAge(time.Since(st.Oldest)))
+			fmt.Fprintf(out, "Newest:    %s ago\n", formatAge(time.Since(st.Newest)))
+		}
+		fmt.Fprintf(out, "Limits:    %s\n", formatPolicy(cachePolicy(cfg)))
+
+		kinds := make([]string, 0, len(st.ByKind))
+		for k := range st.ByKind {
+			kinds = append(kinds, k)
+		}
+		sort.Slice(kinds, func(i, j int) bool {
+			return st.ByKind[kinds[i]].Bytes > st.ByKind[kinds[j]].Bytes
+		})
+		if len(kinds) > 0 {
+			fmt.Fprintln(out)
+			tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "KIND\tFILES\tSIZE")
+			for _, k := range kinds {
+				fmt.Fprintf(tw, "%s\t%d\t%s\n", k, st.ByKind[k].Files, formatBytes(st.ByKind[k].Bytes))
+			}
+			tw.Flush()
+		}
+
+	case "clear":
+		removed, err := c.Clear()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "raceday: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(out, "Removed %s\n", summarizeEntries(removed))
+
+	case "prune":
+		policy := cachePolicy(cfg)
+		fs := flag.NewFlagSet("prune", flag.ContinueOnError)
+		maxAge := fs.Duration("max-age", policy.MaxAge, "Remove files older than this (0=no limit)")
+		maxSize := fs.Int("max-size-mb", cfg.Cache.MaxSizeMB, "Shrink the cache to this many MB (0=no limit)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return 2
+		}
+		policy = cache.Policy{MaxAge: *maxAge, MaxSize: int64(*maxSize) << 20}
+		removed, err := c.Prune(policy)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "raceday: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(out, "Pruned %s (%s)\n", summarizeEntries(removed), formatPolicy(policy))
+
+	default:
+		fmt.Fprintf(os.Stderr, "raceday: unknown cache command %q\n\n%s", args[0], cacheUsage)
+		return 2
+	}
+	return 0
+}
+
+func summarizeEntries(entries []cache.Entry) string {
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+	noun := "files"
+	if len(entries) == 1 {
+		noun = "file"
+	}
+	return fmt.Sprintf("%d %s, %s", len(entries), noun, formatBytes(total))
+}
+
+func formatPolicy(p cache.Policy) string {
+	age, size := "no age limit", "no size limit"
+	if p.MaxAge > 0 {
+		age = "max age " + formatAge(p.MaxAge)
+	}
+	if p.MaxSize > 0 {
+		size = "max size " + formatBytes(p.MaxSize)
+	}
+	return age + ", " + size
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. "1.5 MB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
+
+// formatAge renders a duration at the coarsest useful unit, e.g. "3d", "5h", "12m".
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours())/24)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+}
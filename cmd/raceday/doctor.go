@@ -0,0 +1,410 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/events"
+	"github.com/jfmyers/tmux-raceday/internal/f1"
+	"github.com/jfmyers/tmux-raceday/internal/fetch"
+	"github.com/jfmyers/tmux-raceday/internal/nascar"
+	"github.com/jfmyers/tmux-raceday/internal/notify"
+	"github.com/jfmyers/tmux-raceday/internal/schema"
+	"github.com/jfmyers/tmux-raceday/internal/series"
+	"github.com/jfmyers/tmux-raceday/internal/weather"
+)
+
+// doctorFeedTimeout bounds each feed fetch in `raceday doctor --feeds`.
+const doctorFeedTimeout = 15 * time.Second
+
+// doctorProbeTimeout bounds each endpoint probe and schedule lookup.
+const doctorProbeTimeout = 10 * time.Second
+
+// doctorMaxIssues caps how many schema issues are listed per feed.
+const doctorMaxIssues = 10
+
+// doctorMaxRaces caps how many races are named in a single finding.
+const doctorMaxRaces = 5
+
+// lookPath finds external tools. Tests replace it.
+var lookPath = exec.LookPath
+
+// report prints doctor findings and counts failures and warnings.
+type report struct {
+	out            io.Writer
+	sections       int
+	failed, warned int
+}
+
+func (r *report) section(name string) {
+	if r.sections > 0 {
+		fmt.Fprintln(r.out)
+	}
+	r.sections++
+	fmt.Fprintln(r.out, name)
+}
+
+func (r *report) ok(format string, args ...any) {
+	fmt.Fprintf(r.out, "  ✓ "+format+"\n", args...)
+}
+
+func (r *report) warn(format string, args ...any) {
+	r.warned++
+	fmt.Fprintf(r.out, "  ! "+format+"\n", args...)
+}
+
+func (r *report) fail(format string, args ...any) {
+	r.failed++
+	fmt.Fprintf(r.out, "  ✗ "+format+"\n", args...)
+}
+
+// hint suggests how to fix the previous finding.
+func (r *report) hint(format string, args ...any) {
+	fmt.Fprintf(r.out, "      → "+format+"\n", args...)
+}
+
+// runDoctorCmd implements `raceday doctor`. It returns 1 if any check
+// failed; warnings alone don't change the exit code.
+func runDoctorCmd(cfg config.Config, args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	cfgFlag := fs.Bool("config", false, "Check that the config file parses and its values are valid")
+	cacheFlag := fs.Bool("cache", false, "Check that the cache directory is writable")
+	network := fs.Bool("network", false, "Check that each provider endpoint is reachable")
+	schedule := fs.Bool("schedule", false, "Check each series' schedule and track coordinates")
+	tools := fs.Bool("tools", false, "Check for tmux and a desktop notifier")
+	feeds := fs.Bool("feeds", false, "Fetch every configured feed and check it against the expected schema")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	// With no section flags, run every section.
+	all := !*cfgFlag && !*cacheFlag && !*network && !*schedule && !*tools && !*feeds
+
+	ctx := context.Background()
+	now := time.Now()
+	r := &report{out: out}
+	if *cfgFlag || all {
+		r.section("Config")
+		checkConfig(r, config.Path(), cfg)
+	}
+	if *cacheFlag || all {
+		r.section("Cache")
+		checkCache(r, cache.New(""))
+	}
+	if *network || all {
+		r.section("Network")
+		checkNetwork(ctx, r, cfg, probeTargets(cfg))
+	}
+	if *schedule || all {
+		r.section("Schedule")
+		checkSchedule(ctx, r, cfg, newSeries(cfg.Series), now)
+	}
+	if *tools || all {
+		r.section("Tools")
+		checkTools(r, runtime.GOOS)
+	}
+	if *feeds || all {
+		r.section("Feeds")
+		if cfg.Offline {
+			r.warn("offline mode: skipping feed checks")
+		} else {
+			checkFeeds(ctx, r, feedChecks(cfg, now))
+		}
+	}
+
+	fmt.Fprintln(out)
+	switch {
+	case r.failed > 0:
+		fmt.Fprintf(out, "%d failed, %d %s\n", r.failed, r.warned, plural(r.warned, "warning", "warnings"))
+		return 1
+	case r.warned > 0:
+		fmt.Fprintf(out, "No failures, %d %s\n", r.warned, plural(r.warned, "warning", "warnings"))
+	default:
+		fmt.Fprintln(out, "Everything looks good")
+	}
+	return 0
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// checkConfig parses the config file strictly and validates it. Without a
+// config file the in-effect defaults are validated instead.
+func checkConfig(r *report, path string, cfg config.Config) {
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		r.warn("no config file at %s; using defaults", path)
+		r.hint("run `raceday --init-config` to create one")
+	case err != nil:
+		r.fail("reading %s: %v", path, err)
+		return
+	default:
+		parsed, err := config.Parse(data)
+		if err != nil {
+			r.fail("%s does not parse: %v", path, err)
+			r.hint("fix the YAML, or move the file aside and run `raceday --init-config`")
+			return
+		}
+		r.ok("%s parses", path)
+		// Keep command-line and environment overrides in effect.
+		parsed.Offline = cfg.Offline
+		parsed.Endpoints = cfg.Endpoints
+		cfg = parsed
+	}
+	errs := cfg.Validate()
+	if _, err := compileStatusFormat(cfg.StatusFormat); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := notify.New(cfg.Notify, cfg.Offline); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := events.CompileRules(cfg.Notify.Rules); err != nil {
+		errs = append(errs, err)
+	}
+	for _, err := range errs {
+		r.fail("%v", err)
+	}
+	if len(errs) > 0 {
+		r.hint("edit %s", path)
+		return
+	}
+	r.ok("series: %s", strings.Join(cfg.Series, ", "))
+}
+
+// checkCache verifies the cache directory can be written.
+func checkCache(r *report, c *cache.Cache) {
+	if err := c.Check(); err != nil {
+		r.fail("cache directory %s is not writable: %v", c.Dir(), err)
+		r.hint("check the permissions on %s, or set XDG_CACHE_HOME", c.Dir())
+		return
+	}
+	st, err := c.Stats()
+	if err != nil {
+		r.ok("%s writable", c.Dir())
+		return
+	}
+	r.ok("%s writable (%d files, %s)", c.Dir(), st.Files, formatBytes(st.Bytes))
+}
+
+// probeTarget is one provider endpoint checked by the network section.
+type probeTarget struct {
+	name string
+	url  string
+	env  string // environment variable overriding the endpoint
+}
+
+// probeTargets lists the endpoints the configured series and weather use.
+func probeTargets(cfg config.Config) []probeTarget {
+	var targets []probeTarget
+	if slices.Contains(cfg.Series, "nascar") {
+		targets = append(targets, probeTarget{"NASCAR", nascar.ProbeURL(), "RACEDAY_NASCAR_URL"})
+	}
+	if slices.Contains(cfg.Series, "f1") {
+		targets = append(targets, probeTarget{"OpenF1", f1.ProbeURL(), "RACEDAY_OPENF1_URL"})
+	}
+	if cfg.Weather {
+		targets = append(targets, probeTarget{"Open-Meteo", weather.ProbeURL(), "RACEDAY_OPEN_METEO_URL"})
+	}
+	return targets
+}
+
+// checkNetwork makes one request to each endpoint and reports its latency
+// and circuit breaker state.
+func checkNetwork(ctx context.Context, r *report, cfg config.Config, targets []probeTarget) {
+	if cfg.Offline {
+		r.warn("offline mode: skipping network checks")
+		return
+	}
+	now := time.Now()
+	for _, t := range targets {
+		pctx, cancel := context.WithTimeout(ctx, doctorProbeTimeout)
+		latency, err := fetch.Probe(pctx, t.url)
+		cancel()
+		if err != nil {
+			r.fail("%-10s unreachable: %v", t.name, err)
+			r.hint("check your connection, or point %s at a reachable server", t.env)
+		} else {
+			r.ok("%-10s reachable (%s)", t.name, latency.Round(time.Millisecond))
+		}
+
+		st := fetch.Status(t.url)
+		switch {
+		case st.OpenUntil.After(now):
+			r.warn("%-10s circuit breaker open for %s after %d failures", t.name, formatAge(st.OpenUntil.Sub(now)), st.Failures)
+			r.hint("requests are skipped until then; raceday serves cached data meanwhile")
+		case st.RetryAfter.After(now):
+			r.warn("%-10s rate limited for another %s", t.name, formatAge(st.RetryAfter.Sub(now)))
+		}
+	}
+}
+
+// newSeries builds the series named in the config, in order. Unknown
+// names are skipped.
+func newSeries(names []string) []series.Series {
+	var all []series.Series
+	for _, name := range names {
+		switch name {
+		case "nascar":
+			all = append(all, nascar.NewSeries())
+		case "f1":
+			all = append(all, f1.NewSeries())
+		}
+	}
+	return all
+}
+
+// checkSchedule reports whether each series has a schedule for the
+// current season, when its next race is, and which upcoming races lack
+// track coordinates (and so get no weather).
+func checkSchedule(ctx context.Context, r *report, cfg config.Config, all []series.Series, now time.Time) {
+	for _, s := range all {
+		sctx, cancel := context.WithTimeout(ctx, doctorProbeTimeout)
+		races, err := s.FetchSchedule(sctx, now.Year())
+		if err != nil {
+			cancel()
+			r.fail("%-6s no %d schedule: %v", s.ShortName(), now.Year(), err)
+			r.hint("run `raceday doctor --network` to check the %s endpoint", s.Name())
+			continue
+		}
+		if len(races) == 0 {
+			cancel()
+			r.fail("%-6s %d schedule is empty", s.ShortName(), now.Year())
+			continue
+		}
+		var upcoming []series.Race
+		for _, race := range races {
+			if !race.Complete && !race.StartTime.Before(now) {
+				upcoming = append(upcoming, race)
+			}
+		}
+		r.ok("%-6s %d schedule: %d races, %d upcoming", s.ShortName(), now.Year(), len(races), len(upcoming))
+
+		next, err := series.NextRace(sctx, s, now, time.Duration(cfg.ScheduleHorizon))
+		cancel()
+		switch {
+		case err != nil:
+			r.warn("%-6s next race lookup failed: %v", s.ShortName(), err)
+		case next == nil:
+			r.warn("%-6s no race within %s", s.ShortName(), formatAge(time.Duration(cfg.ScheduleHorizon)))
+			r.hint("raise schedule_horizon if the next season is further out")
+		default:
+			r.ok("%-6s next: %s in %d days", s.ShortName(), next.RaceName, daysUntil(now, next.StartTime))
+			if len(upcoming) == 0 {
+				upcoming = append(upcoming, *next)
+			}
+		}
+
+		var missing []string
+		for _, race := range upcoming {
+			if race.Lat == 0 && race.Lon == 0 {
+				missing = append(missing, race.TrackName)
+			}
+		}
+		if len(missing) == 0 {
+			r.ok("%-6s track coordinates for all %d upcoming races", s.ShortName(), len(upcoming))
+			continue
+		}
+		names := slices.Compact(slices.Sorted(slices.Values(missing)))
+		if len(names) > doctorMaxRaces {
+			names = append(names[:doctorMaxRaces:doctorMaxRaces], fmt.Sprintf("%d more", len(names)-doctorMaxRaces))
+		}
+		r.warn("%-6s no track coordinates for %d upcoming %s: %s",
+			s.ShortName(), len(missing), plural(len(missing), "race", "races"), strings.Join(names, ", "))
+		r.hint("weather is unavailable for these races")
+	}
+}
+
+// checkTools looks for tmux and the desktop notifier for goos.
+func checkTools(r *report, goos string) {
+	if path, err := lookPath("tmux"); err != nil {
+		r.fail("tmux not found in PATH")
+		r.hint("install tmux; the status line is only shown inside tmux")
+	} else {
+		version := path
+		if out, err := exec.Command(path, "-V").Output(); err == nil {
+			version = strings.TrimSpace(string(out))
+		}
+		r.ok("%s", version)
+		if os.Getenv("TMUX") == "" {
+			r.warn("not running inside a tmux session")
+		}
+	}
+
+	var notifier string
+	switch goos {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		notifier = "notify-send"
+	case "darwin":
+		notifier = "osascript"
+	default:
+		return
+	}
+	if path, err := lookPath(notifier); err != nil {
+		r.warn("%s not found; desktop notifications are unavailable", notifier)
+		if notifier == "notify-send" {
+			r.hint("install libnotify (often packaged as libnotify-bin)")
+		}
+	} else {
+		r.ok("%s", path)
+	}
+}
+
+// feedChecks lists the feeds of every configured series.
+func feedChecks(cfg config.Config, now time.Time) []schema.Check {
+	var checks []schema.Check
+	if slices.Contains(cfg.Series, "nascar") {
+		checks = append(checks, nascar.FeedChecks(now)...)
+	}
+	if slices.Contains(cfg.Series, "f1") {
+		checks = append(checks, f1.FeedChecks(now)...)
+	}
+	return checks
+}
+
+// checkFeeds fetches each feed directly, bypassing the cache, and
+// reports whether it still matches the expected schema.
+func checkFeeds(ctx context.Context, r *report, checks []schema.Check) {
+	for _, check := range checks {
+		fctx, cancel := context.WithTimeout(ctx, doctorFeedTimeout)
+		start := time.Now()
+		data, err := fetch.Get(fctx, check.URL)
+		elapsed := time.Since(start)
+		cancel()
+
+		if err != nil {
+			r.fail("%-20s fetch failed: %v", check.Feed, err)
+			continue
+		}
+		err = check.Validate(data)
+		schema.Record(check.Feed, err)
+		var se *schema.Error
+		if errors.As(err, &se) {
+			r.fail("%-20s %d schema %s", check.Feed, len(se.Issues), plural(len(se.Issues), "issue", "issues"))
+			for i, is := range se.Issues {
+				if i == doctorMaxIssues {
+					fmt.Fprintf(r.out, "      … %d more\n", len(se.Issues)-i)
+					break
+				}
+				fmt.Fprintf(r.out, "      %s\n", is)
+			}
+			continue
+		}
+		r.ok("%-20s ok (%s, %s)", check.Feed, formatBytes(int64(len(data))), elapsed.Round(time.Millisecond))
+	}
+}
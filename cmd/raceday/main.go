@@ -1,21 +1,30 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jfmyers/tmux-raceday/internal/cache"
 	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/delay"
 	"github.com/jfmyers/tmux-raceday/internal/f1"
+	"github.com/jfmyers/tmux-raceday/internal/fetch"
+	"github.com/jfmyers/tmux-raceday/internal/logging"
 	"github.com/jfmyers/tmux-raceday/internal/nascar"
 	"github.com/jfmyers/tmux-raceday/internal/series"
+	"github.com/jfmyers/tmux-raceday/internal/spoiler"
 	"github.com/jfmyers/tmux-raceday/internal/ui"
 	"github.com/jfmyers/tmux-raceday/internal/weather"
-	"github.com/mattn/go-runewidth"
 )
 
 func main() {
@@ -25,8 +34,17 @@ func main() {
 	width := flag.Int("width", 0, "Fixed output width for status mode (0=use config)")
 	marquee := flag.Bool("marquee", false, "Enable marquee scrolling for long status text")
 	initCfg := flag.Bool("init-config", false, "Create default config file")
+	offline := flag.Bool("offline", false, "Use cached data only; make no network requests")
+	output := flag.String("output", "", "Status output format: "+strings.Join(outputFormats, ", ")+" (implies --status)")
+	tmuxStyle := flag.Bool("tmux-style", false, "Colour --status output with tmux #[fg=…,bg=…] styles")
+	debug := flag.Bool("debug", false, "Write debug logs to log_file (default "+logging.DefaultPath()+")")
 	flag.Parse()
 
+	if *output != "" && !slices.Contains(outputFormats, *output) {
+		fmt.Fprintf(os.Stderr, "raceday: unknown --output %q (want one of %s)\n", *output, strings.Join(outputFormats, ", "))
+		os.Exit(2)
+	}
+
 	if *initCfg {
 		config.EnsureDefault()
 		fmt.Println("Config created at ~/.config/raceday/config.yaml")
@@ -37,6 +55,38 @@ func main() {
 	if *noWeather {
 		cfg.Weather = false
 	}
+	if *offline {
+		cfg.Offline = true
+	}
+	if *tmuxStyle {
+		cfg.StatusStyle.Tmux = true
+	}
+	closeLog := setupLogging(cfg.LogFile, *debug)
+	defer closeLog()
+	slog.Debug("start", "args", os.Args[1:], "series", cfg.Series, "offline", cfg.Offline)
+	httpOpts := httpOptions(cfg.HTTP)
+	httpOpts.Offline = cfg.Offline
+	fetch.Configure(httpOpts)
+	nascar.SetBaseURL(cfg.Endpoints.NASCAR)
+	f1.SetBaseURL(cfg.Endpoints.OpenF1)
+	weather.SetBaseURL(cfg.Endpoints.OpenMeteo)
+
+	switch flag.Arg(0) {
+	case "":
+	case "cache":
+		os.Exit(runCacheCmd(cfg, flag.Args()[1:], os.Stdout))
+	case "doctor":
+		os.Exit(runDoctorCmd(cfg, flag.Args()[1:], os.Stdout))
+	case "mock-server":
+		os.Exit(runMockServerCmd(flag.Args()[1:], os.Stdout))
+	case "spoilers":
+		os.Exit(runSpoilersCmd(cfg, flag.Args()[1:], os.Stdout))
+	default:
+		fmt.Fprintf(os.Stderr, "raceday: unknown command %q\n", flag.Arg(0))
+		os.Exit(2)
+	}
+
+	cache.New("").AutoPrune(cachePolicy(cfg), autoPruneInterval)
 
 	// Merge all driver numbers from config into flat list
 	var drivers []int
@@ -48,13 +98,17 @@ func main() {
 		drivers = []int{*driver}
 	}
 
-	if *status {
+	if *status || *output != "" {
 		w := *width
 		if w == 0 {
 			w = cfg.StatusWidth
 		}
 		m := *marquee || cfg.Marquee
-		runStatus(cfg, drivers, w, m)
+		format := *output
+		if format == "" {
+			format = outputTmux
+		}
+		runStatus(cfg, drivers, w, m, format)
 		return
 	}
 
@@ -63,7 +117,8 @@ func main() {
 	if len(drivers) > 0 {
 		primary = drivers[0]
 	}
-	m := ui.NewModel(primary)
+	d := delay.Setting(delay.SettingPath(), time.Duration(cfg.BroadcastDelay))
+	m := ui.NewModel(primary, spoiler.New(cfg.SpoilerFree, spoiler.Path()), d, time.Duration(cfg.ScheduleHorizon))
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "raceday: %v\n", err)
@@ -71,6 +126,44 @@ func main() {
 	}
 }
 
+// setupLogging directs slog to path (or the default log path with
+// --debug). Without either, records are discarded. A leading ~/ in path
+// is expanded.
+func setupLogging(path string, debug bool) func() error {
+	level := slog.LevelInfo
+	if debug {
+		level = slog.LevelDebug
+		if path == "" {
+			path = logging.DefaultPath()
+		}
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+	closeLog, err := logging.Setup(path, level)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "raceday: %v\n", err)
+		return func() error { return nil }
+	}
+	return closeLog
+}
+
+// httpOptions converts the http config section into fetch options.
+func httpOptions(h config.HTTP) fetch.Options {
+	opts := fetch.DefaultOptions()
+	opts.Timeout = time.Duration(h.Timeout)
+	opts.Retries = h.Retries
+	opts.BreakerThreshold = h.BreakerThreshold
+	opts.BreakerCooldown = time.Duration(h.BreakerCooldown)
+	opts.RateLimits = make(map[string]time.Duration, len(h.RateLimits))
+	for host, iv := range h.RateLimits {
+		opts.RateLimits[host] = time.Duration(iv)
+	}
+	return opts
+}
+
 // segment is a piece of the status bar with a priority.
 // Lower priority number = higher importance (dropped last).
 // Static segments are always visible; non-static ones rotate in the marquee.
@@ -80,66 +173,517 @@ type segment struct {
 	static   bool // true = pinned visible, false = part of marquee rotation
 }
 
-func runStatus(cfg config.Config, drivers []int, width int, marquee bool) {
-	var allSeries []series.Series
-	for _, name := range cfg.Series {
-		switch name {
-		case "nascar":
-			allSeries = append(allSeries, nascar.NewSeries())
-		case "f1":
-			allSeries = append(allSeries, f1.NewSeries())
+// offSeasonGap is how far away the next race has to be before the status
+// bar counts down to the new season instead of showing the race time.
+const offSeasonGap = 30 * 24 * time.Hour
+
+// statusGrace is how long past the status deadline we wait for series
+// that are still falling back to stale cache data.
+const statusGrace = 250 * time.Millisecond
+
+// statusResult is what one series contributes to the status line: its live
+// session if one is running, otherwise its next race, plus weather for
+// whichever was found.
+type statusResult struct {
+	name    string // series short name
+	live    *series.LiveState
+	next    *series.Race
+	last    *series.Result // latest result, if it ended within last_result
+	weather string
+	spoiler bool      // live is redacted by spoiler-free mode
+	stale   bool      // live/next came from stale cache
+	staleAt time.Time // modification time of the oldest stale entry used
+	err     error     // the provider failed and nothing was cached
+
+	weatherStaleAt time.Time // modification time of stale weather; zero if fresh
+}
+
+// errNoAnswer marks a series that hadn't answered by the status deadline.
+var errNoAnswer = errors.New("no answer before the status deadline")
+
+func runStatus(cfg config.Config, drivers []int, width int, marquee bool, format string) {
+	allSeries := newSeries(cfg.Series)
+	multiSeries := len(allSeries) > 1
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if cfg.StatusTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.StatusTimeout))
+	}
+	defer cancel()
+	now := time.Now()
+	layout, err := compileStatusFormat(cfg.StatusFormat)
+	if err != nil {
+		slog.Warn("status_format ignored", "err", err)
+	}
+	results := gatherStatus(ctx, cfg, allSeries, now)
+	delayLive(results, delay.Setting(delay.SettingPath(), time.Duration(cfg.BroadcastDelay)), now)
+	spoilers := spoiler.New(cfg.SpoilerFree, spoiler.Path())
+	// Events are found before spoilers are redacted, so the last state
+	// saved for a hidden race is complete.
+	evs := detectEvents(results, drivers, cfg.Notify.Rules, spoilers, now)
+	hideSpoilers(results, spoilers, now)
+	view := statusView{
+		layout:      layout,
+		theme:       outputTheme(format, cfg.StatusStyle),
+		drivers:     drivers,
+		multiSeries: multiSeries,
+		gaps:        cfg.StatusGaps,
+	}
+	segments := statusSegments(results, view, now)
+
+	var s string
+	if marquee && width > 0 {
+		s = assembleHybrid(segments, width, cfg.MarqueeSpeed, cfg.MarqueeSeparator)
+	} else {
+		s = assembleSegments(segments, width)
+		if width > 0 {
+			s = ui.PadToWidth(s, width)
 		}
 	}
-	multiSeries := len(allSeries) > 1
+	slog.Debug("status", "text", s, "output", format)
+	line := statusLine{text: s, segments: segments, results: results, chosen: chooseResult(results)}
+	if err := writeStatus(os.Stdout, format, line, cfg.StatusStyle); err != nil {
+		fmt.Fprintf(os.Stderr, "raceday: %v\n", err)
+	}
+	// Alerts go out once the line is written, so slow sinks don't hold it up.
+	sendEvents(cfg, append(evs, dueReminders(cfg, allSeries, now)...))
+}
+
+// statusView is how the status line is laid out and styled.
+type statusView struct {
+	layout      *statusLayout // status_format templates; nil for the built-in layout
+	theme       *tmuxTheme    // nil for plain text
+	drivers     []int
+	multiSeries bool
+	gaps        bool // add each favourite's gaps to the cars around it
+}
 
+// statusSegments builds the status line from each series' result. The
+// first series (in config order) with a live session wins; otherwise the
+// soonest upcoming race is shown, laid out by the view's templates if it
+// has some for the state. A low-priority indicator flags stale data and
+// failed providers, so a fetch failure doesn't read as the off-season.
+func statusSegments(results []statusResult, v statusView, now time.Time) []segment {
+	chosen := chooseResult(results)
+	last := latestResult(results)
 	var segments []segment
+	templated := false
+	if chosen != nil {
+		logChosen(chosen)
+		if !chosen.spoiler {
+			segments, templated = v.layout.render(chosen, last, v.drivers, v.multiSeries, now)
+		}
+	}
+	switch {
+	case templated:
+	case chosen == nil:
+		slog.Debug("status chosen", "series", nil)
+		text := "No upcoming races"
+		if allFailed(results) {
+			text = "Race data unavailable"
+		}
+		segments = []segment{{text, 0, true}}
+	case chosen.spoiler:
+		segments = spoilerSegments(chosen.live, v.multiSeries)
+	case chosen.live != nil:
+		segments = liveSegmentsFromState(chosen.live, v.drivers, v.multiSeries, v.gaps)
+	default:
+		primary := 0
+		if len(v.drivers) > 0 {
+			primary = v.drivers[0]
+		}
+		segments = scheduleSegmentsFromRace(chosen.next, primary, v.multiSeries)
+	}
+	if chosen != nil {
+		v.theme.style(segments, chosen, !templated)
+	}
+	// Between races, follow the next race with the last one's result.
+	// Templates place it themselves.
+	if last != nil && (chosen == nil || chosen.live == nil) && !templated {
+		segments = append(segments, lastResultSegments(last, v.drivers, v.multiSeries)...)
+	}
+	// Templates place the weather themselves.
+	if chosen != nil && chosen.weather != "" && !templated {
+		segments = append(segments, segment{chosen.weather, 3, false})
+	}
+	if ind := statusIndicator(chosen, results, now); ind != "" {
+		segments = append(segments, segment{v.theme.stale(ind), 3, true})
+	}
+	return segments
+}
 
-	// Check each series for a live session.
-	var liveState *series.LiveState
-	for _, s := range allSeries {
-		st, err := s.FetchLiveState()
-		if err == nil && st != nil {
-			liveState = st
-			break
+// chooseResult picks the result the status line shows: the first series
+// (in config order) with a live session, otherwise the soonest upcoming
+// race. It returns nil if there is neither.
+func chooseResult(results []statusResult) *statusResult {
+	for i := range results {
+		if results[i].live != nil {
+			return &results[i]
 		}
 	}
+	var chosen *statusResult
+	for i := range results {
+		r := &results[i]
+		if r.next != nil && (chosen == nil || r.next.StartTime.Before(chosen.next.StartTime)) {
+			chosen = r
+		}
+	}
+	return chosen
+}
 
-	if liveState != nil {
-		segments = liveSegmentsFromState(liveState, drivers, multiSeries)
-		if cfg.Weather && shouldShowWeather(time.Time{}, true, time.Duration(cfg.WeatherWindow)) {
-			if ws := weatherSuffixFromCoords(liveState.Lat, liveState.Lon); ws != "" {
-				segments = append(segments, segment{ws, 3, false})
-			}
+// latestResult returns the most recent race result among results, or nil
+// if none has one.
+func latestResult(results []statusResult) *series.Result {
+	var last *series.Result
+	for _, r := range results {
+		if r.last != nil && (last == nil || r.last.EndTime.After(last.EndTime)) {
+			last = r.last
 		}
-	} else {
-		race := series.NextRaceAcrossAll(allSeries, time.Now())
-		if race == nil {
-			fmt.Print("No upcoming races")
-			return
+	}
+	return last
+}
+
+// allFailed reports whether every series failed.
+func allFailed(results []statusResult) bool {
+	for _, r := range results {
+		if r.err == nil {
+			return false
 		}
+	}
+	return len(results) > 0
+}
 
-		primary := 0
-		if len(drivers) > 0 {
-			primary = drivers[0]
+// statusIndicator describes what's wrong with the data behind the status
+// line, e.g. " ⚠ 3m old" or " ⚠ 3m old, F1 failed". It is empty when
+// everything is fresh. It is the only mark stale data gets.
+func statusIndicator(chosen *statusResult, results []statusResult, now time.Time) string {
+	var notes []string
+	if chosen != nil && chosen.stale {
+		if chosen.staleAt.IsZero() {
+			notes = append(notes, "cached")
+		} else {
+			notes = append(notes, formatAge(now.Sub(chosen.staleAt))+" old")
 		}
-		segments = scheduleSegmentsFromRace(race, primary, multiSeries)
-		if cfg.Weather && shouldShowWeather(race.StartTime, false, time.Duration(cfg.WeatherWindow)) {
-			if ws := weatherSuffixFromCoords(race.Lat, race.Lon); ws != "" {
-				segments = append(segments, segment{ws, 3, false})
-			}
+	}
+	if chosen != nil && chosen.weather != "" && !chosen.weatherStaleAt.IsZero() {
+		notes = append(notes, "weather "+formatAge(now.Sub(chosen.weatherStaleAt))+" old")
+	}
+	for _, r := range results {
+		if r.err != nil {
+			notes = append(notes, r.name+" failed")
 		}
 	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " ⚠ " + strings.Join(notes, ", ")
+}
 
-	var s string
-	if marquee && width > 0 {
-		s = assembleHybrid(segments, width, cfg.MarqueeSpeed, cfg.MarqueeSeparator)
+// logChosen records which series won the status line and why.
+func logChosen(r *statusResult) {
+	if r.live != nil {
+		slog.Debug("status chosen", "series", r.live.SeriesName, "live", true, "race", r.live.RaceName, "stale", r.stale)
+		return
+	}
+	slog.Debug("status chosen", "series", r.next.SeriesName, "live", false, "race", r.next.RaceName,
+		"start", r.next.StartTime, "stale", r.stale)
+}
+
+// gatherStatus queries every series concurrently. Results are returned in
+// series order; a series that has not answered by the ctx deadline (plus
+// statusGrace) is left as a zero statusResult.
+func gatherStatus(ctx context.Context, cfg config.Config, allSeries []series.Series, now time.Time) []statusResult {
+	type indexed struct {
+		i int
+		r statusResult
+	}
+	ch := make(chan indexed, len(allSeries))
+	for i, s := range allSeries {
+		go func() {
+			ch <- indexed{i, seriesStatus(ctx, cfg, s, now)}
+		}()
+	}
+
+	results := make([]statusResult, len(allSeries))
+	for i, s := range allSeries {
+		results[i] = statusResult{name: s.ShortName(), err: errNoAnswer}
+	}
+	var expired <-chan time.Time
+	if deadline, ok := ctx.Deadline(); ok {
+		t := time.NewTimer(time.Until(deadline) + statusGrace)
+		defer t.Stop()
+		expired = t.C
+	}
+	answered := 0
+	for range allSeries {
+		select {
+		case r := <-ch:
+			results[r.i] = r.r
+			answered++
+		case <-expired:
+			slog.Warn("status deadline passed", "waiting_on", len(allSeries)-answered)
+			return results
+		}
+	}
+	return results
+}
+
+// seriesStatus fetches one series' live state or next race, then the
+// weather for its track.
+func seriesStatus(ctx context.Context, cfg config.Config, s series.Series, now time.Time) statusResult {
+	r := statusResult{name: s.ShortName()}
+	window := time.Duration(cfg.WeatherWindow)
+	sctx, tracker := cache.Track(ctx)
+
+	start := time.Now()
+	var lat, lon float64
+	showWeather := false
+	st, err := s.FetchLiveState(sctx)
+	if err != nil {
+		slog.Warn("live state failed", "series", s.Name(), "err", err)
+		r.err = err
+	}
+	if err == nil && st != nil {
+		r.live = st
+		lat, lon = st.Lat, st.Lon
+		showWeather = shouldShowWeather(time.Time{}, true, window)
+	} else if race, err := series.NextRace(sctx, s, now, time.Duration(cfg.ScheduleHorizon)); err != nil {
+		slog.Warn("schedule failed", "series", s.Name(), "err", err)
+		r.err = err
+	} else if race != nil {
+		r.next = race
+		lat, lon = race.Lat, race.Lon
+		showWeather = shouldShowWeather(race.StartTime, false, window)
+	}
+	if r.live == nil && cfg.LastResult > 0 {
+		r.last = lastResult(sctx, s, now, time.Duration(cfg.LastResult))
+	}
+	r.stale, r.staleAt = tracker.Stale()
+	slog.Debug("series status", "series", s.Name(), "duration", time.Since(start),
+		"live", r.live != nil, "next", r.next != nil, "last", r.last != nil, "stale", r.stale)
+
+	if cfg.Weather && showWeather {
+		wctx, wtracker := cache.Track(ctx)
+		r.weather = weatherSuffixFromCoords(wctx, lat, lon)
+		if stale, at := wtracker.Stale(); stale && r.weather != "" {
+			r.weatherStaleAt = at
+		}
+	}
+	return r
+}
+
+// delayBufferPath is where a series' live states are buffered. Tests
+// replace it.
+var delayBufferPath = delay.BufferPath
+
+// delayLockWait bounds how long a status run waits for another to finish
+// with a delay buffer before using it unlocked.
+const delayLockWait = 2 * time.Second
+
+// delayLive replaces each live state with the one fetched d before now,
+// to match a delayed broadcast. Each status run adds its state to the
+// series' buffer, so the line runs d behind once the buffer reaches back
+// that far; until then it shows the oldest state buffered. When the
+// session ends the buffer keeps being replayed until the broadcast has
+// caught up with the end, and is then removed.
+func delayLive(results []statusResult, d time.Duration, now time.Time) {
+	if d <= 0 {
+		return
+	}
+	for i := range results {
+		if r := &results[i]; r.err == nil {
+			r.live = delayed(r.name, r.live, d, now)
+		}
+	}
+}
+
+// delayed adds live, nil once the session is over, to name's buffer and
+// returns the state d before now.
+func delayed(name string, live *series.LiveState, d time.Duration, now time.Time) *series.LiveState {
+	path := delayBufferPath(name)
+	if live == nil {
+		if _, err := os.Stat(path); err != nil {
+			return nil // no session buffered
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), delayLockWait)
+	defer cancel()
+	if unlock, err := cache.Lock(ctx, path); err == nil {
+		defer unlock()
 	} else {
-		s = assembleSegments(segments, width)
-		if width > 0 {
-			s = ui.PadToWidth(s, width)
+		slog.Warn("delay buffer busy", "series", name, "err", err)
+	}
+
+	buf, err := delay.Load[*series.LiveState](path)
+	if err != nil {
+		slog.Warn("delay buffer reset", "series", name, "err", err)
+	}
+	buf.Push(now, live)
+	live, _ = buf.At(now.Add(-d))
+	buf.Trim(now.Add(-d))
+	if live == nil && !slices.ContainsFunc(buf.Snapshots, func(s delay.Snapshot[*series.LiveState]) bool { return s.Value != nil }) {
+		// The broadcast has shown the end too.
+		if err := os.Remove(path); err != nil {
+			slog.Warn("delay buffer not removed", "series", name, "err", err)
+		}
+		return nil
+	}
+	if err := buf.Save(path); err != nil {
+		slog.Warn("delay buffer not saved", "series", name, "err", err)
+	}
+	if live != nil {
+		slog.Debug("delayed", "series", name, "delay", d, "lap", live.CurrentLap)
+	}
+	return live
+}
+
+// hideSpoilers redacts what spoiler-free mode hides: a hidden race's
+// live state keeps only its names and whether it has finished, and a
+// hidden last result is dropped.
+func hideSpoilers(results []statusResult, f *spoiler.Filter, now time.Time) {
+	for i := range results {
+		r := &results[i]
+		if st := r.live; st != nil && f.Hides(now.Year(), st.RaceName, st.TrackName) {
+			slog.Debug("spoiler-free", "series", st.SeriesName, "race", st.RaceName)
+			r.live = &series.LiveState{
+				SeriesName: st.SeriesName,
+				ShortName:  st.ShortName,
+				RaceName:   st.RaceName,
+				TrackName:  st.TrackName,
+				Finished:   st.Finished,
+				Lat:        st.Lat,
+				Lon:        st.Lon,
+			}
+			r.spoiler = true
+		}
+		if res := r.last; res != nil && f.Hides(res.StartTime.Year(), res.RaceName, res.TrackName) {
+			r.last = nil
+		}
+	}
+}
+
+// spoilerSegments shows a spoiler-free race as just running or over.
+func spoilerSegments(state *series.LiveState, multiSeries bool) []segment {
+	prefix := ""
+	if multiSeries {
+		prefix = state.ShortName + ": "
+	}
+	if state.Finished {
+		return []segment{{fmt.Sprintf("%s🙈 %s (spoiler-free)", prefix, state.RaceName), 0, true}}
+	}
+	return []segment{{fmt.Sprintf("%s🙈 %s in progress", prefix, state.RaceName), 0, true}}
+}
+
+// lastResult returns s's latest race result if the race ended less than
+// window before now. Failures only cost the segment, so they are logged
+// rather than reported.
+func lastResult(ctx context.Context, s series.Series, now time.Time, window time.Duration) *series.Result {
+	rs, ok := s.(series.ResultSeries)
+	if !ok {
+		return nil
+	}
+	res, err := rs.LastResult(ctx, now)
+	if err != nil {
+		slog.Warn("last result failed", "series", s.Name(), "err", err)
+		return nil
+	}
+	if res == nil || now.Sub(res.EndTime) >= window {
+		return nil
+	}
+	return res
+}
+
+// offSeasonSegments counts down to a season opener that is more than
+// offSeasonGap away, e.g. "🏁 NASCAR season starts in 97 days | Daytona 500".
+func offSeasonSegments(race *series.Race, now time.Time) []segment {
+	name := race.ShortName
+	if name == "" {
+		name = race.SeriesName
+	}
+	days := daysUntil(now, race.StartTime)
+	unit := "days"
+	if days == 1 {
+		unit = "day"
+	}
+	return []segment{
+		{fmt.Sprintf("🏁 %s season starts in %d %s", name, days, unit), 0, true},
+		{fmt.Sprintf(" | %s", race.RaceName), 2, false},
+		{fmt.Sprintf(" | %s", race.StartTime.Local().Format("Jan 2")), 3, false},
+	}
+}
+
+// lastResultSegments show the latest race's winner and each favourite's
+// finish, e.g. " | Last: #24 Byron won DAYTONA 500 | #8 Busch P2 +41 pts".
+func lastResultSegments(res *series.Result, drivers []int, multiSeries bool) []segment {
+	label := "Last"
+	if multiSeries {
+		label += " " + res.ShortName
+	}
+	segs := []segment{{
+		fmt.Sprintf(" | %s: #%s %s won %s", label, res.Winner.Number, res.Winner.Name, res.RaceName), 2, false,
+	}}
+	for _, d := range favoritePositions(res.Positions, drivers) {
+		pos := "NC"
+		if d.Position > 0 {
+			pos = fmt.Sprintf("P%d", d.Position)
+		}
+		text := fmt.Sprintf(" | #%s %s %s", d.Number, d.Name, pos)
+		if pts := formatPoints(d.Points); pts != "" {
+			text += " " + pts
 		}
+		segs = append(segs, segment{text, 2, false})
+	}
+	return segs
+}
+
+// formatPoints formats points earned, e.g. "+41 pts". It is empty for none.
+func formatPoints(points float64) string {
+	switch {
+	case points == 1:
+		return "+1 pt"
+	case points > 0:
+		return fmt.Sprintf("+%g pts", points)
+	}
+	return ""
+}
+
+// formatDelta formats positions gained or lost since the start, e.g.
+// "[+2]" or "[-3]". It is empty for no change.
+func formatDelta(delta float64) string {
+	switch {
+	case delta > 0:
+		return fmt.Sprintf("[+%d]", int(delta))
+	case delta < 0:
+		return fmt.Sprintf("[%d]", int(delta))
+	}
+	return ""
+}
+
+// formatRaceTime formats a start time relative to now: "Today 3:00 PM",
+// "Tomorrow 3:00 PM" or "Feb 16 2:30 PM".
+func formatRaceTime(t, now time.Time) string {
+	local := t.Local()
+	switch {
+	case sameDay(local, now):
+		return "Today " + local.Format("3:04 PM")
+	case sameDay(local, now.AddDate(0, 0, 1)):
+		return "Tomorrow " + local.Format("3:04 PM")
 	}
-	fmt.Print(s)
+	return local.Format("Jan 2 3:04 PM")
+}
+
+// sameDay reports whether a and b fall on the same local calendar date.
+func sameDay(a, b time.Time) bool {
+	a, b = a.Local(), b.Local()
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// daysUntil counts local calendar days from now to t.
+func daysUntil(now, t time.Time) int {
+	n, t := now.Local(), t.Local()
+	from := time.Date(n.Year(), n.Month(), n.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
 }
 
 // assembleSegments joins segments, dropping lowest-priority ones first
@@ -162,7 +706,7 @@ func assembleSegments(segs []segment, width int) string {
 			}
 		}
 		result := strings.Join(parts, "")
-		if runewidth.StringWidth(result) <= width {
+		if ui.DisplayWidth(result) <= width {
 			return result
 		}
 		// If we still exceed width even at this priority level,
@@ -189,7 +733,7 @@ func assembleHybrid(segs []segment, width, speed int, sep string) string {
 
 	// Build static text, dropping lowest-priority statics if over budget
 	staticText := joinSegments(statics)
-	for maxPri := 3; runewidth.StringWidth(staticText) > width && maxPri >= 0; maxPri-- {
+	for maxPri := 3; ui.DisplayWidth(staticText) > width && maxPri >= 0; maxPri-- {
 		var kept []segment
 		for _, s := range statics {
 			if s.priority <= maxPri {
@@ -201,8 +745,8 @@ func assembleHybrid(segs []segment, width, speed int, sep string) string {
 	}
 
 	spacer := " | "
-	spacerWidth := runewidth.StringWidth(spacer)
-	remaining := width - runewidth.StringWidth(staticText) - spacerWidth
+	spacerWidth := ui.DisplayWidth(spacer)
+	remaining := width - ui.DisplayWidth(staticText) - spacerWidth
 	if remaining <= 0 || len(dynamics) == 0 {
 		return ui.PadToWidth(staticText, width)
 	}
@@ -232,18 +776,19 @@ func shouldShowWeather(startTime time.Time, isLive bool, window time.Duration) b
 	return time.Until(startTime) <= window
 }
 
-func weatherSuffixFromCoords(lat, lon float64) string {
+func weatherSuffixFromCoords(ctx context.Context, lat, lon float64) string {
 	if lat == 0 && lon == 0 {
 		return ""
 	}
-	c, err := weather.FetchCurrent(lat, lon)
+	c, err := weather.FetchCurrent(ctx, lat, lon)
 	if err != nil {
+		slog.Warn("weather failed", "lat", lat, "lon", lon, "err", err)
 		return ""
 	}
 	return fmt.Sprintf(" | %.0f°F %s %.0fmph %s", c.Temp, weather.Symbol(c.WeatherCode), c.WindSpeed, weather.WindDirectionArrow(c.WindDirection))
 }
 
-func liveSegmentsFromState(state *series.LiveState, drivers []int, multiSeries bool) []segment {
+func liveSegmentsFromState(state *series.LiveState, drivers []int, multiSeries, gaps bool) []segment {
 	prefix := ""
 	if multiSeries {
 		prefix = state.ShortName + ": "
@@ -279,14 +824,15 @@ func liveSegmentsFromState(state *series.LiveState, drivers []int, multiSeries b
 		for _, p := range state.Positions {
 			if p.Number == carNum {
 				diffStr := ""
-				if p.Delta > 0 {
-					diffStr = fmt.Sprintf(" [+%d]", int(p.Delta))
-				} else if p.Delta < 0 {
-					diffStr = fmt.Sprintf(" [%d]", int(p.Delta))
+				if p.Delta != 0 {
+					diffStr = " " + formatDelta(p.Delta)
 				}
 				segs = append(segs, segment{
 					fmt.Sprintf(" | #%s %s P%d%s", p.Number, p.Name, p.Position, diffStr), 1, i == 0,
 				})
+				if g := gapText(p); gaps && g != "" && !state.Finished {
+					segs = append(segs, segment{" | " + g, 2, false})
+				}
 				break
 			}
 		}
@@ -295,6 +841,45 @@ func liveSegmentsFromState(state *series.LiveState, drivers []int, multiSeries b
 	return segs
 }
 
+// gapText describes where d is running: laps down, the gaps to the cars
+// ahead (▲) and behind (▼), and for lapped cars their place in line for
+// the lucky dog, e.g. "▲+0.412 ▼+0.675" or "-1 LAP ▲+1 LAP lucky dog".
+func gapText(d series.Driver) string {
+	var parts []string
+	if d.LapsDown > 0 {
+		parts = append(parts, fmt.Sprintf("-%d %s", d.LapsDown, plural(d.LapsDown, "LAP", "LAPS")))
+	}
+	if d.GapAhead != "" {
+		parts = append(parts, "▲"+d.GapAhead)
+	}
+	if d.GapBehind != "" {
+		parts = append(parts, "▼"+d.GapBehind)
+	}
+	switch {
+	case d.LapsDown == 0:
+	case d.LuckyDog == 1:
+		parts = append(parts, "lucky dog")
+	case d.LuckyDog > 1:
+		parts = append(parts, fmt.Sprintf("lucky dog %s", ordinal(d.LuckyDog)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ordinal formats n as "1st", "2nd", "3rd", "4th", ...
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
 func scheduleSegmentsFromRace(race *series.Race, driverNum int, multiSeries bool) []segment {
 	prefix := ""
 	if multiSeries {
@@ -308,16 +893,12 @@ func scheduleSegmentsFromRace(race *series.Race, driverNum int, multiSeries bool
 	local := race.StartTime.Local()
 	now := time.Now()
 
-	var timeStr string
-	switch {
-	case local.Year() == now.Year() && local.YearDay() == now.YearDay():
-		timeStr = "Today " + local.Format("3:04 PM")
-	case local.Year() == now.Year() && local.YearDay() == now.YearDay()+1:
-		timeStr = "Tomorrow " + local.Format("3:04 PM")
-	default:
-		timeStr = local.Format("Jan 2 3:04 PM")
+	if race.StartTime.Sub(now) > offSeasonGap {
+		return offSeasonSegments(race, now)
 	}
 
+	timeStr := formatRaceTime(local, now)
+
 	segs := []segment{
 		{fmt.Sprintf("🏁 %s%s", prefix, race.RaceName), 0, true},
 		{fmt.Sprintf(" | %s", timeStr), 0, false},
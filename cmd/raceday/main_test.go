@@ -1,9 +1,30 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/delay"
+	"github.com/jfmyers/tmux-raceday/internal/events"
+	"github.com/jfmyers/tmux-raceday/internal/f1"
+	"github.com/jfmyers/tmux-raceday/internal/mockserver"
+	"github.com/jfmyers/tmux-raceday/internal/mockserver/mockservertest"
+	"github.com/jfmyers/tmux-raceday/internal/nascar"
+	"github.com/jfmyers/tmux-raceday/internal/notify"
+	"github.com/jfmyers/tmux-raceday/internal/reminder"
+	"github.com/jfmyers/tmux-raceday/internal/series"
+	"github.com/jfmyers/tmux-raceday/internal/spoiler"
+	"github.com/jfmyers/tmux-raceday/internal/ui"
 	"github.com/mattn/go-runewidth"
 )
 
@@ -152,3 +173,922 @@ func TestShouldShowWeather(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{100 << 20, "100.0 MB"},
+		{3 << 30, "3.0 GB"},
+	}
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Second, "30s"},
+		{5 * time.Minute, "5m"},
+		{3 * time.Hour, "3h"},
+		{30 * 24 * time.Hour, "30d"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.d); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+// fakeSeries answers after delay, or when ctx is done if honourCtx is set,
+// mimicking a provider that falls back to stale cache on cancellation.
+type fakeSeries struct {
+	name      string
+	delay     time.Duration
+	honourCtx bool
+	live      *series.LiveState
+	races     []series.Race
+}
+
+func (f *fakeSeries) Name() string      { return f.name }
+func (f *fakeSeries) ShortName() string { return f.name }
+
+func (f *fakeSeries) wait(ctx context.Context) {
+	if !f.honourCtx {
+		time.Sleep(f.delay)
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(f.delay):
+	}
+}
+
+func (f *fakeSeries) FetchSchedule(ctx context.Context, year int) ([]series.Race, error) {
+	f.wait(ctx)
+	return f.races, nil
+}
+
+func (f *fakeSeries) FetchLiveState(ctx context.Context) (*series.LiveState, error) {
+	f.wait(ctx)
+	return f.live, nil
+}
+
+func TestGatherStatus(t *testing.T) {
+	now := time.Now()
+	cfg := config.DefaultConfig()
+	cfg.Weather = false
+
+	fast := &fakeSeries{name: "NASCAR", races: []series.Race{{RaceName: "Daytona 500", StartTime: now.Add(time.Hour)}}}
+	stale := &fakeSeries{name: "F1", delay: time.Minute, honourCtx: true,
+		live: &series.LiveState{RaceName: "Bahrain"}}
+	hung := &fakeSeries{name: "HUNG", delay: 5 * time.Second,
+		live: &series.LiveState{RaceName: "never"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	results := gatherStatus(ctx, cfg, []series.Series{fast, stale, hung}, now)
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond+statusGrace+100*time.Millisecond {
+		t.Errorf("gatherStatus took %v, want close to the deadline", elapsed)
+	}
+
+	if results[0].next == nil || results[0].next.RaceName != "Daytona 500" {
+		t.Errorf("fast series: got %+v", results[0])
+	}
+	if results[1].live == nil || results[1].live.RaceName != "Bahrain" {
+		t.Errorf("ctx-aware series should answer from fallback: got %+v", results[1])
+	}
+	if results[2].live != nil || results[2].next != nil {
+		t.Errorf("hung series should be dropped: got %+v", results[2])
+	}
+	if results[2].name != "HUNG" || !errors.Is(results[2].err, errNoAnswer) {
+		t.Errorf("hung series should be reported as failed: got %+v", results[2])
+	}
+}
+
+// resultSeries is a fakeSeries that also reports a last result.
+type resultSeries struct {
+	fakeSeries
+	result *series.Result
+}
+
+func (r *resultSeries) LastResult(ctx context.Context, now time.Time) (*series.Result, error) {
+	return r.result, nil
+}
+
+func TestLastResult(t *testing.T) {
+	now := time.Date(2026, 2, 17, 12, 0, 0, 0, time.Local)
+	cfg := config.DefaultConfig()
+	cfg.Weather = false
+	res := &series.Result{
+		ShortName: "NASCAR", RaceName: "DAYTONA 500", EndTime: now.Add(-36 * time.Hour),
+		Winner: series.Driver{Number: "24", Name: "Byron", Position: 1, Points: 55},
+		Positions: []series.Driver{
+			{Number: "24", Name: "Byron", Position: 1, Points: 55},
+			{Number: "8", Name: "Busch", Position: 2, Points: 41},
+			{Number: "48", Name: "Bowman", Position: 0},
+		},
+	}
+	s := &resultSeries{fakeSeries{name: "NASCAR", races: []series.Race{
+		{ShortName: "NASCAR", RaceName: "Ambetter Health 400", StartTime: now.Add(5 * 24 * time.Hour)},
+	}}, res}
+
+	r := seriesStatus(context.Background(), cfg, s, now)
+	if r.last != res {
+		t.Fatalf("last = %+v, want the result", r.last)
+	}
+	got := joinSegments(statusSegments([]statusResult{r}, statusView{drivers: []int{8, 48}}, now))
+	want := " | #8 | Last: #24 Byron won DAYTONA 500 | #8 Busch P2 +41 pts | #48 Bowman NC"
+	if !strings.HasPrefix(got, "🏁 Ambetter Health 400") || !strings.HasSuffix(got, want) {
+		t.Errorf("status = %q\nwant it to end %q", got, want)
+	}
+
+	// Outside the window, or with last_result off, there is no result.
+	cfg.LastResult = config.Duration(24 * time.Hour)
+	if r := seriesStatus(context.Background(), cfg, s, now); r.last != nil {
+		t.Errorf("result past the window: %+v", r.last)
+	}
+	cfg.LastResult = 0
+	if r := seriesStatus(context.Background(), cfg, s, now); r.last != nil {
+		t.Errorf("result with last_result off: %+v", r.last)
+	}
+
+	// The result still shows once the schedule has run out.
+	got = joinSegments(statusSegments([]statusResult{{name: "NASCAR", last: res}}, statusView{}, now))
+	if got != "No upcoming races | Last: #24 Byron won DAYTONA 500" {
+		t.Errorf("without a next race = %q", got)
+	}
+
+	layout, err := compileStatusFormat(config.StatusFormat{Scheduled: []config.StatusBlock{
+		{Text: "{{.Race.RaceName}}{{with .Last}} (last: {{.Winner.Name}}){{end}}" +
+			"{{range .LastFavorites}} #{{.Number}} P{{.Position}} {{points .Points}}{{end}}"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = joinSegments(statusSegments([]statusResult{r}, statusView{layout: layout, drivers: []int{8}}, now))
+	if got != "Ambetter Health 400 (last: Byron) #8 P2 +41 pts" {
+		t.Errorf("template = %q", got)
+	}
+}
+
+func TestStatusSegmentsIndicator(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	race := &series.Race{SeriesName: "NASCAR Cup", ShortName: "NASCAR", RaceName: "Dover 400", StartTime: now.Add(72 * time.Hour)}
+	down := errors.New("503")
+
+	tests := []struct {
+		name    string
+		results []statusResult
+		want    string
+	}{
+		{"fresh", []statusResult{{name: "NASCAR", next: race}}, ""},
+		{"stale", []statusResult{{name: "NASCAR", next: race, stale: true, staleAt: now.Add(-3 * time.Minute)}}, " ⚠ 3m old"},
+		{"stale weather", []statusResult{{name: "NASCAR", next: race, weather: " | 72°F ☀️", weatherStaleAt: now.Add(-12 * time.Minute)}}, " ⚠ weather 12m old"},
+		{"other series failed", []statusResult{{name: "NASCAR", next: race}, {name: "F1", err: down}}, " ⚠ F1 failed"},
+		{"all failed", []statusResult{{name: "NASCAR", err: down}}, "Race data unavailable ⚠ NASCAR failed"},
+		{"off-season", []statusResult{{name: "NASCAR"}}, "No upcoming races"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segs := statusSegments(tt.results, statusView{}, now)
+			got := joinSegments(segs)
+			if tt.results[0].next == nil {
+				if got != tt.want {
+					t.Errorf("got %q, want %q", got, tt.want)
+				}
+				return
+			}
+			last := segs[len(segs)-1]
+			switch {
+			case tt.want == "" && strings.Contains(got, "⚠"):
+				t.Errorf("unexpected indicator in %q", got)
+			case tt.want != "" && (last.text != tt.want || last.priority != 3):
+				t.Errorf("indicator = %+v, want %q at priority 3", last, tt.want)
+			case strings.Contains(got, "~"):
+				t.Errorf("stale data marked twice in %q", got)
+			}
+		})
+	}
+}
+
+func TestStatusIndicatorDropsUnderWidth(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	race := &series.Race{RaceName: "Dover 400", StartTime: now.Add(72 * time.Hour)}
+	segs := statusSegments([]statusResult{{name: "NASCAR", next: race, stale: true, staleAt: now.Add(-time.Hour)}}, statusView{}, now)
+
+	full := assembleSegments(segs, 0)
+	if !strings.Contains(full, "⚠ 1h old") {
+		t.Fatalf("no indicator in %q", full)
+	}
+	narrow := assembleSegments(segs, runewidth.StringWidth(full)-1)
+	if strings.Contains(narrow, "⚠") || !strings.HasPrefix(narrow, "🏁") {
+		t.Errorf("under width pressure got %q, want the indicator dropped", narrow)
+	}
+}
+
+func TestGatherStatusRunsConcurrently(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Weather = false
+	var all []series.Series
+	for i := 0; i < 4; i++ {
+		all = append(all, &fakeSeries{name: "S", delay: 100 * time.Millisecond,
+			live: &series.LiveState{RaceName: "live"}})
+	}
+
+	start := time.Now()
+	results := gatherStatus(context.Background(), cfg, all, time.Now())
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("4 series took %v, want them fetched in parallel", elapsed)
+	}
+	for i, r := range results {
+		if r.live == nil {
+			t.Errorf("series %d missing", i)
+		}
+	}
+}
+
+func TestDaysUntil(t *testing.T) {
+	now := time.Date(2026, 11, 20, 23, 0, 0, 0, time.Local)
+	tests := []struct {
+		t    time.Time
+		want int
+	}{
+		{time.Date(2026, 11, 21, 1, 0, 0, 0, time.Local), 1},
+		{time.Date(2027, 1, 1, 12, 0, 0, 0, time.Local), 42},
+		{time.Date(2027, 2, 14, 14, 30, 0, 0, time.Local), 86},
+	}
+	for _, tt := range tests {
+		if got := daysUntil(now, tt.t); got != tt.want {
+			t.Errorf("daysUntil(%v) = %d, want %d", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleSegmentsOffSeason(t *testing.T) {
+	race := &series.Race{
+		ShortName: "NASCAR",
+		RaceName:  "Daytona 500",
+		StartTime: time.Now().AddDate(0, 0, 90),
+	}
+	segs := scheduleSegmentsFromRace(race, 24, false)
+	if want := "🏁 NASCAR season starts in 90 days"; segs[0].text != want {
+		t.Errorf("segs[0] = %q, want %q", segs[0].text, want)
+	}
+	if segs[1].text != " | Daytona 500" {
+		t.Errorf("segs[1] = %q, want race name", segs[1].text)
+	}
+
+	race.StartTime = time.Now().Add(3 * 24 * time.Hour)
+	segs = scheduleSegmentsFromRace(race, 24, false)
+	if segs[0].text != "🏁 Daytona 500" {
+		t.Errorf("in-season segs[0] = %q, want race name", segs[0].text)
+	}
+}
+
+func TestRouteFlag(t *testing.T) {
+	f := routeFlag{}
+	for _, s := range []string{"live-feed=503", "*=429"} {
+		if err := f.Set(s); err != nil {
+			t.Fatalf("Set(%q): %v", s, err)
+		}
+	}
+	if got := f.String(); got != "*=429,live-feed=503" {
+		t.Errorf("String() = %q", got)
+	}
+	for _, bad := range []string{"live-feed", "=503", "live-feed="} {
+		if err := f.Set(bad); err == nil {
+			t.Errorf("Set(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestCheckFeeds(t *testing.T) {
+	ts := mockservertest.Start(t, mockserver.Options{Drift: map[string]string{"position": mockserver.DriftRename}})
+	ep := ts.Endpoints()
+	nascar.SetBaseURL(ep.NASCAR)
+	f1.SetBaseURL(ep.OpenF1)
+	t.Cleanup(func() {
+		nascar.SetBaseURL("https://cf.nascar.com")
+		f1.SetBaseURL("https://api.openf1.org/v1")
+	})
+
+	cfg := config.DefaultConfig()
+	cfg.Series = config.SeriesList{"nascar", "f1"}
+	checks := feedChecks(cfg, time.Date(2026, 12, 6, 17, 0, 0, 0, time.UTC))
+	if len(checks) != 11 {
+		t.Fatalf("got %d checks, want 11", len(checks))
+	}
+
+	var out strings.Builder
+	r := &report{out: &out}
+	if checkFeeds(context.Background(), r, checks); r.failed != 1 {
+		t.Errorf("failed = %d, want 1\n%s", r.failed, out.String())
+	}
+	if !strings.Contains(out.String(), "✗ openf1 position") || !strings.Contains(out.String(), "[0].driver_number: missing") {
+		t.Errorf("report does not flag the drifted feed:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "✓ nascar live feed") {
+		t.Errorf("report does not pass the healthy feed:\n%s", out.String())
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := config.DefaultConfig()
+
+	var out strings.Builder
+	r := &report{out: &out}
+	checkConfig(r, path, cfg)
+	if r.failed != 0 || r.warned != 1 || !strings.Contains(out.String(), "--init-config") {
+		t.Errorf("missing file: failed=%d warned=%d\n%s", r.failed, r.warned, out.String())
+	}
+
+	os.WriteFile(path, []byte("series: [nascar, indycar]\nstatus_widht: 40\n"), 0o644)
+	out.Reset()
+	r = &report{out: &out}
+	checkConfig(r, path, cfg)
+	if r.failed != 1 || !strings.Contains(out.String(), "status_widht") {
+		t.Errorf("unknown key: failed=%d\n%s", r.failed, out.String())
+	}
+
+	os.WriteFile(path, []byte("series: [nascar, indycar]\n"), 0o644)
+	out.Reset()
+	r = &report{out: &out}
+	checkConfig(r, path, cfg)
+	if r.failed != 1 || !strings.Contains(out.String(), `unknown series "indycar"`) {
+		t.Errorf("invalid value: failed=%d\n%s", r.failed, out.String())
+	}
+
+	os.WriteFile(path, []byte("notify:\n  rules:\n    - when: driver.postion <= 5\n"), 0o644)
+	out.Reset()
+	r = &report{out: &out}
+	checkConfig(r, path, cfg)
+	if r.failed != 1 || !strings.Contains(out.String(), `notify.rules[0]: at 1: unknown field "driver.postion"`) {
+		t.Errorf("invalid rule: failed=%d\n%s", r.failed, out.String())
+	}
+}
+
+func TestCheckNetwork(t *testing.T) {
+	ts := mockservertest.Start(t, mockserver.Options{Faults: map[string]int{"forecast": 503}})
+	ep := ts.Endpoints()
+	targets := []probeTarget{
+		{"NASCAR", ep.NASCAR + "/live/feeds/live-feed.json", "RACEDAY_NASCAR_URL"},
+		{"Open-Meteo", ep.OpenMeteo + "/forecast", "RACEDAY_OPEN_METEO_URL"},
+	}
+
+	var out strings.Builder
+	r := &report{out: &out}
+	checkNetwork(context.Background(), r, config.DefaultConfig(), targets)
+	if r.failed != 1 || !strings.Contains(out.String(), "✓ NASCAR") || !strings.Contains(out.String(), "RACEDAY_OPEN_METEO_URL") {
+		t.Errorf("failed=%d\n%s", r.failed, out.String())
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Offline = true
+	before := ts.Hits("live-feed")
+	r = &report{out: &out}
+	checkNetwork(context.Background(), r, cfg, targets)
+	if ts.Hits("live-feed") != before || r.failed != 0 {
+		t.Errorf("offline doctor probed the network")
+	}
+}
+
+func TestCheckSchedule(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	s := &fakeSeries{name: "NCS", races: []series.Race{
+		{RaceName: "Done", TrackName: "Daytona", StartTime: now.Add(-48 * time.Hour), Complete: true},
+		{RaceName: "Next", TrackName: "Dover", StartTime: now.Add(72 * time.Hour), Lat: 39.19, Lon: -75.53},
+		{RaceName: "Later", TrackName: "Nowhere Speedway", StartTime: now.Add(240 * time.Hour)},
+		{RaceName: "Away", TrackName: "Atlantis Raceway", StartTime: now.Add(480 * time.Hour)},
+		{RaceName: "Return", TrackName: "Nowhere Speedway", StartTime: now.Add(720 * time.Hour)},
+	}}
+
+	var out strings.Builder
+	r := &report{out: &out}
+	checkSchedule(context.Background(), r, config.DefaultConfig(), []series.Series{s}, now)
+	for _, want := range []string{"5 races, 4 upcoming", "next: Next in 3 days",
+		"no track coordinates for 3 upcoming races: Atlantis Raceway, Nowhere Speedway\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, out.String())
+		}
+	}
+	if r.failed != 0 || r.warned != 1 {
+		t.Errorf("failed=%d warned=%d", r.failed, r.warned)
+	}
+
+	out.Reset()
+	r = &report{out: &out}
+	checkSchedule(context.Background(), r, config.DefaultConfig(), []series.Series{&fakeSeries{name: "F1"}}, now)
+	if r.failed != 1 || !strings.Contains(out.String(), "schedule is empty") {
+		t.Errorf("empty schedule: failed=%d\n%s", r.failed, out.String())
+	}
+}
+
+func TestCheckTools(t *testing.T) {
+	t.Cleanup(func() { lookPath = exec.LookPath })
+	lookPath = func(name string) (string, error) { return "", exec.ErrNotFound }
+
+	var out strings.Builder
+	r := &report{out: &out}
+	checkTools(r, "linux")
+	if r.failed != 1 || r.warned != 1 || !strings.Contains(out.String(), "notify-send not found") {
+		t.Errorf("failed=%d warned=%d\n%s", r.failed, r.warned, out.String())
+	}
+
+	out.Reset()
+	r = &report{out: &out}
+	checkTools(r, "windows")
+	if r.warned != 0 {
+		t.Errorf("windows has no notifier to check:\n%s", out.String())
+	}
+}
+
+func TestStatusFormatTemplates(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.Local)
+	layout, err := compileStatusFormat(config.StatusFormat{
+		Live: []config.StatusBlock{
+			{Text: "{{.Prefix}}{{.Live.FlagSymbol}} L{{.Live.CurrentLap}}", Priority: 0, Static: true},
+			{Text: "{{range .Favorites}} #{{.Number}} P{{.Position}}{{with delta .Delta}} {{.}}{{end}}{{end}}", Priority: 1, Static: true},
+			{Text: "{{if .Weather}} {{.Weather}}{{end}}", Priority: 3},
+		},
+		Scheduled: []config.StatusBlock{
+			{Text: "{{upper .Race.RaceName}} {{when .Race.StartTime}} ({{days .Race.StartTime}}d)", Static: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	live := statusResult{name: "NASCAR", weather: " | 72°F ☀️", live: &series.LiveState{
+		ShortName: "NASCAR", FlagSymbol: "🟢", CurrentLap: 42,
+		Positions: []series.Driver{{Number: "5", Position: 1}, {Number: "24", Position: 6, Delta: 3}},
+	}}
+	segs := statusSegments([]statusResult{live, {name: "F1"}}, statusView{layout: layout, drivers: []int{24, 5}, multiSeries: true}, now)
+	want := []segment{
+		{"NASCAR: 🟢 L42", 0, true},
+		{" #24 P6 [+3] #5 P1", 1, true},
+		{" 72°F ☀️", 3, false},
+	}
+	if len(segs) != len(want) {
+		t.Fatalf("got %+v, want %+v", segs, want)
+	}
+	for i := range want {
+		if segs[i] != want[i] {
+			t.Errorf("segment %d = %+v, want %+v", i, segs[i], want[i])
+		}
+	}
+
+	// A blank block is dropped rather than leaving an empty segment.
+	live.weather = ""
+	if segs := statusSegments([]statusResult{live}, statusView{layout: layout}, now); len(segs) != 1 {
+		t.Errorf("blank blocks kept: %+v", segs)
+	}
+
+	race := &series.Race{RaceName: "Dover 400", StartTime: now.Add(26 * time.Hour)}
+	got := joinSegments(statusSegments([]statusResult{{name: "NASCAR", next: race}}, statusView{layout: layout}, now))
+	if got != "DOVER 400 Tomorrow 2:00 PM (1d)" {
+		t.Errorf("scheduled = %q", got)
+	}
+
+	// States without templates keep the built-in layout.
+	finished := statusResult{name: "NASCAR", live: &series.LiveState{RaceName: "Dover 400", Finished: true}}
+	if got := joinSegments(statusSegments([]statusResult{finished}, statusView{layout: layout}, now)); got != "🏁 FINAL Dover 400" {
+		t.Errorf("finished = %q", got)
+	}
+}
+
+func TestStatusGaps(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	live := statusResult{name: "NASCAR", live: &series.LiveState{
+		RaceName: "Dover 400", CurrentLap: 50, TotalLaps: 400,
+		Positions: []series.Driver{
+			{Number: "5", Name: "Larson", Position: 1, GapBehind: "+0.412"},
+			{Number: "24", Name: "Byron", Position: 2, GapAhead: "+0.412", GapBehind: "+1 LAP"},
+			{Number: "48", Name: "Bowman", Position: 3, GapAhead: "+1 LAP", LapsDown: 1, LuckyDog: 1},
+			{Number: "9", Name: "Elliott", Position: 4, LapsDown: 2, LuckyDog: 2},
+		},
+	}}
+	v := statusView{drivers: []int{24, 48, 9}, gaps: true}
+	got := joinSegments(statusSegments([]statusResult{live}, v, now))
+	want := "Dover 400 | Lap 50/400 | #24 Byron P2 | ▲+0.412 ▼+1 LAP" +
+		" | #48 Bowman P3 | -1 LAP ▲+1 LAP lucky dog | #9 Elliott P4 | -2 LAPS lucky dog 2nd"
+	if got != want {
+		t.Errorf("status = %q\nwant     %q", got, want)
+	}
+
+	// Gaps are opt-in and drop before the favourites themselves.
+	v.gaps = false
+	if got := joinSegments(statusSegments([]statusResult{live}, v, now)); strings.Contains(got, "▲") {
+		t.Errorf("gaps shown while disabled: %q", got)
+	}
+	v.gaps = true
+	if got := assembleSegments(statusSegments([]statusResult{live}, v, now), 80); strings.Contains(got, "▲") {
+		t.Errorf("gaps kept under width: %q", got)
+	}
+
+	if got := ordinal(2) + ordinal(3) + ordinal(11) + ordinal(21); got != "2nd3rd11th21st" {
+		t.Errorf("ordinal = %q", got)
+	}
+}
+
+func TestHideSpoilers(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	live := statusResult{name: "NASCAR", live: &series.LiveState{
+		ShortName: "NASCAR", RaceName: "Würth 400", TrackName: "Dover Motor Speedway",
+		CurrentLap: 50, TotalLaps: 400, FlagSymbol: "🟡", FlagName: "CAUTION",
+		Leader:    series.Driver{Number: "5", Name: "Larson", Position: 1},
+		Positions: []series.Driver{{Number: "5", Name: "Larson", Position: 1}},
+	}}
+	last := statusResult{name: "F1", last: &series.Result{
+		RaceName: "Miami Grand Prix", StartTime: now.Add(-5 * 24 * time.Hour),
+		Winner: series.Driver{Number: "4", Name: "NOR", Position: 1},
+	}}
+	results := []statusResult{live, last}
+	f := &spoiler.Filter{Config: config.SpoilerFree{Races: []string{"dover", "miami"}}}
+	hideSpoilers(results, f, now)
+
+	if r := results[0]; !r.spoiler || r.live.Leader.Number != "" || len(r.live.Positions) != 0 || r.live.FlagName != "" {
+		t.Errorf("live state not redacted: %+v", r.live)
+	}
+	if results[1].last != nil {
+		t.Errorf("hidden result kept: %+v", results[1].last)
+	}
+	// Templates never see the redacted state.
+	layout, err := compileStatusFormat(config.StatusFormat{Live: []config.StatusBlock{{Text: "{{.Live.RaceName}} lap {{.Live.CurrentLap}}"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := joinSegments(statusSegments(results, statusView{layout: layout, drivers: []int{5}, multiSeries: true}, now))
+	if got != "NASCAR: 🙈 Würth 400 in progress" {
+		t.Errorf("status = %q", got)
+	}
+	results[0].live.Finished = true
+	if got := joinSegments(statusSegments(results, statusView{}, now)); got != "🙈 Würth 400 (spoiler-free)" {
+		t.Errorf("finished = %q", got)
+	}
+	if classes := statusClasses(statusLine{results: results, chosen: &results[0]}); !slices.Contains(classes, "spoiler") {
+		t.Errorf("classes = %v", classes)
+	}
+
+	// Once watched, nothing is hidden.
+	f.Watch(spoiler.Race{Year: 2026, Name: "Dover"})
+	results = []statusResult{live}
+	hideSpoilers(results, f, now)
+	if results[0].spoiler || len(results[0].live.Positions) != 1 {
+		t.Errorf("watched race hidden: %+v", results[0])
+	}
+}
+
+func TestDelayLive(t *testing.T) {
+	dir := t.TempDir()
+	delayBufferPath = func(name string) string { return filepath.Join(dir, name+".json") }
+	t.Cleanup(func() { delayBufferPath = delay.BufferPath })
+
+	start := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+	run := func(at time.Duration, lap int) int {
+		results := []statusResult{{name: "NASCAR", live: &series.LiveState{RaceName: "Würth 400", CurrentLap: lap}}, {name: "F1"}}
+		delayLive(results, 30*time.Second, start.Add(at))
+		if results[1].live != nil {
+			t.Fatal("series without a live state got one")
+		}
+		return results[0].live.CurrentLap
+	}
+	// Each run is a tmux refresh five seconds apart, one lap per run.
+	for i := range 10 {
+		got := run(time.Duration(i)*5*time.Second, 100+i)
+		want := max(100+i-6, 100) // 30s behind, once buffered that far
+		if got != want {
+			t.Errorf("run %d: lap %d, want %d", i, got, want)
+		}
+	}
+
+	// When the session ends, the line catches up with the feed before it
+	// goes quiet.
+	ended := func(at time.Duration) *series.LiveState {
+		results := []statusResult{{name: "NASCAR"}}
+		delayLive(results, 30*time.Second, start.Add(at))
+		return results[0].live
+	}
+	for _, tt := range []struct {
+		at   time.Duration
+		want int
+	}{{50 * time.Second, 104}, {70 * time.Second, 108}, {80 * time.Second, 0}} {
+		got := 0
+		if st := ended(tt.at); st != nil {
+			got = st.CurrentLap
+		}
+		if got != tt.want {
+			t.Errorf("after the end, at %v: lap %d, want %d", tt.at, got, tt.want)
+		}
+	}
+	if _, err := os.Stat(delayBufferPath("NASCAR")); !os.IsNotExist(err) {
+		t.Errorf("buffer kept after the broadcast caught up: %v", err)
+	}
+	if st := ended(90 * time.Second); st != nil {
+		t.Errorf("no session: %+v", st)
+	}
+
+	// Without a delay, live states pass through untouched.
+	results := []statusResult{{name: "NASCAR", live: &series.LiveState{CurrentLap: 120}}}
+	delayLive(results, 0, start.Add(time.Minute))
+	if results[0].live.CurrentLap != 120 {
+		t.Errorf("no delay: lap %d", results[0].live.CurrentLap)
+	}
+}
+
+// recordSink collects the events sent to it.
+type recordSink struct{ sent *[]string }
+
+func (recordSink) Name() string { return "record" }
+
+func (s recordSink) Send(_ context.Context, e events.Event) error {
+	*s.sent = append(*s.sent, e.Title()+": "+e.Message())
+	return nil
+}
+
+func TestNotifyEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+	var sent []string
+	eventsPath = func() string { return path }
+	newDispatcher = func(cfg config.Notify, _ bool) (*notify.Dispatcher, error) {
+		d := &notify.Dispatcher{}
+		d.Add(recordSink{&sent}, func(k events.Kind) bool { return notify.Wants(cfg, k) })
+		return d, nil
+	}
+	t.Cleanup(func() { eventsPath, newDispatcher = events.Path, notify.New })
+
+	now := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+	live := func(lap int, flag string, leader string) []statusResult {
+		return []statusResult{{name: "NASCAR", live: &series.LiveState{
+			ShortName: "NASCAR", RaceName: "Würth 400", TrackName: "Dover Motor Speedway",
+			CurrentLap: lap, FlagName: flag, Leader: series.Driver{Number: leader, Name: "Driver" + leader},
+		}}}
+	}
+	cfg := config.DefaultConfig()
+	cfg.Notify.Cautions = true // lead changes stay off
+	run := func(results []statusResult, filter *spoiler.Filter, at time.Duration) {
+		sendEvents(cfg, detectEvents(results, nil, nil, filter, now.Add(at)))
+	}
+	run(live(56, "Green", "5"), nil, 0)
+	run(live(57, "Caution", "24"), nil, 5*time.Second)
+	run(live(57, "Caution", "24"), nil, 10*time.Second)
+	want := []string{"NASCAR · Würth 400: Caution, lap 57"}
+	if !slices.Equal(sent, want) {
+		t.Errorf("sent %q, want %q", sent, want)
+	}
+
+	// A failed series keeps its last state; a spoiler-free race is silent.
+	sent = nil
+	run([]statusResult{{name: "NASCAR", err: errNoAnswer}}, nil, 15*time.Second)
+	hidden := &spoiler.Filter{Config: config.SpoilerFree{Races: []string{"dover"}}}
+	run(live(60, "Green", "24"), hidden, 20*time.Second)
+	if len(sent) != 0 {
+		t.Errorf("sent %q", sent)
+	}
+	run(live(61, "Caution", "24"), nil, 25*time.Second)
+	if len(sent) != 1 {
+		t.Errorf("tracking stopped for the hidden race: sent %q", sent)
+	}
+
+	// Status processes running at once report a change once.
+	run(live(62, "Green", "24"), nil, 30*time.Second)
+	var mu sync.Mutex
+	var found []events.Event
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			evs := detectEvents(live(63, "Caution", "24"), nil, nil, nil, now.Add(35*time.Second))
+			mu.Lock()
+			found = append(found, evs...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if len(found) != 1 {
+		t.Errorf("concurrent runs found %+v, want one caution", found)
+	}
+}
+
+func TestDueReminders(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reminders")
+	remindersDir = func() string { return dir }
+	t.Cleanup(func() { remindersDir = reminder.Dir })
+
+	start := time.Date(2026, 2, 15, 19, 30, 0, 0, time.UTC)
+	s := &fakeSeries{name: "NASCAR", races: []series.Race{
+		{ShortName: "NASCAR", RaceName: "DAYTONA 500", Broadcaster: "FOX", StartTime: start},
+	}}
+	cfg := config.DefaultConfig()
+	cfg.Weather = false
+	if evs := dueReminders(cfg, []series.Series{s}, start.Add(-5*time.Minute)); evs != nil {
+		t.Errorf("reminders off: %v", evs)
+	}
+
+	// Status processes racing for the same reminder send it once.
+	cfg.Notify.Reminders = []config.Duration{config.Duration(time.Hour), config.Duration(10 * time.Minute)}
+	now := start.Add(-time.Hour)
+	var mu sync.Mutex
+	var got []events.Event
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			evs := dueReminders(cfg, []series.Series{s}, now)
+			mu.Lock()
+			got = append(got, evs...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if len(got) != 1 || got[0].Kind != events.Reminder || got[0].Broadcaster != "FOX" {
+		t.Fatalf("reminders = %+v", got)
+	}
+	if !strings.HasPrefix(got[0].Message(), "Race in 1h, ") {
+		t.Errorf("message = %q", got[0].Message())
+	}
+
+	if evs := dueReminders(cfg, []series.Series{s}, start.Add(-10*time.Minute)); len(evs) != 1 || evs[0].Until != 10*time.Minute {
+		t.Errorf("10m reminder = %+v", evs)
+	}
+	if evs := dueReminders(cfg, []series.Series{s}, start.Add(-time.Minute)); evs != nil {
+		t.Errorf("repeated reminder: %+v", evs)
+	}
+}
+
+func TestSpoilersCmd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spoilers.json")
+	spoilersPath = func() string { return path }
+	t.Cleanup(func() { spoilersPath = spoiler.Path })
+	cfg := config.DefaultConfig()
+	cfg.SpoilerFree.Races = []string{"Daytona 500"}
+
+	run := func(args ...string) string {
+		t.Helper()
+		var out strings.Builder
+		if code := runSpoilersCmd(cfg, args, &out); code != 0 {
+			t.Fatalf("spoilers %v exited %d", args, code)
+		}
+		return out.String()
+	}
+	run("hide", "--year", "2026", "Bristol")
+	run("on")
+	run("watched", "--year", "2026", "daytona")
+	got := run()
+	want := "All races: spoiler-free\n" +
+		"Hidden:    Daytona 500 (every season, from config)\n" +
+		"Hidden:    Bristol (2026)\n" +
+		"Watched:   daytona (2026)\n"
+	if got != want {
+		t.Errorf("status = %q\nwant     %q", got, want)
+	}
+	if code := runSpoilersCmd(cfg, []string{"hide"}, &strings.Builder{}); code != 2 {
+		t.Errorf("hide without a race exited %d, want 2", code)
+	}
+}
+
+func TestCompileStatusFormatError(t *testing.T) {
+	_, err := compileStatusFormat(config.StatusFormat{Finished: []config.StatusBlock{{Text: "{{.Live.RaceName"}}})
+	if err == nil || !strings.Contains(err.Error(), "status_format.finished[0]") {
+		t.Errorf("err = %v, want it to name the block", err)
+	}
+	if l, err := compileStatusFormat(config.StatusFormat{}); l != nil || err != nil {
+		t.Errorf("empty format = %v, %v; want nil layout", l, err)
+	}
+}
+
+func TestTmuxStyledStatus(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	st := config.DefaultConfig().StatusStyle
+	st.Tmux = true
+	live := statusResult{name: "NASCAR", stale: true, staleAt: now.Add(-3 * time.Minute), live: &series.LiveState{
+		RaceName: "Dover 400", CurrentLap: 50, TotalLaps: 400, FlagSymbol: "🟡", FlagName: "Caution",
+		Leader:    series.Driver{Number: "5", Name: "Larson"},
+		Positions: []series.Driver{{Number: "5", Name: "Larson", Position: 1}, {Number: "24", Name: "Byron", Position: 6}},
+	}}
+	v := statusView{theme: newTmuxTheme(st), drivers: []int{24}}
+	segs := statusSegments([]statusResult{live}, v, now)
+
+	want := []string{
+		"#[fg=black,bg=yellow]🟡 Dover 400 | Lap 50/400#[default]",
+		" | P1 #5 Larson",
+		" | #[fg=cyan,bold]#24 Byron P6#[default]",
+		" #[fg=colour244]⚠ 3m old#[default]",
+	}
+	if len(segs) != len(want) {
+		t.Fatalf("got %d segments: %+v", len(segs), segs)
+	}
+	for i := range want {
+		if segs[i].text != want[i] {
+			t.Errorf("segment %d = %q, want %q", i, segs[i].text, want[i])
+		}
+	}
+
+	// Widths ignore the style sequences: the plain line fits exactly.
+	plain := ui.StripStyles(assembleSegments(segs, 0))
+	full := assembleSegments(segs, runewidth.StringWidth(plain))
+	if ui.StripStyles(full) != plain {
+		t.Errorf("styles counted towards width: %q", full)
+	}
+
+	// Without tmux styling the output is unchanged plain text.
+	v.theme = newTmuxTheme(config.DefaultConfig().StatusStyle)
+	if got := joinSegments(statusSegments([]statusResult{live}, v, now)); strings.Contains(got, "#[") {
+		t.Errorf("plain output has styles: %q", got)
+	}
+}
+
+func TestWriteStatusFormats(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	st := config.DefaultConfig().StatusStyle
+	results := []statusResult{{name: "NASCAR", live: &series.LiveState{
+		RaceName: "Food & Fuel 400", CurrentLap: 50, TotalLaps: 400, FlagSymbol: "🟡", FlagName: "Caution",
+		Positions: []series.Driver{{Number: "24", Name: "Byron", Position: 6}},
+	}}}
+
+	render := func(format string) string {
+		t.Helper()
+		segs := statusSegments(results, statusView{theme: outputTheme(format, st), drivers: []int{24}}, now)
+		l := statusLine{text: assembleSegments(segs, 0), segments: segs, results: results, chosen: chooseResult(results)}
+		var out strings.Builder
+		if err := writeStatus(&out, format, l, st); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		return out.String()
+	}
+
+	if got := render(outputTmux); got != "🟡 Food & Fuel 400 | Lap 50/400 | #24 Byron P6" {
+		t.Errorf("tmux = %q", got)
+	}
+
+	var waybar struct {
+		Text, Tooltip string
+		Class         []string
+	}
+	if err := json.Unmarshal([]byte(render(outputWaybar)), &waybar); err != nil {
+		t.Fatal(err)
+	}
+	if waybar.Text != "🟡 Food &amp; Fuel 400 | Lap 50/400 | #24 Byron P6" ||
+		waybar.Tooltip != "🟡 Food &amp; Fuel 400 | Lap 50/400\n#24 Byron P6" ||
+		!slices.Equal(waybar.Class, []string{"live", "caution"}) {
+		t.Errorf("waybar = %+v", waybar)
+	}
+
+	var i3 struct {
+		FullText  string `json:"full_text"`
+		ShortText string `json:"short_text"`
+		Markup    string
+	}
+	if err := json.Unmarshal([]byte(render(outputI3blocks)), &i3); err != nil {
+		t.Fatal(err)
+	}
+	wantFull := `<span foreground="#000000" background="#cdcd00">🟡 Food &amp; Fuel 400 | Lap 50/400</span>` +
+		` | <span foreground="#00cdcd" weight="bold">#24 Byron P6</span>`
+	if i3.FullText != wantFull || i3.Markup != "pango" || !strings.HasPrefix(i3.ShortText, "<span") {
+		t.Errorf("i3blocks = %+v", i3)
+	}
+
+	if got := render(outputPolybar); got != "%{F#000000}%{B#cdcd00}🟡 Food & Fuel 400 | Lap 50/400%{F-}%{B-} | %{F#00cdcd}#24 Byron P6%{F-}%{B-}\n" {
+		t.Errorf("polybar = %q", got)
+	}
+
+	sketchy := render(outputSketchybar)
+	for _, want := range []string{"label=🟡 Food & Fuel 400 | Lap 50/400 | #24 Byron P6\n", "label.color=0xff000000\n", "background.color=0xffcdcd00\n"} {
+		if !strings.Contains(sketchy, want) {
+			t.Errorf("sketchybar missing %q:\n%s", want, sketchy)
+		}
+	}
+
+	var raw struct {
+		Segments []struct{ Text string }
+		Series   []struct {
+			Name   string
+			Chosen bool
+			Live   map[string]any
+		}
+	}
+	if err := json.Unmarshal([]byte(render(outputJSON)), &raw); err != nil {
+		t.Fatal(err)
+	}
+	if len(raw.Segments) != 2 || len(raw.Series) != 1 || !raw.Series[0].Chosen || raw.Series[0].Live["current_lap"] != 50.0 {
+		t.Errorf("json = %+v", raw)
+	}
+	if pos, _ := raw.Series[0].Live["positions"].([]any); len(pos) != 1 || pos[0].(map[string]any)["number"] != "24" {
+		t.Errorf("json positions = %v, want snake_case keys", raw.Series[0].Live["positions"])
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jfmyers/tmux-raceday/internal/mockserver"
+)
+
+const mockServerUsage = `usage: raceday mock-server [flags]
+
+Serves fixtures shaped like the NASCAR, OpenF1 and Open-Meteo APIs.
+Routes: race_list_basic, weekend-feed, live-feed, live-points, meetings,
+sessions, position, race_control, stints, drivers, intervals,
+session_result, forecast (or * for all).
+
+Flags:
+`
+
+// routeFlag collects repeated route=value flags.
+type routeFlag map[string]string
+
+func (f routeFlag) String() string {
+	pairs := make([]string, 0, len(f))
+	for k, v := range f {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f routeFlag) Set(s string) error {
+	route, value, ok := strings.Cut(s, "=")
+	if !ok || route == "" || value == "" {
+		return fmt.Errorf("want route=value, got %q", s)
+	}
+	f[route] = value
+	return nil
+}
+
+// runMockServerCmd implements `raceday mock-server`.
+func runMockServerCmd(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("mock-server", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), mockServerUsage)
+		fs.PrintDefaults()
+	}
+	addr := fs.String("addr", "127.0.0.1:8787", "Address to listen on")
+	fixtures := fs.String("fixtures", "", "Directory of fixtures overriding the built-in ones")
+	latency := fs.Duration("latency", 0, "Delay added to every response")
+	faults := routeFlag{}
+	fs.Var(faults, "fail", "Answer route with an HTTP status, e.g. live-feed=503 (repeatable)")
+	drift := routeFlag{}
+	fs.Var(drift, "drift", "Apply schema drift to route: rename, retype or truncate (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	opts := mockserver.Options{
+		Fixtures: *fixtures,
+		Latency:  *latency,
+		Faults:   make(map[string]int),
+		Drift:    drift,
+	}
+	for route, v := range faults {
+		code, err := strconv.Atoi(v)
+		if err != nil || code < 100 || code > 599 {
+			fmt.Fprintf(os.Stderr, "raceday: --fail %s: invalid status %q\n", route, v)
+			return 2
+		}
+		opts.Faults[route] = code
+	}
+	for route, mode := range drift {
+		switch mode {
+		case mockserver.DriftRename, mockserver.DriftRetype, mockserver.DriftTruncate:
+		default:
+			fmt.Fprintf(os.Stderr, "raceday: --drift %s: unknown mode %q\n", route, mode)
+			return 2
+		}
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "raceday: %v\n", err)
+		return 1
+	}
+	root := "http://" + ln.Addr().String()
+	ep := mockserver.Endpoints(root)
+	fmt.Fprintf(out, "Mock server listening on %s\n\n", root)
+	fmt.Fprintf(out, "export RACEDAY_NASCAR_URL=%s\n", ep.NASCAR)
+	fmt.Fprintf(out, "export RACEDAY_OPENF1_URL=%s\n", ep.OpenF1)
+	fmt.Fprintf(out, "export RACEDAY_OPEN_METEO_URL=%s\n", ep.OpenMeteo)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	srv := &http.Server{Handler: mockserver.New(opts)}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "raceday: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/events"
+	"github.com/jfmyers/tmux-raceday/internal/notify"
+	"github.com/jfmyers/tmux-raceday/internal/reminder"
+	"github.com/jfmyers/tmux-raceday/internal/series"
+	"github.com/jfmyers/tmux-raceday/internal/spoiler"
+)
+
+// notifyTimeout bounds sending a status run's events to every sink.
+const notifyTimeout = 10 * time.Second
+
+// eventsPath is where status runs remember the last live states,
+// remindersDir where they record the reminders sent, and newDispatcher
+// builds the sinks events go to. Tests replace them.
+var (
+	eventsPath    = events.Path
+	remindersDir  = reminder.Dir
+	newDispatcher = notify.New
+)
+
+// detectEvents compares each series' live state with the one the last
+// status run saw and returns what happened since, including the custom
+// rules that became true. Spoiler-free races are tracked but stay quiet.
+// Series that failed keep their last state, so a blip doesn't reset the
+// comparison. The saved states are locked from load to save, so status
+// processes running at once each see the states the last one left and
+// report each event once.
+func detectEvents(results []statusResult, drivers []int, rules []config.Rule, filter *spoiler.Filter, now time.Time) []events.Event {
+	path := eventsPath()
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	unlock, err := cache.Lock(ctx, path)
+	if err != nil {
+		slog.Warn("event state busy, events skipped", "err", err)
+		return nil
+	}
+	defer unlock()
+	t, err := events.LoadTracker(path)
+	if err != nil {
+		slog.Warn("event state reset", "err", err)
+	}
+	t.Rules, err = events.CompileRules(rules)
+	if err != nil {
+		slog.Warn("notify rules ignored", "err", err)
+	}
+	favorites := make([]string, len(drivers))
+	for i, d := range drivers {
+		favorites[i] = strconv.Itoa(d)
+	}
+	var out []events.Event
+	for _, r := range results {
+		if r.err != nil {
+			continue
+		}
+		evs := t.Observe(r.name, r.live, now, favorites)
+		if r.live != nil && filter.Hides(now.Year(), r.live.RaceName, r.live.TrackName) {
+			continue
+		}
+		for _, e := range evs {
+			slog.Info("event", "kind", e.Kind, "message", e.Message())
+		}
+		out = append(out, evs...)
+	}
+	if err := t.Save(path); err != nil {
+		slog.Warn("event state not saved", "err", err)
+	}
+	return out
+}
+
+// dueReminders returns reminders of the sessions in allSeries about to
+// start. Each is claimed first, so however many status processes run,
+// one sends it.
+func dueReminders(cfg config.Config, allSeries []series.Series, now time.Time) []events.Event {
+	if len(cfg.Notify.Reminders) == 0 {
+		return nil
+	}
+	before := make([]time.Duration, len(cfg.Notify.Reminders))
+	longest := time.Duration(0)
+	for i, d := range cfg.Notify.Reminders {
+		before[i] = time.Duration(d)
+		longest = max(longest, before[i])
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	dir := remindersDir()
+	var out []events.Event
+	for _, s := range allSeries {
+		var sessions []series.Session
+		for year := now.Year(); year <= now.Add(longest).Year(); year++ {
+			ss, err := series.Sessions(ctx, s, year)
+			if err != nil {
+				slog.Warn("reminder sessions failed", "series", s.ShortName(), "year", year, "err", err)
+				continue
+			}
+			sessions = append(sessions, ss...)
+		}
+		for _, r := range reminder.Due(sessions, before, cfg.Notify.ReminderSessions, now) {
+			claimed, err := reminder.Claim(dir, r)
+			if err != nil {
+				slog.Warn("reminder not recorded", "key", r.Key(), "err", err)
+				continue
+			}
+			if !claimed {
+				continue
+			}
+			var conditions string
+			if cfg.Weather {
+				conditions = strings.TrimPrefix(weatherSuffixFromCoords(ctx, r.Session.Race.Lat, r.Session.Race.Lon), " | ")
+			}
+			e := r.Event(now, conditions)
+			slog.Info("event", "kind", e.Kind, "message", e.Message())
+			out = append(out, e)
+		}
+	}
+	if len(out) > 0 {
+		if err := reminder.Prune(dir, now); err != nil {
+			slog.Warn("old reminders not pruned", "err", err)
+		}
+	}
+	return out
+}
+
+// sendEvents delivers evs to the sinks cfg.Notify configures. With
+// cooldowns it runs even without new events, to send the digests of
+// those held back.
+func sendEvents(cfg config.Config, evs []events.Event) {
+	if len(evs) == 0 && len(cfg.Notify.Cooldowns) == 0 {
+		return
+	}
+	d, err := newDispatcher(cfg.Notify, cfg.Offline)
+	if err != nil {
+		slog.Warn("notify sinks ignored", "err", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	d.Send(ctx, evs)
+}
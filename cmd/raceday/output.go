@@ -0,0 +1,395 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/series"
+	"github.com/jfmyers/tmux-raceday/internal/ui"
+)
+
+// Status output formats for --output.
+const (
+	outputTmux       = "tmux"
+	outputWaybar     = "waybar"
+	outputI3blocks   = "i3blocks"
+	outputPolybar    = "polybar"
+	outputSketchybar = "sketchybar"
+	outputJSON       = "json"
+)
+
+var outputFormats = []string{outputTmux, outputWaybar, outputI3blocks, outputPolybar, outputSketchybar, outputJSON}
+
+// statusLine is a rendered status line and what it was built from.
+type statusLine struct {
+	text     string // fitted to the width; carries tmux styles if themed
+	segments []segment
+	results  []statusResult
+	chosen   *statusResult
+}
+
+// outputTheme returns the tmux theme to render format with. polybar and
+// i3blocks always get one, since their colours are translated from the
+// tmux styles; tmux only when styling is on. The rest stay plain.
+func outputTheme(format string, st config.StatusStyle) *tmuxTheme {
+	switch format {
+	case outputPolybar, outputI3blocks:
+		st.Tmux = true
+	case outputTmux:
+	default:
+		st.Tmux = false
+	}
+	return newTmuxTheme(st)
+}
+
+// writeStatus writes l to w in format.
+func writeStatus(w io.Writer, format string, l statusLine, st config.StatusStyle) error {
+	switch format {
+	case outputWaybar:
+		return writeWaybar(w, l)
+	case outputI3blocks:
+		return writeI3blocks(w, l)
+	case outputPolybar:
+		_, err := fmt.Fprintln(w, polybarMarkup(l.text))
+		return err
+	case outputSketchybar:
+		return writeSketchybar(w, l, st)
+	case outputJSON:
+		return writeJSON(w, l)
+	}
+	_, err := fmt.Fprint(w, l.text)
+	return err
+}
+
+// statusClasses describes the line's state for bars that style by class,
+// e.g. ["live", "caution", "stale"].
+func statusClasses(l statusLine) []string {
+	var classes []string
+	switch r := l.chosen; {
+	case r == nil && allFailed(l.results):
+		classes = append(classes, "unavailable")
+	case r == nil:
+		classes = append(classes, "idle")
+	case r.live != nil && r.live.Finished:
+		classes = append(classes, "finished")
+	case r.live != nil:
+		classes = append(classes, "live")
+		if kind := flagKind(r.live); kind != "" {
+			classes = append(classes, kind)
+		}
+	default:
+		classes = append(classes, "scheduled")
+	}
+	if l.chosen != nil && l.chosen.spoiler {
+		classes = append(classes, "spoiler")
+	}
+	if l.chosen != nil && l.chosen.stale {
+		classes = append(classes, "stale")
+	}
+	for _, r := range l.results {
+		if r.err != nil {
+			classes = append(classes, "error")
+			break
+		}
+	}
+	return classes
+}
+
+// tooltip lists every segment on its own line, unfitted.
+func tooltip(segs []segment) string {
+	var lines []string
+	for _, s := range segs {
+		text := strings.TrimSpace(strings.TrimPrefix(ui.StripStyles(s.text), " | "))
+		if text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// coreText joins the priority-0 segments, for bars that want a short form.
+func coreText(segs []segment) string {
+	var core []segment
+	for _, s := range segs {
+		if s.priority == 0 {
+			core = append(core, s)
+		}
+	}
+	return joinSegments(core)
+}
+
+// pangoEscaper escapes text for Pango markup, which waybar and i3bar parse.
+var pangoEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// writeWaybar writes a waybar custom module return-type=json object.
+func writeWaybar(w io.Writer, l statusLine) error {
+	classes := statusClasses(l)
+	return json.NewEncoder(w).Encode(struct {
+		Text    string   `json:"text"`
+		Tooltip string   `json:"tooltip"`
+		Class   []string `json:"class"`
+		Alt     string   `json:"alt"`
+	}{
+		Text:    pangoEscaper.Replace(strings.TrimRight(l.text, " ")),
+		Tooltip: pangoEscaper.Replace(tooltip(l.segments)),
+		Class:   classes,
+		Alt:     classes[0],
+	})
+}
+
+// writeI3blocks writes an i3bar protocol block, as read by i3blocks with
+// format=json. Colours are Pango spans translated from the tmux styles.
+func writeI3blocks(w io.Writer, l statusLine) error {
+	return json.NewEncoder(w).Encode(struct {
+		FullText  string `json:"full_text"`
+		ShortText string `json:"short_text"`
+		Markup    string `json:"markup"`
+	}{
+		FullText:  pangoMarkup(strings.TrimRight(l.text, " ")),
+		ShortText: pangoMarkup(coreText(l.segments)),
+		Markup:    "pango",
+	})
+}
+
+// pangoMarkup converts tmux styles in s to Pango spans.
+func pangoMarkup(s string) string {
+	open := false
+	return ui.ConvertStyles(s, func(spec string) string {
+		var b strings.Builder
+		if open {
+			b.WriteString("</span>")
+			open = false
+		}
+		if spec == "default" {
+			return b.String()
+		}
+		fg, bg := ui.StyleColors(spec)
+		b.WriteString("<span")
+		if fg != "" {
+			fmt.Fprintf(&b, ` foreground="%s"`, fg)
+		}
+		if bg != "" {
+			fmt.Fprintf(&b, ` background="%s"`, bg)
+		}
+		if strings.Contains(spec, "bold") {
+			b.WriteString(` weight="bold"`)
+		}
+		b.WriteString(">")
+		open = true
+		return b.String()
+	}, pangoEscaper.Replace)
+}
+
+// polybarMarkup converts tmux styles in s to polybar format tags.
+func polybarMarkup(s string) string {
+	return ui.ConvertStyles(s, func(spec string) string {
+		if spec == "default" {
+			return "%{F-}%{B-}"
+		}
+		var b strings.Builder
+		fg, bg := ui.StyleColors(spec)
+		if fg != "" {
+			b.WriteString("%{F" + fg + "}")
+		}
+		if bg != "" {
+			b.WriteString("%{B" + bg + "}")
+		}
+		return b.String()
+	}, nil)
+}
+
+// writeSketchybar writes one sketchybar property per line, each ready to
+// pass as a single argument to `sketchybar --set`.
+func writeSketchybar(w io.Writer, l statusLine, st config.StatusStyle) error {
+	props := []string{"label=" + strings.TrimRight(l.text, " ")}
+	style := ""
+	if l.chosen != nil && l.chosen.live != nil {
+		style = kindStyle(st, flagKind(l.chosen.live))
+	}
+	fg, bg := ui.StyleColors(style)
+	if fg != "" {
+		props = append(props, "label.color="+argbColor(fg))
+	}
+	if bg != "" {
+		props = append(props, "background.color="+argbColor(bg), "background.drawing=on")
+	} else {
+		props = append(props, "background.drawing=off")
+	}
+	_, err := fmt.Fprintln(w, strings.Join(props, "\n"))
+	return err
+}
+
+// argbColor converts #rrggbb to sketchybar's opaque 0xffrrggbb.
+func argbColor(hex string) string {
+	return "0xff" + strings.TrimPrefix(hex, "#")
+}
+
+// writeJSON writes the segments and each series' underlying state.
+func writeJSON(w io.Writer, l statusLine) error {
+	type jsonSegment struct {
+		Text     string `json:"text"`
+		Priority int    `json:"priority"`
+		Static   bool   `json:"static"`
+	}
+	type jsonSeries struct {
+		Name       string      `json:"name"`
+		Chosen     bool        `json:"chosen"`
+		Live       *jsonLive   `json:"live,omitempty"`
+		Next       *jsonRace   `json:"next,omitempty"`
+		Last       *jsonResult `json:"last,omitempty"`
+		Weather    string      `json:"weather,omitempty"`
+		Stale      bool        `json:"stale"`
+		StaleSince *time.Time  `json:"stale_since,omitempty"`
+		Error      string      `json:"error,omitempty"`
+	}
+	out := struct {
+		Text     string        `json:"text"`
+		Classes  []string      `json:"classes"`
+		Segments []jsonSegment `json:"segments"`
+		Series   []jsonSeries  `json:"series"`
+	}{
+		Text:    strings.TrimRight(l.text, " "),
+		Classes: statusClasses(l),
+	}
+	for _, s := range l.segments {
+		out.Segments = append(out.Segments, jsonSegment{s.text, s.priority, s.static})
+	}
+	for i := range l.results {
+		r := &l.results[i]
+		js := jsonSeries{
+			Name:    r.name,
+			Chosen:  r == l.chosen,
+			Live:    newJSONLive(r.live),
+			Next:    newJSONRace(r.next),
+			Last:    newJSONResult(r.last),
+			Weather: strings.TrimPrefix(r.weather, " | "),
+			Stale:   r.stale,
+		}
+		if !r.staleAt.IsZero() {
+			js.StaleSince = &r.staleAt
+		}
+		if r.err != nil {
+			js.Error = r.err.Error()
+		}
+		out.Series = append(out.Series, js)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// The JSON output has its own types, so that its keys are snake_case like
+// the rest of the document and don't change when the series types do.
+
+type jsonDriver struct {
+	Number     string  `json:"number"`
+	Name       string  `json:"name"`
+	FullName   string  `json:"full_name,omitempty"`
+	Team       string  `json:"team,omitempty"`
+	Position   int     `json:"position"`
+	Gap        string  `json:"gap,omitempty"`
+	GapAhead   string  `json:"gap_ahead,omitempty"`
+	GapBehind  string  `json:"gap_behind,omitempty"`
+	LapsDown   int     `json:"laps_down"`
+	LuckyDog   int     `json:"lucky_dog,omitempty"`
+	Delta      float64 `json:"delta"`
+	Compound   string  `json:"compound,omitempty"`
+	Points     float64 `json:"points,omitempty"`
+	PitStops   int     `json:"pit_stops,omitempty"`
+	LastPitLap int     `json:"last_pit_lap,omitempty"`
+	Make       string  `json:"make,omitempty"`
+}
+
+func newJSONDriver(d series.Driver) jsonDriver {
+	return jsonDriver{
+		Number: d.Number, Name: d.Name, FullName: d.FullName, Team: d.Team,
+		Position: d.Position, Gap: d.Gap, GapAhead: d.GapAhead, GapBehind: d.GapBehind,
+		LapsDown: d.LapsDown, LuckyDog: d.LuckyDog, Delta: d.Delta, Compound: d.Compound,
+		Points: d.Points, PitStops: d.PitStops, LastPitLap: d.LastPitLap, Make: d.Make,
+	}
+}
+
+func newJSONDrivers(ds []series.Driver) []jsonDriver {
+	out := make([]jsonDriver, len(ds))
+	for i, d := range ds {
+		out[i] = newJSONDriver(d)
+	}
+	return out
+}
+
+type jsonLive struct {
+	SeriesName string       `json:"series_name"`
+	ShortName  string       `json:"short_name"`
+	RaceName   string       `json:"race_name"`
+	TrackName  string       `json:"track_name"`
+	CurrentLap int          `json:"current_lap"`
+	TotalLaps  int          `json:"total_laps,omitempty"`
+	FlagSymbol string       `json:"flag_symbol"`
+	FlagName   string       `json:"flag_name"`
+	Stage      int          `json:"stage,omitempty"`
+	Finished   bool         `json:"finished"`
+	Leader     jsonDriver   `json:"leader"`
+	Positions  []jsonDriver `json:"positions"`
+	Lat        float64      `json:"lat,omitempty"`
+	Lon        float64      `json:"lon,omitempty"`
+}
+
+func newJSONLive(st *series.LiveState) *jsonLive {
+	if st == nil {
+		return nil
+	}
+	return &jsonLive{
+		SeriesName: st.SeriesName, ShortName: st.ShortName, RaceName: st.RaceName, TrackName: st.TrackName,
+		CurrentLap: st.CurrentLap, TotalLaps: st.TotalLaps, FlagSymbol: st.FlagSymbol, FlagName: st.FlagName,
+		Stage: st.Stage, Finished: st.Finished, Leader: newJSONDriver(st.Leader),
+		Positions: newJSONDrivers(st.Positions), Lat: st.Lat, Lon: st.Lon,
+	}
+}
+
+type jsonRace struct {
+	SeriesName  string    `json:"series_name"`
+	ShortName   string    `json:"short_name"`
+	RaceName    string    `json:"race_name"`
+	TrackName   string    `json:"track_name"`
+	StartTime   time.Time `json:"start_time"`
+	Broadcaster string    `json:"broadcaster,omitempty"`
+	Complete    bool      `json:"complete"`
+	Lat         float64   `json:"lat,omitempty"`
+	Lon         float64   `json:"lon,omitempty"`
+}
+
+func newJSONRace(r *series.Race) *jsonRace {
+	if r == nil {
+		return nil
+	}
+	return &jsonRace{
+		SeriesName: r.SeriesName, ShortName: r.ShortName, RaceName: r.RaceName, TrackName: r.TrackName,
+		StartTime: r.StartTime, Broadcaster: r.Broadcaster, Complete: r.Complete, Lat: r.Lat, Lon: r.Lon,
+	}
+}
+
+type jsonResult struct {
+	SeriesName string       `json:"series_name"`
+	ShortName  string       `json:"short_name"`
+	RaceName   string       `json:"race_name"`
+	TrackName  string       `json:"track_name"`
+	StartTime  time.Time    `json:"start_time"`
+	EndTime    time.Time    `json:"end_time"`
+	Winner     jsonDriver   `json:"winner"`
+	Positions  []jsonDriver `json:"positions"`
+}
+
+func newJSONResult(r *series.Result) *jsonResult {
+	if r == nil {
+		return nil
+	}
+	return &jsonResult{
+		SeriesName: r.SeriesName, ShortName: r.ShortName, RaceName: r.RaceName, TrackName: r.TrackName,
+		StartTime: r.StartTime, EndTime: r.EndTime, Winner: newJSONDriver(r.Winner),
+		Positions: newJSONDrivers(r.Positions),
+	}
+}
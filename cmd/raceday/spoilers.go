@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/spoiler"
+)
+
+const spoilersUsage = `usage: raceday spoilers [command] [--year YEAR] [RACE]
+
+Commands:
+  status        show which races are spoiler-free (default)
+  on            hide every race until it is marked watched
+  off           stop hiding every race; races hidden by name stay hidden
+  hide RACE     hide RACE's positions and results until it is watched
+  watched RACE  mark RACE watched, showing its positions and results again
+
+RACE matches any part of a race or track name, e.g. "daytona". It applies
+to the current season unless --year is given.
+`
+
+// spoilersPath is where the spoiler state lives. Tests replace it.
+var spoilersPath = spoiler.Path
+
+// runSpoilersCmd implements `raceday spoilers`.
+func runSpoilersCmd(cfg config.Config, args []string, out io.Writer) int {
+	cmd := "status"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("spoilers", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, spoilersUsage) }
+	year := fs.Int("year", time.Now().Year(), "Season the race is in")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	name := strings.Join(fs.Args(), " ")
+
+	path := spoilersPath()
+	st, err := spoiler.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "raceday: %v\n", err)
+		return 1
+	}
+	switch cmd {
+	case "status":
+		printSpoilers(out, cfg.SpoilerFree, st)
+		return 0
+	case "on":
+		st.All = true
+		fmt.Fprintln(out, "Spoiler-free mode on for every race")
+	case "off":
+		st.All = false
+		fmt.Fprintln(out, "Spoiler-free mode off")
+	case "hide", "watched":
+		if name == "" {
+			fmt.Fprint(os.Stderr, spoilersUsage)
+			return 2
+		}
+		r := spoiler.Race{Year: *year, Name: name}
+		if cmd == "hide" {
+			st.Hide(r)
+			fmt.Fprintf(out, "Hiding %s\n", r)
+		} else {
+			st.Watch(r)
+			fmt.Fprintf(out, "Marked %s watched\n", r)
+		}
+	default:
+		fmt.Fprint(os.Stderr, spoilersUsage)
+		return 2
+	}
+	if err := st.Save(path); err != nil {
+		fmt.Fprintf(os.Stderr, "raceday: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// printSpoilers lists what spoiler-free mode hides.
+func printSpoilers(out io.Writer, cfg config.SpoilerFree, st spoiler.State) {
+	switch {
+	case st.All || cfg.All:
+		fmt.Fprintln(out, "All races: spoiler-free")
+	default:
+		fmt.Fprintln(out, "All races: shown")
+	}
+	for _, pattern := range cfg.Races {
+		fmt.Fprintf(out, "Hidden:    %s (every season, from config)\n", pattern)
+	}
+	for _, r := range st.Hidden {
+		fmt.Fprintf(out, "Hidden:    %s\n", r)
+	}
+	for _, r := range st.Watched {
+		fmt.Fprintf(out, "Watched:   %s\n", r)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/series"
+)
+
+// statusLayout renders the status line from status_format templates. A
+// nil layout, or a state with no blocks, falls back to the built-in
+// layout.
+type statusLayout struct {
+	live, scheduled, finished []statusBlock
+}
+
+type statusBlock struct {
+	tmpl     *template.Template
+	priority int
+	static   bool
+}
+
+// statusData is what status_format templates see.
+type statusData struct {
+	Series    string            // series short name, e.g. "NASCAR"
+	Prefix    string            // "NASCAR: " when several series are configured
+	Live      *series.LiveState // live and finished states
+	Race      *series.Race      // scheduled state
+	Favorites []series.Driver   // favourite drivers in the running order (live and finished)
+	Drivers   []int             // favourite car numbers from the config
+	Weather   string            // e.g. "72°F ☀️ 12mph ↗"; empty when not shown
+	Now       time.Time
+
+	Last          *series.Result  // most recent result within last_result (scheduled); nil if none
+	LastFavorites []series.Driver // favourite drivers' finishes in Last
+}
+
+// statusFuncs are the helpers available to status_format templates.
+func statusFuncs(now time.Time) template.FuncMap {
+	return template.FuncMap{
+		"when":   func(t time.Time) string { return formatRaceTime(t, now) },
+		"days":   func(t time.Time) int { return daysUntil(now, t) },
+		"delta":  formatDelta,
+		"gaps":   gapText,
+		"points": formatPoints,
+		"upper":  strings.ToUpper,
+	}
+}
+
+// compileStatusFormat parses the status_format templates. It returns nil
+// if none are set.
+func compileStatusFormat(f config.StatusFormat) (*statusLayout, error) {
+	if len(f.Live)+len(f.Scheduled)+len(f.Finished) == 0 {
+		return nil, nil
+	}
+	l := &statusLayout{}
+	var err error
+	if l.live, err = compileBlocks("live", f.Live); err != nil {
+		return nil, err
+	}
+	if l.scheduled, err = compileBlocks("scheduled", f.Scheduled); err != nil {
+		return nil, err
+	}
+	if l.finished, err = compileBlocks("finished", f.Finished); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func compileBlocks(state string, blocks []config.StatusBlock) ([]statusBlock, error) {
+	var out []statusBlock
+	for i, b := range blocks {
+		name := fmt.Sprintf("status_format.%s[%d]", state, i)
+		tmpl, err := template.New(name).Funcs(statusFuncs(time.Now())).Parse(b.Text)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, statusBlock{tmpl, b.Priority, b.Static})
+	}
+	return out, nil
+}
+
+// render builds the segments for r from the templates for its state; last
+// is the result scheduled templates may show. It reports false if there
+// are no templates for that state, so the caller uses the built-in
+// layout. Blocks that fail or render blank are left out.
+func (l *statusLayout) render(r *statusResult, last *series.Result, drivers []int, multiSeries bool, now time.Time) ([]segment, bool) {
+	if l == nil {
+		return nil, false
+	}
+	data := statusData{
+		Series:  r.name,
+		Drivers: drivers,
+		Weather: strings.TrimPrefix(r.weather, " | "),
+		Now:     now,
+	}
+	var blocks []statusBlock
+	switch {
+	case r.live != nil && r.live.Finished:
+		blocks = l.finished
+	case r.live != nil:
+		blocks = l.live
+	default:
+		blocks = l.scheduled
+	}
+	if len(blocks) == 0 {
+		return nil, false
+	}
+	if r.live != nil {
+		data.Live = r.live
+		data.Favorites = favoritePositions(r.live.Positions, drivers)
+		if data.Series == "" {
+			data.Series = r.live.ShortName
+		}
+	} else {
+		data.Race = r.next
+		if data.Series == "" {
+			data.Series = r.next.ShortName
+		}
+		if last != nil {
+			data.Last = last
+			data.LastFavorites = favoritePositions(last.Positions, drivers)
+		}
+	}
+	if multiSeries {
+		data.Prefix = data.Series + ": "
+	}
+
+	var segs []segment
+	for _, b := range blocks {
+		var sb strings.Builder
+		if err := b.tmpl.Funcs(statusFuncs(now)).Execute(&sb, data); err != nil {
+			slog.Warn("status_format", "err", err)
+			continue
+		}
+		if strings.TrimSpace(sb.String()) == "" {
+			continue
+		}
+		segs = append(segs, segment{sb.String(), b.priority, b.static})
+	}
+	return segs, true
+}
+
+// favoritePositions returns the favourite drivers found in positions, in
+// config order.
+func favoritePositions(positions []series.Driver, drivers []int) []series.Driver {
+	var favs []series.Driver
+	for _, d := range drivers {
+		num := strconv.Itoa(d)
+		for _, p := range positions {
+			if p.Number == num {
+				favs = append(favs, p)
+				break
+			}
+		}
+	}
+	return favs
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/series"
+	"github.com/jfmyers/tmux-raceday/internal/ui"
+)
+
+// tmuxTheme colours status segments with tmux #[...] styles. A nil theme
+// leaves them as plain text.
+type tmuxTheme struct {
+	config.StatusStyle
+}
+
+// newTmuxTheme returns the theme for st, or nil if tmux styling is off.
+func newTmuxTheme(st config.StatusStyle) *tmuxTheme {
+	if !st.Tmux {
+		return nil
+	}
+	return &tmuxTheme{st}
+}
+
+// Flag kinds shared by both series' flag names.
+const (
+	flagGreen   = "green"
+	flagCaution = "caution"
+	flagRed     = "red-flag"
+)
+
+// flagKind classifies the flag shown in state, or returns "" if it is
+// finished or the flag is unknown.
+func flagKind(state *series.LiveState) string {
+	if state.Finished {
+		return ""
+	}
+	switch strings.ToUpper(state.FlagName) {
+	case "CAUTION", "YELLOW", "SAFETY CAR", "VSC":
+		return flagCaution
+	case "RED":
+		return flagRed
+	case "GREEN":
+		return flagGreen
+	}
+	return ""
+}
+
+// flagStyle returns the style for the flag shown in state.
+func (t *tmuxTheme) flagStyle(state *series.LiveState) string {
+	return kindStyle(t.StatusStyle, flagKind(state))
+}
+
+// kindStyle returns the configured style for a flag kind.
+func kindStyle(st config.StatusStyle, kind string) string {
+	switch kind {
+	case flagCaution:
+		return st.Caution
+	case flagRed:
+		return st.RedFlag
+	case flagGreen:
+		return st.Green
+	}
+	return ""
+}
+
+// style colours the header by flag state and, if favorites is set, the
+// favourite driver segments (priority 1 in the built-in layouts).
+func (t *tmuxTheme) style(segs []segment, r *statusResult, favorites bool) {
+	if t == nil || len(segs) == 0 {
+		return
+	}
+	if r.live != nil {
+		segs[0].text = styleSegment(t.flagStyle(r.live), segs[0].text)
+	}
+	if !favorites {
+		return
+	}
+	for i := range segs {
+		if segs[i].priority == 1 {
+			segs[i].text = styleSegment(t.Favorite, segs[i].text)
+		}
+	}
+}
+
+// stale colours the stale-data indicator.
+func (t *tmuxTheme) stale(text string) string {
+	if t == nil {
+		return text
+	}
+	return styleSegment(t.Stale, text)
+}
+
+// styleSegment wraps text in style, leaving a leading " | " separator or
+// space outside the styled span.
+func styleSegment(style, text string) string {
+	for _, lead := range []string{" | ", " "} {
+		if rest, ok := strings.CutPrefix(text, lead); ok {
+			return lead + ui.Styled(style, rest)
+		}
+	}
+	return ui.Styled(style, text)
+}
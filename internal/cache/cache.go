@@ -1,11 +1,22 @@
 package cache
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"time"
 )
 
+// lockWait bounds how long Refresh waits for another process to finish
+// refreshing the same key before fetching on its own.
+const lockWait = 15 * time.Second
+
+var errLockTimeout = errors.New("cache: timed out waiting for lock")
+
 // Cache provides file-based caching with mtime-based TTL.
 type Cache struct {
 	dir string
@@ -20,29 +31,83 @@ func New(subdir string) *Cache {
 	return &Cache{dir: filepath.Join(base, "raceday", subdir)}
 }
 
+// NewFor creates a Cache for data fetched from baseURL. Data from
+// defaultURL, the provider itself, is stored under subdir as with New.
+// Data from anywhere else, such as a mirror or fixture server, is stored
+// under mirrors/{hash of baseURL}/{subdir}, so it never mixes with the
+// provider's or another server's.
+func NewFor(subdir, baseURL, defaultURL string) *Cache {
+	if baseURL == defaultURL {
+		return New(subdir)
+	}
+	sum := sha256.Sum256([]byte(baseURL))
+	return New(filepath.Join("mirrors", hex.EncodeToString(sum[:4]), subdir))
+}
+
+// NewDir creates a Cache that stores files directly under dir.
+func NewDir(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
 // Read returns cached data if it exists and is younger than ttl.
 func (c *Cache) Read(key string, ttl time.Duration) ([]byte, bool) {
 	path := filepath.Join(c.dir, key)
 	info, err := os.Stat(path)
 	if err != nil {
+		slog.Debug("cache", "key", key, "result", "miss", "ttl", ttl)
 		return nil, false
 	}
-	if time.Since(info.ModTime()) > ttl {
+	age := time.Since(info.ModTime())
+	if age > ttl {
+		slog.Debug("cache", "key", key, "result", "expired", "age", age, "ttl", ttl)
 		return nil, false
 	}
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, false
 	}
+	slog.Debug("cache", "key", key, "result", "hit", "age", age, "ttl", ttl)
 	return data, true
 }
 
-// Write stores data to the cache file.
+// Write stores data to the cache file. It is written atomically, as by
+// WriteFile.
 func (c *Cache) Write(key string, data []byte) error {
-	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+	return WriteFile(filepath.Join(c.dir, key), data)
+}
+
+// WriteFile writes data to path, creating its directory if needed. The
+// data is written to a temporary file and renamed into place, so
+// concurrent readers in other processes see either the previous contents
+// or the new ones, never a partial file.
+func WriteFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Chmod(tmpName, 0o644); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
 		return err
 	}
-	return os.WriteFile(filepath.Join(c.dir, key), data, 0o644)
+	return nil
 }
 
 // ReadStale returns cached data regardless of TTL, as long as the file exists.
@@ -61,6 +126,118 @@ func (c *Cache) ReadStale(key string, ttl time.Duration) ([]byte, bool) {
 	return data, stale
 }
 
+// Refresh fetches fresh data for key and writes it to the cache while
+// holding an advisory lock on the key. When several processes refresh the
+// same key at once, only the first one calls fetch; the others wait for it
+// and reuse the file it wrote. Data already younger than ttl is returned
+// without fetching.
+//
+// If the lock cannot be acquired within lockWait, Refresh fetches anyway
+// rather than stalling the caller. If ctx is done first, Refresh returns
+// its error so the caller can fall back to stale data.
+func (c *Cache) Refresh(ctx context.Context, key string, ttl time.Duration, fetch func() ([]byte, error)) ([]byte, error) {
+	start := time.Now()
+
+	unlock, err := c.lock(ctx, key, lockWait)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err == nil {
+		defer unlock()
+	}
+
+	path := filepath.Join(c.dir, key)
+	if info, err := os.Stat(path); err == nil {
+		mod := info.ModTime()
+		// Written while we waited for the lock, or still fresh.
+		if !mod.Before(start) || (ttl > 0 && time.Since(mod) <= ttl) {
+			if data, err := os.ReadFile(path); err == nil {
+				slog.Debug("cache", "key", key, "result", "hit", "age", time.Since(mod), "ttl", ttl)
+				return data, nil
+			}
+		}
+	}
+
+	data, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	_ = c.Write(key, data)
+	return data, nil
+}
+
+// Update replaces key's data with what fn returns for it (nil if there is
+// none), holding key's lock meanwhile so updates from several processes
+// aren't lost. If the lock can't be had within lockWait, it updates
+// anyway. If ctx is done first, nothing is written.
+func (c *Cache) Update(ctx context.Context, key string, fn func(data []byte) ([]byte, error)) error {
+	unlock, err := c.lock(ctx, key, lockWait)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err == nil {
+		defer unlock()
+	}
+	data, err := os.ReadFile(filepath.Join(c.dir, key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if data, err = fn(data); err != nil {
+		return err
+	}
+	return c.Write(key, data)
+}
+
+// lock acquires an exclusive advisory lock for key, polling until wait
+// elapses or ctx is done. The returned func releases the lock.
+func (c *Cache) lock(ctx context.Context, key string, wait time.Duration) (func(), error) {
+	return lockFile(ctx, filepath.Join(c.dir, "."+key+".lock"), time.Now().Add(wait))
+}
+
+// Lock acquires an exclusive advisory lock guarding path, for state that
+// status processes read, change and write back. It waits until ctx is
+// done. The returned func releases the lock; if the process dies holding
+// it, the kernel releases it.
+func Lock(ctx context.Context, path string) (func(), error) {
+	return lockFile(ctx, path+".lock", time.Time{})
+}
+
+// lockFile takes an flock on name, polling until deadline, if not zero,
+// or until ctx is done.
+func lockFile(ctx context.Context, name string, deadline time.Time) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if ok {
+			return func() {
+				_ = unlockFile(f)
+				f.Close()
+			}, nil
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			f.Close()
+			return nil, errLockTimeout
+		}
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, ctx.Err()
+		case <-time.After(25 * time.Millisecond):
+		}
+	}
+}
+
 // Invalidate removes a cache entry.
 func (c *Cache) Invalidate(key string) {
 	_ = os.Remove(filepath.Join(c.dir, key))
@@ -73,8 +250,12 @@ var TimeNow = time.Now
 // the given event start time. Closer events get shorter (or zero) TTLs
 // so data stays fresh when it matters most.
 func TTLForProximity(eventStart time.Time) time.Duration {
-	now := TimeNow()
+	ttl := ttlForProximity(TimeNow(), eventStart)
+	slog.Debug("ttl", "event_start", eventStart, "ttl", ttl)
+	return ttl
+}
 
+func ttlForProximity(now, eventStart time.Time) time.Duration {
 	// Event in progress (within 6h of start) — no caching.
 	sinceStart := now.Sub(eventStart)
 	if sinceStart >= 0 && sinceStart < 6*time.Hour {
@@ -1,6 +1,14 @@
 package cache
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -101,3 +109,208 @@ func TestTTLForProximity(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteIsAtomicUnderConcurrentReaders(t *testing.T) {
+	c := &Cache{dir: t.TempDir()}
+	const key = "live_feed.json"
+
+	payloads := [][]byte{
+		[]byte(`{"lap_number":1,"vehicles":[` + strings.Repeat(`{"vehicle_number":"1"},`, 500) + `{}]}`),
+		[]byte(`{"lap_number":2,"vehicles":[` + strings.Repeat(`{"vehicle_number":"22"},`, 2000) + `{}]}`),
+	}
+	if err := c.Write(key, payloads[0]); err != nil {
+		t.Fatalf("seed Write: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if err := c.Write(key, payloads[(w+i)%len(payloads)]); err != nil {
+					t.Errorf("Write: %v", err)
+					return
+				}
+			}
+		}(w)
+	}
+
+	for r := 0; r < 2000; r++ {
+		data, ok := c.ReadStale(key, time.Minute)
+		if !ok && data == nil {
+			t.Fatal("entry disappeared during concurrent writes")
+		}
+		if !json.Valid(data) {
+			close(stop)
+			wg.Wait()
+			t.Fatalf("read a partial file (%d bytes)", len(data))
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".tmp") {
+			t.Errorf("temp file left behind: %s", e.Name())
+		}
+	}
+}
+
+func TestRefreshFetchesOnce(t *testing.T) {
+	c := &Cache{dir: t.TempDir()}
+
+	var calls atomic.Int32
+	fetch := func() ([]byte, error) {
+		calls.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		return []byte(`{"ok":true}`), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := c.Refresh(context.Background(), "schedule_2026.json", 0, fetch)
+			if err != nil {
+				t.Errorf("Refresh: %v", err)
+				return
+			}
+			if string(data) != `{"ok":true}` {
+				t.Errorf("Refresh returned %q", data)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fetch called %d times, want 1", n)
+	}
+}
+
+func TestRefreshReusesFreshData(t *testing.T) {
+	c := &Cache{dir: t.TempDir()}
+	if err := c.Write("k.json", []byte("cached")); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := c.Refresh(context.Background(), "k.json", time.Minute, func() ([]byte, error) {
+		t.Error("fetch should not be called for fresh data")
+		return nil, nil
+	})
+	if err != nil || string(data) != "cached" {
+		t.Errorf("Refresh = %q, %v", data, err)
+	}
+}
+
+func TestRefreshPropagatesFetchError(t *testing.T) {
+	c := &Cache{dir: t.TempDir()}
+	if err := c.Write("k.json", []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+	// Backdate so it is neither fresh nor written during the refresh.
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(c.dir, "k.json"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := c.Refresh(context.Background(), "k.json", time.Minute, func() ([]byte, error) {
+		return nil, errors.New("boom")
+	})
+	if err == nil {
+		t.Fatal("expected fetch error")
+	}
+	if data, stale := c.ReadStale("k.json", time.Minute); string(data) != "old" || !stale {
+		t.Errorf("stale entry = %q (stale=%v), want old entry kept", data, stale)
+	}
+}
+
+func TestRefreshStopsWaitingWhenContextDone(t *testing.T) {
+	c := &Cache{dir: t.TempDir()}
+
+	unlock, err := c.lock(context.Background(), "k.json", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = c.Refresh(ctx, "k.json", 0, func() ([]byte, error) {
+		t.Error("fetch should not run after the context expired")
+		return nil, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestLockWaitsForHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	unlock, err := Lock(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := Lock(ctx, path); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("second Lock while held: err = %v, want DeadlineExceeded", err)
+	}
+
+	unlock()
+	unlock, err = Lock(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Lock after release: %v", err)
+	}
+	unlock()
+}
+
+func TestFallbackMarksTracker(t *testing.T) {
+	c := &Cache{dir: t.TempDir()}
+	if err := c.Write("a.json", []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Write("b.json", []byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(filepath.Join(c.dir, "b.json"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, tr := Track(context.Background())
+	if stale, _ := tr.Stale(); stale {
+		t.Fatal("new tracker should not be stale")
+	}
+	if got := c.Fallback(ctx, "a.json"); string(got) != "a" {
+		t.Errorf("Fallback(a) = %q", got)
+	}
+	if got := c.Fallback(ctx, "b.json"); string(got) != "b" {
+		t.Errorf("Fallback(b) = %q", got)
+	}
+	if got := c.Fallback(ctx, "missing.json"); got != nil {
+		t.Errorf("Fallback(missing) = %q, want nil", got)
+	}
+
+	stale, since := tr.Stale()
+	if !stale || !since.Equal(old) {
+		t.Errorf("Stale() = %v, %v; want true, %v", stale, since, old)
+	}
+
+	// Without a tracker Fallback still works.
+	if got := c.Fallback(context.Background(), "a.json"); string(got) != "a" {
+		t.Errorf("untracked Fallback = %q", got)
+	}
+}
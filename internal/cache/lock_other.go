@@ -0,0 +1,11 @@
+//go:build !unix
+
+package cache
+
+import "os"
+
+// tryLockFile is a no-op on platforms without flock. Writes are still
+// atomic; concurrent refreshes simply fetch independently.
+func tryLockFile(f *os.File) (bool, error) { return true, nil }
+
+func unlockFile(f *os.File) error { return nil }
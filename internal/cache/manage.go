@@ -0,0 +1,241 @@
+package cache
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// pruneMarker records when AutoPrune last ran.
+const pruneMarker = ".last_prune"
+
+// Entry describes one cached file.
+type Entry struct {
+	Key     string // path relative to the cache directory, e.g. "f1/positions_9158.json"
+	Size    int64
+	ModTime time.Time
+}
+
+// Kind groups entries by what they hold, dropping the per-year, per-session
+// or per-coordinate suffix: "f1/positions_9158.json" → "f1/positions".
+func (e Entry) Kind() string {
+	dir, name := filepath.Split(e.Key)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	parts := strings.Split(name, "_")
+	for len(parts) > 1 {
+		last := parts[len(parts)-1]
+		if last == "" || !(last[0] == '-' || (last[0] >= '0' && last[0] <= '9')) {
+			break
+		}
+		parts = parts[:len(parts)-1]
+	}
+	return filepath.ToSlash(dir) + strings.Join(parts, "_")
+}
+
+// Stats summarizes the contents of a cache directory.
+type Stats struct {
+	Files  int
+	Bytes  int64
+	Oldest time.Time
+	Newest time.Time
+	ByKind map[string]KindStats
+}
+
+// KindStats totals the entries of one Kind.
+type KindStats struct {
+	Files int
+	Bytes int64
+}
+
+// Policy bounds cache growth. Zero values disable the respective limit.
+type Policy struct {
+	MaxAge  time.Duration
+	MaxSize int64 // bytes
+}
+
+// Dir returns the directory backing the cache.
+func (c *Cache) Dir() string { return c.dir }
+
+// Check verifies the cache directory exists (creating it if needed) and
+// is writable.
+func (c *Cache) Check() error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(c.dir, ".check.*.tmp")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// List returns every cached file under the cache directory, including
+// subdirectories, oldest first. Lock and temporary files are skipped.
+func (c *Cache) List() ([]Entry, error) {
+	var entries []Entry
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(c.dir, path)
+		if err != nil {
+			return nil
+		}
+		entries = append(entries, Entry{
+			Key:     filepath.ToSlash(rel),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime.Before(entries[j].ModTime)
+	})
+	return entries, err
+}
+
+// Stats returns totals for the cache directory.
+func (c *Cache) Stats() (Stats, error) {
+	entries, err := c.List()
+	st := Stats{ByKind: make(map[string]KindStats)}
+	for _, e := range entries {
+		st.Files++
+		st.Bytes += e.Size
+		if st.Oldest.IsZero() || e.ModTime.Before(st.Oldest) {
+			st.Oldest = e.ModTime
+		}
+		if e.ModTime.After(st.Newest) {
+			st.Newest = e.ModTime
+		}
+		k := st.ByKind[e.Kind()]
+		k.Files++
+		k.Bytes += e.Size
+		st.ByKind[e.Kind()] = k
+	}
+	return st, err
+}
+
+// Clear removes every cached file and returns what was removed.
+func (c *Cache) Clear() ([]Entry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	var removed []Entry
+	for _, e := range entries {
+		if os.Remove(filepath.Join(c.dir, filepath.FromSlash(e.Key))) == nil {
+			removed = append(removed, e)
+		}
+	}
+	c.removeLeftovers(0)
+	return removed, nil
+}
+
+// Prune removes entries older than p.MaxAge, then the oldest remaining
+// entries until the total size fits within p.MaxSize. It returns the
+// removed entries.
+func (c *Cache) Prune(p Policy) ([]Entry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	now := TimeNow()
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+
+	var removed []Entry
+	for _, e := range entries {
+		expired := p.MaxAge > 0 && now.Sub(e.ModTime) > p.MaxAge
+		oversize := p.MaxSize > 0 && total > p.MaxSize
+		if !expired && !oversize {
+			continue
+		}
+		if os.Remove(filepath.Join(c.dir, filepath.FromSlash(e.Key))) != nil {
+			continue
+		}
+		total -= e.Size
+		removed = append(removed, e)
+	}
+
+	c.removeLeftovers(lockWait)
+	return removed, nil
+}
+
+// AutoPrune runs Prune at most once per interval, across all processes
+// sharing the cache directory. Errors are ignored; pruning is best effort.
+func (c *Cache) AutoPrune(p Policy, interval time.Duration) {
+	if p.MaxAge <= 0 && p.MaxSize <= 0 {
+		return
+	}
+	if _, ok := c.Read(pruneMarker, interval); ok {
+		return
+	}
+	unlock, err := c.lock(context.Background(), pruneMarker, 0)
+	if err != nil {
+		return
+	}
+	defer unlock()
+	_ = c.Write(pruneMarker, []byte(TimeNow().UTC().Format(time.RFC3339)))
+	_, _ = c.Prune(p)
+}
+
+// removeLeftovers deletes temp files abandoned by interrupted writes and
+// lock files whose entry no longer exists. Files younger than minAge are
+// left alone since a writer may still be using them.
+func (c *Cache) removeLeftovers(minAge time.Duration) {
+	now := TimeNow()
+	_ = filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || now.Sub(info.ModTime()) < minAge {
+			return nil
+		}
+		name := d.Name()
+		switch {
+		case strings.HasSuffix(name, ".tmp"):
+			_ = os.Remove(path)
+		case strings.HasSuffix(name, ".lock"):
+			key := strings.TrimSuffix(strings.TrimPrefix(name, "."), ".lock")
+			if _, err := os.Stat(filepath.Join(filepath.Dir(path), key)); os.IsNotExist(err) {
+				removeLockIfFree(path)
+			}
+		}
+		return nil
+	})
+}
+
+// removeLockIfFree deletes a lock file only while holding it, so a
+// process mid-refresh keeps its lock.
+func removeLockIfFree(path string) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if ok, err := tryLockFile(f); err != nil || !ok {
+		return
+	}
+	_ = os.Remove(path)
+	_ = unlockFile(f)
+}
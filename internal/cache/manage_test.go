@@ -0,0 +1,217 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// seed writes key with size bytes and backdates it by age.
+func seed(t *testing.T, c *Cache, key string, size int, age time.Duration) {
+	t.Helper()
+	path := filepath.Join(c.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mod := time.Now().Add(-age)
+	if err := os.Chtimes(path, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func keys(entries []Entry) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.Key)
+	}
+	return out
+}
+
+func TestEntryKind(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"live_feed.json", "live_feed"},
+		{"schedule_2026.json", "schedule"},
+		{"weather_29.1872_-81.0715.json", "weather"},
+		{"f1/positions_9158.json", "f1/positions"},
+		{"f1/race_control_9158.json", "f1/race_control"},
+		{"f1/latest_session.json", "f1/latest_session"},
+		{"live-points.json", "live-points"},
+	}
+	for _, tt := range tests {
+		if got := (Entry{Key: tt.key}).Kind(); got != tt.want {
+			t.Errorf("Kind(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestListSkipsInternalFiles(t *testing.T) {
+	c := &Cache{dir: t.TempDir()}
+	seed(t, c, "schedule_2026.json", 10, 2*time.Hour)
+	seed(t, c, "f1/positions_1.json", 10, time.Hour)
+	seed(t, c, ".schedule_2026.json.lock", 0, 0)
+	seed(t, c, ".live_feed.json.123.tmp", 5, 0)
+
+	entries, err := c.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(keys(entries), ",")
+	if got != "schedule_2026.json,f1/positions_1.json" {
+		t.Errorf("List = %s", got)
+	}
+}
+
+func TestListMissingDir(t *testing.T) {
+	c := &Cache{dir: filepath.Join(t.TempDir(), "missing")}
+	entries, err := c.List()
+	if err != nil || len(entries) != 0 {
+		t.Errorf("List on missing dir = %v, %v", entries, err)
+	}
+}
+
+func TestStats(t *testing.T) {
+	c := &Cache{dir: t.TempDir()}
+	seed(t, c, "f1/positions_1.json", 300, time.Hour)
+	seed(t, c, "f1/positions_2.json", 200, time.Minute)
+	seed(t, c, "schedule_2026.json", 50, time.Minute)
+
+	st, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Files != 3 || st.Bytes != 550 {
+		t.Errorf("Files=%d Bytes=%d, want 3/550", st.Files, st.Bytes)
+	}
+	if k := st.ByKind["f1/positions"]; k.Files != 2 || k.Bytes != 500 {
+		t.Errorf("f1/positions = %+v", k)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	t.Run("by age", func(t *testing.T) {
+		c := &Cache{dir: t.TempDir()}
+		seed(t, c, "schedule_2024.json", 10, 60*24*time.Hour)
+		seed(t, c, "schedule_2026.json", 10, time.Hour)
+
+		removed, err := c.Prune(Policy{MaxAge: 30 * 24 * time.Hour})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(keys(removed), ","); got != "schedule_2024.json" {
+			t.Errorf("removed %s", got)
+		}
+	})
+
+	t.Run("by size removes oldest first", func(t *testing.T) {
+		c := &Cache{dir: t.TempDir()}
+		seed(t, c, "f1/positions_1.json", 400, 3*time.Hour)
+		seed(t, c, "f1/positions_2.json", 400, 2*time.Hour)
+		seed(t, c, "live_feed.json", 100, time.Minute)
+
+		removed, err := c.Prune(Policy{MaxSize: 600})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(keys(removed), ","); got != "f1/positions_1.json" {
+			t.Errorf("removed %s", got)
+		}
+		st, _ := c.Stats()
+		if st.Bytes != 500 {
+			t.Errorf("remaining bytes = %d, want 500", st.Bytes)
+		}
+	})
+
+	t.Run("zero policy keeps everything", func(t *testing.T) {
+		c := &Cache{dir: t.TempDir()}
+		seed(t, c, "schedule_2020.json", 10, 365*24*time.Hour)
+		removed, _ := c.Prune(Policy{})
+		if len(removed) != 0 {
+			t.Errorf("removed %v", keys(removed))
+		}
+	})
+
+	t.Run("drops orphaned lock and temp files", func(t *testing.T) {
+		c := &Cache{dir: t.TempDir()}
+		seed(t, c, "live_feed.json", 10, 0)
+		seed(t, c, ".live_feed.json.lock", 0, time.Hour)
+		seed(t, c, ".schedule_2024.json.lock", 0, time.Hour)
+		seed(t, c, ".live_feed.json.42.tmp", 10, time.Hour)
+
+		if _, err := c.Prune(Policy{MaxAge: time.Hour}); err != nil {
+			t.Fatal(err)
+		}
+		for name, want := range map[string]bool{
+			".live_feed.json.lock":     true,
+			".schedule_2024.json.lock": false,
+			".live_feed.json.42.tmp":   false,
+		} {
+			_, err := os.Stat(filepath.Join(c.dir, name))
+			if exists := err == nil; exists != want {
+				t.Errorf("%s exists=%v, want %v", name, exists, want)
+			}
+		}
+	})
+}
+
+func TestClear(t *testing.T) {
+	c := &Cache{dir: t.TempDir()}
+	seed(t, c, "live_feed.json", 10, 0)
+	seed(t, c, "f1/drivers_1.json", 10, 0)
+
+	removed, err := c.Clear()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("removed %d entries, want 2", len(removed))
+	}
+	if entries, _ := c.List(); len(entries) != 0 {
+		t.Errorf("entries left: %v", keys(entries))
+	}
+}
+
+func TestAutoPruneRunsOncePerInterval(t *testing.T) {
+	c := &Cache{dir: t.TempDir()}
+	p := Policy{MaxAge: time.Hour}
+
+	seed(t, c, "old_1.json", 10, 2*time.Hour)
+	c.AutoPrune(p, time.Hour)
+	if entries, _ := c.List(); len(entries) != 0 {
+		t.Fatalf("first AutoPrune left %v", keys(entries))
+	}
+
+	seed(t, c, "old_2.json", 10, 2*time.Hour)
+	c.AutoPrune(p, time.Hour)
+	if entries, _ := c.List(); len(entries) != 1 {
+		t.Errorf("second AutoPrune within interval should be skipped, left %v", keys(entries))
+	}
+}
+
+func TestCheck(t *testing.T) {
+	c := &Cache{dir: filepath.Join(t.TempDir(), "new")}
+	if err := c.Check(); err != nil {
+		t.Fatalf("Check on missing dir: %v", err)
+	}
+	if entries, _ := os.ReadDir(c.dir); len(entries) != 0 {
+		t.Errorf("Check left files behind: %v", entries)
+	}
+
+	if os.Getuid() == 0 {
+		t.Skip("root ignores directory permissions")
+	}
+	if err := os.Chmod(c.dir, 0o555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(c.dir, 0o755) })
+	if err := c.Check(); err == nil {
+		t.Error("Check on read-only dir succeeded")
+	}
+}
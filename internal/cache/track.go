@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type trackerKey struct{}
+
+// Tracker records whether a fetch had to fall back to stale cached data,
+// so callers can flag what they display. Attach one to a context with
+// Track; it is safe for concurrent use.
+type Tracker struct {
+	mu     sync.Mutex
+	stale  bool
+	oldest time.Time
+}
+
+// Track returns a context carrying a new Tracker.
+func Track(ctx context.Context) (context.Context, *Tracker) {
+	t := &Tracker{}
+	return context.WithValue(ctx, trackerKey{}, t), t
+}
+
+// Stale reports whether any stale data was served and, if so, the
+// modification time of the oldest entry used.
+func (t *Tracker) Stale() (bool, time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stale, t.oldest
+}
+
+func (t *Tracker) markStale(mod time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.stale || mod.Before(t.oldest) {
+		t.oldest = mod
+	}
+	t.stale = true
+}
+
+// Fallback returns the cached data for key regardless of its age, for use
+// after a refresh failed. The entry is recorded as stale on the Tracker
+// in ctx, if any. It returns nil when nothing is cached.
+func (c *Cache) Fallback(ctx context.Context, key string) []byte {
+	path := filepath.Join(c.dir, key)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	slog.Debug("cache", "key", key, "result", "stale", "age", time.Since(info.ModTime()))
+	if t, ok := ctx.Value(trackerKey{}).(*Tracker); ok {
+		t.markStale(info.ModTime())
+	}
+	return data
+}
@@ -1,9 +1,16 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -25,6 +32,10 @@ func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
 	return nil
 }
 
+func (d Duration) MarshalYAML() (any, error) {
+	return time.Duration(d).String(), nil
+}
+
 // SeriesList holds configured series names (e.g. "nascar", "f1").
 // Backward-compatible: unmarshals from int (old format) or string or []string.
 type SeriesList []string
@@ -67,22 +78,166 @@ func (d *DriverMap) UnmarshalYAML(value *yaml.Node) error {
 }
 
 type Config struct {
-	Drivers          DriverMap  `yaml:"drivers"`
-	Series           SeriesList `yaml:"series"`
-	Theme            string     `yaml:"theme"`
-	Weather          bool   `yaml:"weather"`
-	Notify           Notify `yaml:"notify"`
-	StatusWidth      int    `yaml:"status_width"`
-	Marquee          bool   `yaml:"marquee"`
-	MarqueeSpeed     int    `yaml:"marquee_speed"`
-	MarqueeSeparator string   `yaml:"marquee_separator"`
-	WeatherWindow    Duration `yaml:"weather_window"`
+	Drivers          DriverMap    `yaml:"drivers"`
+	Series           SeriesList   `yaml:"series"`
+	Theme            string       `yaml:"theme"`
+	Weather          bool         `yaml:"weather"`
+	Notify           Notify       `yaml:"notify"`
+	StatusWidth      int          `yaml:"status_width"`
+	StatusGaps       bool         `yaml:"status_gaps"`     // gaps around favourites in the status line
+	LastResult       Duration     `yaml:"last_result"`     // how long after a race its result is shown; 0 hides it
+	BroadcastDelay   Duration     `yaml:"broadcast_delay"` // show live data this late, to match TV; the TUI can change it
+	Marquee          bool         `yaml:"marquee"`
+	MarqueeSpeed     int          `yaml:"marquee_speed"`
+	MarqueeSeparator string       `yaml:"marquee_separator"`
+	WeatherWindow    Duration     `yaml:"weather_window"`
+	StatusTimeout    Duration     `yaml:"status_timeout"`
+	ScheduleHorizon  Duration     `yaml:"schedule_horizon"`
+	Offline          bool         `yaml:"offline"`
+	Cache            Cache        `yaml:"cache"`
+	HTTP             HTTP         `yaml:"http"`
+	Endpoints        Endpoints    `yaml:"endpoints,omitempty"`
+	LogFile          string       `yaml:"log_file,omitempty"` // structured log; --debug defaults it
+	StatusFormat     StatusFormat `yaml:"status_format,omitempty"`
+	StatusStyle      StatusStyle  `yaml:"status_style"`
+	SpoilerFree      SpoilerFree  `yaml:"spoiler_free"`
 }
 
+// SpoilerFree hides positions and results of races until they are marked
+// watched with `raceday spoilers watched`.
+type SpoilerFree struct {
+	All   bool     `yaml:"all"`   // every race
+	Races []string `yaml:"races"` // races whose name contains one of these, every season
+}
+
+// StatusStyle colours the status line with tmux #[...] style sequences.
+// Each colour is a tmux style such as "fg=black,bg=yellow"; an empty one
+// leaves that part unstyled.
+type StatusStyle struct {
+	Tmux     bool   `yaml:"tmux"` // same as --tmux-style
+	Green    string `yaml:"green"`
+	Caution  string `yaml:"caution"`
+	RedFlag  string `yaml:"red_flag"`
+	Favorite string `yaml:"favorite"`
+	Stale    string `yaml:"stale"`
+}
+
+// StatusFormat replaces the built-in status line layout for each state
+// with a list of text/template blocks. States left empty keep the
+// built-in layout.
+type StatusFormat struct {
+	Live      []StatusBlock `yaml:"live,omitempty"`
+	Scheduled []StatusBlock `yaml:"scheduled,omitempty"`
+	Finished  []StatusBlock `yaml:"finished,omitempty"`
+}
+
+// StatusBlock is one segment of a templated status line. Priority runs
+// from 0 (kept longest) to MaxPriority (dropped first) when the line is
+// too wide; static blocks stay pinned while the rest scroll in marquee
+// mode. Blocks that render empty are left out.
+type StatusBlock struct {
+	Text     string `yaml:"text"`
+	Priority int    `yaml:"priority"`
+	Static   bool   `yaml:"static"`
+}
+
+// MaxPriority is the lowest status segment priority.
+const MaxPriority = 3
+
+// Notify picks the race events raceday alerts about, in tmux and
+// optionally on the desktop.
 type Notify struct {
-	Cautions    bool `yaml:"cautions"`
-	LeadChanges bool `yaml:"lead_changes"`
+	Cautions    bool `yaml:"cautions"`     // cautions, restarts, red and white flags
+	LeadChanges bool `yaml:"lead_changes"` // a new leader
+	Pits        bool `yaml:"pits"`         // a favourite pits
+	Positions   bool `yaml:"positions"`    // a favourite gains or loses positions
+	Stages      bool `yaml:"stages"`       // a stage ends
+	Finish      bool `yaml:"finish"`       // the race ends
 	Desktop     bool `yaml:"desktop"`
+	// Reminders are how long before a session starts to send a reminder,
+	// e.g. [1h, 10m]; empty for none. ReminderSessions picks the sessions
+	// reminded of, matching names such as "race", "qualifying" or "sprint".
+	Reminders        []Duration `yaml:"reminders,omitempty"`
+	ReminderSessions []string   `yaml:"reminder_sessions,omitempty"`
+	// Rules are custom alerts, sent when their condition becomes true.
+	Rules []Rule `yaml:"rules,omitempty"`
+	// QuietHours hold alerts back overnight. Cooldowns space alerts of an
+	// event kind (or "all") out per sink; those in between are sent as
+	// one digest once the cooldown ends.
+	QuietHours QuietHours          `yaml:"quiet_hours"`
+	Cooldowns  map[string]Duration `yaml:"cooldowns,omitempty"`
+	// Sinks replace tmux (and the desktop) as where alerts go.
+	Sinks []Sink `yaml:"sinks,omitempty"`
+}
+
+// QuietHours is a daily time span, in local time, when alerts are not
+// sent, e.g. 23:00 to 07:00. Allow lists the event kinds sent anyway.
+type QuietHours struct {
+	Start string   `yaml:"start"` // HH:MM; empty for no quiet hours
+	End   string   `yaml:"end"`
+	Allow []string `yaml:"allow,omitempty"`
+}
+
+// Rule is a custom alert. When is a condition on the race and its
+// drivers, e.g. `driver.number == 24 and driver.position <= 5`.
+type Rule struct {
+	Name string `yaml:"name"`
+	When string `yaml:"when"`
+}
+
+// Sink is one place alerts are sent.
+type Sink struct {
+	Type     string   `yaml:"type"`               // tmux, desktop, webhook, ntfy, gotify or command
+	URL      string   `yaml:"url,omitempty"`      // webhook, ntfy topic or gotify server
+	Format   string   `yaml:"format,omitempty"`   // webhook body: json (default), slack or discord
+	Template string   `yaml:"template,omitempty"` // webhook body as a Go template, instead of format
+	Token    string   `yaml:"token,omitempty"`    // ntfy access token or gotify app token
+	Command  string   `yaml:"command,omitempty"`  // run by sh with the event in RACEDAY_* variables
+	Events   []string `yaml:"events,omitempty"`   // event kinds sent here; empty for the notify switches
+}
+
+// Cache bounds the on-disk cache. Zero values disable the respective limit.
+type Cache struct {
+	MaxAge    Duration `yaml:"max_age"`
+	MaxSizeMB int      `yaml:"max_size_mb"`
+}
+
+// HTTP tunes the shared fetch layer used by every data provider.
+type HTTP struct {
+	Timeout          Duration            `yaml:"timeout"`
+	Retries          int                 `yaml:"retries"`
+	BreakerThreshold int                 `yaml:"breaker_threshold"`
+	BreakerCooldown  Duration            `yaml:"breaker_cooldown"`
+	RateLimits       map[string]Duration `yaml:"rate_limits,omitempty"` // host → min interval
+}
+
+// Endpoints overrides provider base URLs, e.g. to point at an internal
+// mirror, a caching proxy or a local fixture server. Empty fields keep
+// the built-in URL. Each can also be set from the environment; see
+// endpointEnv.
+type Endpoints struct {
+	NASCAR    string `yaml:"nascar,omitempty"`     // default https://cf.nascar.com
+	OpenF1    string `yaml:"openf1,omitempty"`     // default https://api.openf1.org/v1
+	OpenMeteo string `yaml:"open_meteo,omitempty"` // default https://api.open-meteo.com/v1
+}
+
+// endpointEnv maps environment variables to the endpoint they override.
+// The environment wins over config.yaml.
+var endpointEnv = []struct {
+	name  string
+	field func(*Endpoints) *string
+}{
+	{"RACEDAY_NASCAR_URL", func(e *Endpoints) *string { return &e.NASCAR }},
+	{"RACEDAY_OPENF1_URL", func(e *Endpoints) *string { return &e.OpenF1 }},
+	{"RACEDAY_OPEN_METEO_URL", func(e *Endpoints) *string { return &e.OpenMeteo }},
+}
+
+func (e *Endpoints) applyEnv() {
+	for _, v := range endpointEnv {
+		if url := os.Getenv(v.name); url != "" {
+			*v.field(e) = url
+		}
+	}
 }
 
 func DefaultConfig() Config {
@@ -92,16 +247,45 @@ func DefaultConfig() Config {
 		Theme:            "default",
 		Weather:          true,
 		WeatherWindow:    Duration(2 * time.Hour),
+		StatusTimeout:    Duration(4 * time.Second),
+		ScheduleHorizon:  Duration(365 * 24 * time.Hour),
+		LastResult:       Duration(72 * time.Hour),
 		MarqueeSpeed:     2,
 		MarqueeSeparator: " • ",
 		Notify: Notify{
-			Cautions:    true,
+			// Alerts are opt-in: a popup can spoil a race watched on delay.
+			Cautions:    false,
 			LeadChanges: false,
+			Finish:      false,
 			Desktop:     false,
+
+			ReminderSessions: []string{"race"},
+		},
+		StatusStyle: StatusStyle{
+			Caution:  "fg=black,bg=yellow",
+			RedFlag:  "fg=white,bg=red,bold",
+			Favorite: "fg=cyan,bold",
+			Stale:    "fg=colour244",
+		},
+		Cache: Cache{
+			MaxAge:    Duration(30 * 24 * time.Hour),
+			MaxSizeMB: 100,
+		},
+		HTTP: HTTP{
+			Timeout:          Duration(10 * time.Second),
+			Retries:          2,
+			BreakerThreshold: 3,
+			BreakerCooldown:  Duration(2 * time.Minute),
 		},
 	}
 }
 
+// KnownSeries lists the series names accepted in the series list.
+var KnownSeries = []string{"nascar", "f1"}
+
+// Path returns the location of the config file.
+func Path() string { return configPath() }
+
 func configPath() string {
 	home := os.Getenv("HOME")
 	if home == "" {
@@ -110,20 +294,168 @@ func configPath() string {
 	return filepath.Join(home, ".config", "raceday", "config.yaml")
 }
 
+// StateDir returns $XDG_STATE_HOME/raceday, falling back to
+// ~/.local/state/raceday. It holds what raceday records across runs, such
+// as its log, kept apart from the cache so pruning never removes it.
+func StateDir() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return filepath.Join(os.TempDir(), "raceday")
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "raceday")
+}
+
 // Load reads config from ~/.config/raceday/config.yaml.
-// Returns default config if file doesn't exist.
+// Returns default config if file doesn't exist. Endpoint overrides
+// from the environment are applied on top.
 func Load() Config {
 	cfg := DefaultConfig()
 
-	data, err := os.ReadFile(configPath())
-	if err != nil {
-		return cfg
+	if data, err := os.ReadFile(configPath()); err == nil {
+		_ = yaml.Unmarshal(data, &cfg)
 	}
-
-	_ = yaml.Unmarshal(data, &cfg)
+	cfg.Endpoints.applyEnv()
 	return cfg
 }
 
+// Parse decodes config YAML on top of the defaults. Unlike Load it
+// reports syntax errors and unknown keys.
+func Parse(data []byte) (Config, error) {
+	cfg := DefaultConfig()
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// Validate reports settings that are out of range or name something
+// raceday doesn't know.
+func (c Config) Validate() []error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if len(c.Series) == 0 {
+		add("series: no series configured")
+	}
+	for _, name := range c.Series {
+		if !slices.Contains(KnownSeries, name) {
+			add("series: unknown series %q (want one of %s)", name, strings.Join(KnownSeries, ", "))
+		}
+	}
+	for name, nums := range c.Drivers {
+		if !slices.Contains(KnownSeries, name) {
+			add("drivers: unknown series %q", name)
+		}
+		for _, n := range nums {
+			if n <= 0 {
+				add("drivers.%s: invalid car number %d", name, n)
+			}
+		}
+	}
+
+	nonNegative := map[string]int{
+		"status_width":           c.StatusWidth,
+		"marquee_speed":          c.MarqueeSpeed,
+		"cache.max_size_mb":      c.Cache.MaxSizeMB,
+		"http.retries":           c.HTTP.Retries,
+		"http.breaker_threshold": c.HTTP.BreakerThreshold,
+	}
+	for _, key := range slices.Sorted(maps.Keys(nonNegative)) {
+		if nonNegative[key] < 0 {
+			add("%s: must not be negative (got %d)", key, nonNegative[key])
+		}
+	}
+	if c.Marquee && c.MarqueeSpeed == 0 {
+		add("marquee_speed: must be positive when marquee is on")
+	}
+
+	durations := map[string]Duration{
+		"weather_window":        c.WeatherWindow,
+		"status_timeout":        c.StatusTimeout,
+		"schedule_horizon":      c.ScheduleHorizon,
+		"last_result":           c.LastResult,
+		"broadcast_delay":       c.BroadcastDelay,
+		"cache.max_age":         c.Cache.MaxAge,
+		"http.timeout":          c.HTTP.Timeout,
+		"http.breaker_cooldown": c.HTTP.BreakerCooldown,
+	}
+	for host, d := range c.HTTP.RateLimits {
+		durations["http.rate_limits."+host] = d
+	}
+	for kind, d := range c.Notify.Cooldowns {
+		durations["notify.cooldowns."+kind] = d
+	}
+	for _, key := range slices.Sorted(maps.Keys(durations)) {
+		if durations[key] < 0 {
+			add("%s: must not be negative (got %s)", key, time.Duration(durations[key]))
+		}
+	}
+	if q := c.Notify.QuietHours; q.Start != "" || q.End != "" {
+		for _, f := range []struct{ key, value string }{{"start", q.Start}, {"end", q.End}} {
+			if _, err := time.Parse("15:04", f.value); err != nil {
+				add("notify.quiet_hours.%s: want a time like 23:00 (got %q)", f.key, f.value)
+			}
+		}
+	}
+	for i, d := range c.Notify.Reminders {
+		if d <= 0 {
+			add("notify.reminders[%d]: must be positive (got %s)", i, time.Duration(d))
+		}
+	}
+
+	for _, f := range []struct {
+		state  string
+		blocks []StatusBlock
+	}{
+		{"live", c.StatusFormat.Live},
+		{"scheduled", c.StatusFormat.Scheduled},
+		{"finished", c.StatusFormat.Finished},
+	} {
+		for i, b := range f.blocks {
+			if b.Priority < 0 || b.Priority > MaxPriority {
+				add("status_format.%s[%d]: priority must be 0-%d (got %d)", f.state, i, MaxPriority, b.Priority)
+			}
+		}
+	}
+
+	for _, st := range []struct{ key, style string }{
+		{"green", c.StatusStyle.Green},
+		{"caution", c.StatusStyle.Caution},
+		{"red_flag", c.StatusStyle.RedFlag},
+		{"favorite", c.StatusStyle.Favorite},
+		{"stale", c.StatusStyle.Stale},
+	} {
+		if strings.ContainsAny(st.style, "#[]") {
+			add("status_style.%s: %q should be a bare tmux style like \"fg=black,bg=yellow\"", st.key, st.style)
+		}
+	}
+
+	endpoints := map[string]string{
+		"endpoints.nascar":     c.Endpoints.NASCAR,
+		"endpoints.openf1":     c.Endpoints.OpenF1,
+		"endpoints.open_meteo": c.Endpoints.OpenMeteo,
+	}
+	for _, key := range slices.Sorted(maps.Keys(endpoints)) {
+		raw := endpoints[key]
+		if raw == "" {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("%s: %q is not an http(s) URL", key, raw)
+		}
+	}
+	return errs
+}
+
 // Save writes the config to disk, creating directories as needed.
 func Save(cfg Config) error {
 	path := configPath()
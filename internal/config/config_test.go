@@ -53,3 +53,144 @@ func TestWeatherWindowFromYAML(t *testing.T) {
 		t.Errorf("WeatherWindow = %v, want 45m", time.Duration(cfg.WeatherWindow))
 	}
 }
+
+func TestDefaultConfigRoundTrip(t *testing.T) {
+	data, err := yaml.Marshal(DefaultConfig())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("unmarshal saved config: %v\n%s", err, data)
+	}
+	if cfg.WeatherWindow != DefaultConfig().WeatherWindow {
+		t.Errorf("WeatherWindow = %v after round trip", time.Duration(cfg.WeatherWindow))
+	}
+	if cfg.Cache != DefaultConfig().Cache {
+		t.Errorf("Cache = %+v after round trip", cfg.Cache)
+	}
+}
+
+func TestCacheFromYAML(t *testing.T) {
+	input := "cache:\n  max_age: \"168h\"\n  max_size_mb: 0\n"
+	cfg := DefaultConfig()
+	if err := yaml.Unmarshal([]byte(input), &cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if time.Duration(cfg.Cache.MaxAge) != 168*time.Hour {
+		t.Errorf("MaxAge = %v, want 168h", time.Duration(cfg.Cache.MaxAge))
+	}
+	if cfg.Cache.MaxSizeMB != 0 {
+		t.Errorf("MaxSizeMB = %d, want 0", cfg.Cache.MaxSizeMB)
+	}
+}
+
+func TestHTTPFromYAML(t *testing.T) {
+	input := "http:\n  timeout: \"3s\"\n  retries: 0\n  rate_limits:\n    api.openf1.org: \"1s\"\n"
+	cfg := DefaultConfig()
+	if err := yaml.Unmarshal([]byte(input), &cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if time.Duration(cfg.HTTP.Timeout) != 3*time.Second {
+		t.Errorf("Timeout = %v, want 3s", time.Duration(cfg.HTTP.Timeout))
+	}
+	if cfg.HTTP.Retries != 0 {
+		t.Errorf("Retries = %d, want 0", cfg.HTTP.Retries)
+	}
+	if cfg.HTTP.BreakerThreshold != 3 {
+		t.Errorf("BreakerThreshold = %d, want default 3", cfg.HTTP.BreakerThreshold)
+	}
+	if time.Duration(cfg.HTTP.RateLimits["api.openf1.org"]) != time.Second {
+		t.Errorf("RateLimits = %v", cfg.HTTP.RateLimits)
+	}
+}
+
+func TestEndpointsFromEnv(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := Save(Config{Endpoints: Endpoints{NASCAR: "http://yaml", OpenF1: "http://yaml-f1"}}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("RACEDAY_NASCAR_URL", "http://env")
+	t.Setenv("RACEDAY_OPEN_METEO_URL", "http://env-meteo")
+
+	got := Load().Endpoints
+	want := Endpoints{NASCAR: "http://env", OpenF1: "http://yaml-f1", OpenMeteo: "http://env-meteo"}
+	if got != want {
+		t.Errorf("Endpoints = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseRejectsUnknownKeys(t *testing.T) {
+	if _, err := Parse([]byte("status_widht: 40\n")); err == nil {
+		t.Error("expected an error for a misspelled key")
+	}
+	cfg, err := Parse([]byte("status_width: 40\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.StatusWidth != 40 || len(cfg.Series) == 0 {
+		t.Errorf("Parse did not decode over the defaults: %+v", cfg)
+	}
+	if _, err := Parse(nil); err != nil {
+		t.Errorf("empty file: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if errs := DefaultConfig().Validate(); len(errs) != 0 {
+		t.Fatalf("default config invalid: %v", errs)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Series = SeriesList{"nascar", "indycar"}
+	cfg.Drivers = DriverMap{"nascar": {24, -1}}
+	cfg.StatusWidth = -5
+	cfg.HTTP.Timeout = Duration(-time.Second)
+	cfg.Notify.Reminders = []Duration{Duration(time.Hour), 0}
+	cfg.Notify.QuietHours = QuietHours{Start: "23:00", End: "7am"}
+	cfg.Endpoints.OpenF1 = "localhost:8787"
+
+	var got []string
+	for _, err := range cfg.Validate() {
+		got = append(got, err.Error())
+	}
+	want := []string{
+		`series: unknown series "indycar" (want one of nascar, f1)`,
+		"drivers.nascar: invalid car number -1",
+		"status_width: must not be negative (got -5)",
+		"http.timeout: must not be negative (got -1s)",
+		`notify.quiet_hours.end: want a time like 23:00 (got "7am")`,
+		"notify.reminders[1]: must be positive (got 0s)",
+		`endpoints.openf1: "localhost:8787" is not an http(s) URL`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d errors, want %d:\n%v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("error %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestStatusFormatFromYAML(t *testing.T) {
+	cfg, err := Parse([]byte(`
+status_format:
+  live:
+    - text: "{{.Live.RaceName}}"
+      static: true
+    - text: " | {{.Weather}}"
+      priority: 4
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.StatusFormat.Live) != 2 || !cfg.StatusFormat.Live[0].Static {
+		t.Fatalf("StatusFormat = %+v", cfg.StatusFormat)
+	}
+	errs := cfg.Validate()
+	if len(errs) != 1 || errs[0].Error() != "status_format.live[1]: priority must be 0-3 (got 4)" {
+		t.Errorf("Validate = %v", errs)
+	}
+}
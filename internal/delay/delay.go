@@ -0,0 +1,148 @@
+// Package delay holds live data back to match a delayed TV broadcast. The
+// feeds run 20–60 seconds ahead of the pictures, so raceday buffers what
+// it fetches and shows what it had that long ago.
+package delay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+	"github.com/jfmyers/tmux-raceday/internal/config"
+)
+
+// Max bounds the delay; no broadcast lags the feeds by more.
+const Max = 5 * time.Minute
+
+// Step is how much the TUI changes the delay per key press.
+const Step = 5 * time.Second
+
+// Snapshot is a value as fetched at At.
+type Snapshot[T any] struct {
+	At    time.Time `json:"at"`
+	Value T         `json:"value"`
+}
+
+// Buffer keeps the last Max of snapshots so they can be replayed late.
+// The zero Buffer is empty and ready to use.
+type Buffer[T any] struct {
+	Snapshots []Snapshot[T] `json:"snapshots"`
+}
+
+// Push records v as fetched at at and drops snapshots too old to be
+// replayed.
+func (b *Buffer[T]) Push(at time.Time, v T) {
+	cutoff := at.Add(-Max)
+	keep := b.Snapshots[:0]
+	for _, s := range b.Snapshots {
+		if !s.At.Before(cutoff) && !s.At.After(at) {
+			keep = append(keep, s)
+		}
+	}
+	b.Snapshots = append(keep, Snapshot[T]{At: at, Value: v})
+}
+
+// At returns the value as it was at t: the latest snapshot taken at or
+// before t, or the oldest one if the buffer doesn't reach back that far.
+// It reports false if the buffer is empty.
+func (b *Buffer[T]) At(t time.Time) (T, bool) {
+	var zero T
+	if len(b.Snapshots) == 0 {
+		return zero, false
+	}
+	v := b.Snapshots[0].Value
+	for _, s := range b.Snapshots {
+		if s.At.After(t) {
+			break
+		}
+		v = s.Value
+	}
+	return v, true
+}
+
+// Trim drops the snapshots At(t) no longer needs, for buffers that
+// don't have to reach back further than t.
+func (b *Buffer[T]) Trim(t time.Time) {
+	i := 0
+	for i+1 < len(b.Snapshots) && !b.Snapshots[i+1].At.After(t) {
+		i++
+	}
+	b.Snapshots = b.Snapshots[i:]
+}
+
+// Load reads a buffer saved at path. A missing file is an empty buffer.
+func Load[T any](path string) (*Buffer[T], error) {
+	b := &Buffer[T]{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return b, err
+	}
+	if err := json.Unmarshal(data, b); err != nil {
+		return &Buffer[T]{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return b, nil
+}
+
+// Save writes b to path, replacing it atomically.
+func (b *Buffer[T]) Save(path string) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	return cache.WriteFile(path, data)
+}
+
+// BufferPath returns where status runs buffer a series' live state, e.g.
+// $XDG_STATE_HOME/raceday/delay-nascar.json.
+func BufferPath(series string) string {
+	return filepath.Join(config.StateDir(), "delay-"+strings.ToLower(series)+".json")
+}
+
+// SettingPath returns where the delay chosen in the TUI is kept, so the
+// status line follows it.
+func SettingPath() string {
+	return filepath.Join(config.StateDir(), "delay.json")
+}
+
+type setting struct {
+	Delay string `json:"delay"` // e.g. "35s"
+}
+
+// Setting returns the delay saved at path, or def if none is.
+func Setting(path string, def time.Duration) time.Duration {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return def
+	}
+	var s setting
+	if err := json.Unmarshal(data, &s); err != nil {
+		return def
+	}
+	d, err := time.ParseDuration(s.Delay)
+	if err != nil {
+		return def
+	}
+	return Clamp(d)
+}
+
+// SaveSetting saves d at path.
+func SaveSetting(path string, d time.Duration) error {
+	data, err := json.Marshal(setting{Delay: Clamp(d).String()})
+	if err != nil {
+		return err
+	}
+	return cache.WriteFile(path, data)
+}
+
+// Clamp limits d to 0–Max.
+func Clamp(d time.Duration) time.Duration {
+	return min(max(d, 0), Max)
+}
@@ -0,0 +1,89 @@
+package delay
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBuffer(t *testing.T) {
+	start := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+	var b Buffer[int]
+	if _, ok := b.At(start); ok {
+		t.Error("empty buffer returned a value")
+	}
+	for i := range 10 {
+		b.Push(start.Add(time.Duration(i)*5*time.Second), i)
+	}
+	now := start.Add(45 * time.Second)
+	tests := []struct {
+		delay time.Duration
+		want  int
+	}{
+		{0, 9},
+		{5 * time.Second, 8},
+		{12 * time.Second, 6}, // between snapshots: the earlier one
+		{time.Minute, 0},      // not buffered that far back: the oldest
+	}
+	for _, tt := range tests {
+		if got, _ := b.At(now.Add(-tt.delay)); got != tt.want {
+			t.Errorf("delay %s: got %d, want %d", tt.delay, got, tt.want)
+		}
+	}
+
+	trimmed := Buffer[int]{Snapshots: append([]Snapshot[int](nil), b.Snapshots...)}
+	trimmed.Trim(now.Add(-12 * time.Second))
+	if got, _ := trimmed.At(now.Add(-12 * time.Second)); got != 6 || len(trimmed.Snapshots) != 4 {
+		t.Errorf("trimmed to %d snapshots, At = %d; want 4 and 6", len(trimmed.Snapshots), got)
+	}
+
+	// Snapshots older than Max are dropped, so an old race doesn't
+	// resurface when the next one starts.
+	b.Push(now.Add(time.Hour), 100)
+	if len(b.Snapshots) != 1 {
+		t.Errorf("after an hour: %d snapshots, want 1", len(b.Snapshots))
+	}
+	if got, _ := b.At(now.Add(time.Hour - 30*time.Second)); got != 100 {
+		t.Errorf("got %d, want 100", got)
+	}
+}
+
+func TestBufferSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "delay-nascar.json")
+	b, err := Load[string](path)
+	if err != nil || len(b.Snapshots) != 0 {
+		t.Fatalf("missing file = %+v, %v", b, err)
+	}
+	at := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+	b.Push(at, "lap 10")
+	b.Push(at.Add(5*time.Second), "lap 11")
+	if err := b.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	b, err = Load[string](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := b.At(at.Add(time.Second)); got != "lap 10" {
+		t.Errorf("loaded buffer at +1s = %q", got)
+	}
+}
+
+func TestSetting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "delay.json")
+	if got := Setting(path, 20*time.Second); got != 20*time.Second {
+		t.Errorf("unset = %s, want the default", got)
+	}
+	if err := SaveSetting(path, 35*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got := Setting(path, 20*time.Second); got != 35*time.Second {
+		t.Errorf("saved = %s, want 35s", got)
+	}
+	if err := SaveSetting(path, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if got := Setting(path, 0); got != Max {
+		t.Errorf("saved an hour = %s, want it clamped to %s", got, Max)
+	}
+}
@@ -0,0 +1,270 @@
+// Package events turns consecutive live states into race events such as
+// cautions, lead changes and a favourite's pit stops.
+package events
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/series"
+)
+
+// Kind is the type of a race event.
+type Kind string
+
+const (
+	Caution    Kind = "caution"     // caution, yellow flag or safety car
+	Green      Kind = "green"       // racing resumes after a caution or red flag
+	RedFlag    Kind = "red_flag"    // session stopped
+	LeadChange Kind = "lead_change" // a new leader
+	Pit        Kind = "pit"         // a favourite made a pit stop
+	Position   Kind = "position"    // a favourite gained or lost positions
+	StageEnd   Kind = "stage_end"   // a stage finished
+	WhiteFlag  Kind = "white_flag"  // final lap
+	Finish     Kind = "finish"      // the race is over
+	Reminder   Kind = "reminder"    // a session starts soon
+	RuleMatch  Kind = "rule"        // a custom alert rule became true
+)
+
+// Kinds lists every event kind.
+var Kinds = []Kind{Caution, Green, RedFlag, WhiteFlag, LeadChange, Pit, Position, StageEnd, Finish, Reminder, RuleMatch}
+
+// Event is something that happened between two live states, or a
+// reminder of a session about to start.
+type Event struct {
+	Kind       Kind
+	Series     string // series short name, e.g. "NASCAR"
+	Race       string
+	Lap        int
+	FlagSymbol string        // flag events
+	FlagName   string        // flag events, e.g. "Caution" or "SAFETY CAR"
+	Driver     series.Driver // the new leader, the stage or race winner, or the favourite
+	From       int           // the favourite's previous position (Position)
+	Stage      int           // the stage that ended (StageEnd)
+	Rule       string        // the custom rule's name (RuleMatch)
+
+	// Digests stand for Count events of one kind, from FirstLap on, and
+	// otherwise describe the latest.
+	Count    int
+	FirstLap int
+
+	// Reminder events describe the session ahead.
+	Session     string        // e.g. "Qualifying"
+	Start       time.Time     // when the session starts
+	Until       time.Duration // how long until then
+	Broadcaster string        // e.g. "FOX"; empty if unknown
+	Weather     string        // conditions at the track; empty if unknown
+}
+
+// Title names the race the event happened in, e.g. "NASCAR · Würth 400".
+func (e Event) Title() string {
+	if e.Series == "" {
+		return e.Race
+	}
+	return e.Series + " · " + e.Race
+}
+
+// Message describes the event, e.g. "🟡 Caution, lap 57",
+// "#24 Byron up to P3 (+2), lap 120", "Top 5: #24 Byron P5, lap 80" or
+// "Race in 10m, 3:30 PM on FOX".
+func (e Event) Message() string {
+	var msg string
+	switch e.Kind {
+	case Caution:
+		msg = e.FlagName
+	case Green:
+		msg = "Green flag"
+	case RedFlag:
+		msg = "Red flag"
+	case WhiteFlag:
+		msg = "White flag"
+	case LeadChange:
+		msg = driverName(e.Driver) + " takes the lead"
+	case Pit:
+		msg = driverName(e.Driver) + " pits"
+	case Position:
+		dir := "up"
+		if e.Driver.Position > e.From {
+			dir = "down"
+		}
+		msg = fmt.Sprintf("%s %s to P%d (%+d)", driverName(e.Driver), dir, e.Driver.Position, e.From-e.Driver.Position)
+	case StageEnd:
+		msg = fmt.Sprintf("Stage %d to %s", e.Stage, driverName(e.Driver))
+	case Finish:
+		msg = driverName(e.Driver) + " wins"
+		if e.Driver.Number == "" {
+			msg = "Checkered flag"
+		}
+	case RuleMatch:
+		msg = e.Rule
+		if e.Driver.Number != "" {
+			msg += fmt.Sprintf(": %s P%d", driverName(e.Driver), e.Driver.Position)
+		}
+	case Reminder:
+		msg = fmt.Sprintf("%s in %s, %s", e.Session, until(e.Until), e.Start.Local().Format("3:04 PM"))
+		if e.Broadcaster != "" {
+			msg += " on " + e.Broadcaster
+		}
+		if e.Weather != "" {
+			msg += " · " + e.Weather
+		}
+	}
+	if e.FlagSymbol != "" {
+		msg = e.FlagSymbol + " " + msg
+	}
+	if e.Lap > 0 && e.Kind != Finish {
+		msg += fmt.Sprintf(", lap %d", e.Lap)
+	}
+	if e.Count > 1 {
+		msg = fmt.Sprintf("%d %s%s; latest %s", e.Count, plurals[e.Kind], e.span(), msg)
+	}
+	return msg
+}
+
+// plurals name several events of a kind, for digests.
+var plurals = map[Kind]string{
+	Caution:    "cautions",
+	Green:      "restarts",
+	RedFlag:    "red flags",
+	WhiteFlag:  "white flags",
+	LeadChange: "lead changes",
+	Pit:        "pit stops",
+	Position:   "position changes",
+	StageEnd:   "stage ends",
+	Finish:     "finishes",
+	Reminder:   "reminders",
+	RuleMatch:  "rule alerts",
+}
+
+// span says which laps a digest covers, e.g. " in the last 2 laps".
+func (e Event) span() string {
+	switch laps := e.Lap - e.FirstLap + 1; {
+	case e.Lap == 0 || e.FirstLap == 0:
+		return ""
+	case laps <= 1:
+		return " in the last lap"
+	default:
+		return fmt.Sprintf(" in the last %d laps", laps)
+	}
+}
+
+// Digest collapses evs, events of one kind in the order they happened,
+// into one: "3 lead changes in the last 2 laps; latest #24 Byron takes
+// the lead, lap 212".
+func Digest(evs []Event) Event {
+	e := evs[len(evs)-1]
+	if len(evs) > 1 {
+		e.Count, e.FirstLap = len(evs), evs[0].Lap
+	}
+	return e
+}
+
+// until formats d to the minute, e.g. "1h", "1h30m" or "10m".
+func until(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case h > 0 && m > 0:
+		return fmt.Sprintf("%dh%dm", h, m)
+	case h > 0:
+		return fmt.Sprintf("%dh", h)
+	}
+	return fmt.Sprintf("%dm", m)
+}
+
+func driverName(d series.Driver) string {
+	return strings.TrimSpace(fmt.Sprintf("#%s %s", d.Number, d.Name))
+}
+
+// flag groups the flags series report by what they mean for events.
+type flag int
+
+const (
+	flagOther flag = iota
+	flagGreen
+	flagCaution
+	flagRed
+	flagWhite
+)
+
+func flagOf(st *series.LiveState) flag {
+	switch strings.ToUpper(st.FlagName) {
+	case "GREEN":
+		return flagGreen
+	case "CAUTION", "YELLOW", "SAFETY CAR", "VSC":
+		return flagCaution
+	case "RED":
+		return flagRed
+	case "WHITE":
+		return flagWhite
+	}
+	return flagOther
+}
+
+// Detect returns the events between prev and cur, two states of the same
+// race: flag changes, a new leader, stage and race ends, and pit stops
+// and position changes for the favourite car numbers.
+func Detect(prev, cur *series.LiveState, favorites []string) []Event {
+	if prev == nil || cur == nil {
+		return nil
+	}
+	with := func(e Event) Event {
+		e.Series, e.Race, e.Lap = cur.ShortName, cur.RaceName, cur.CurrentLap
+		return e
+	}
+	var evs []Event
+
+	if !cur.Finished {
+		was, now := flagOf(prev), flagOf(cur)
+		flagEvent := func(k Kind) Event {
+			return with(Event{Kind: k, FlagSymbol: cur.FlagSymbol, FlagName: cur.FlagName})
+		}
+		switch {
+		case now == was:
+		case now == flagCaution:
+			evs = append(evs, flagEvent(Caution))
+		case now == flagGreen && (was == flagCaution || was == flagRed):
+			evs = append(evs, flagEvent(Green))
+		case now == flagRed:
+			evs = append(evs, flagEvent(RedFlag))
+		case now == flagWhite:
+			evs = append(evs, flagEvent(WhiteFlag))
+		}
+	}
+
+	if prev.Stage > 0 && cur.Stage > prev.Stage {
+		evs = append(evs, with(Event{Kind: StageEnd, Stage: prev.Stage, Driver: prev.Leader}))
+	}
+	if !cur.Finished && prev.Leader.Number != "" && cur.Leader.Number != "" && prev.Leader.Number != cur.Leader.Number {
+		evs = append(evs, with(Event{Kind: LeadChange, Driver: cur.Leader}))
+	}
+
+	for _, num := range favorites {
+		before, ok1 := find(prev.Positions, num)
+		after, ok2 := find(cur.Positions, num)
+		if !ok1 || !ok2 {
+			continue
+		}
+		if after.PitStops > before.PitStops {
+			evs = append(evs, with(Event{Kind: Pit, Driver: after}))
+		}
+		if before.Position > 0 && after.Position > 0 && after.Position != before.Position {
+			evs = append(evs, with(Event{Kind: Position, Driver: after, From: before.Position}))
+		}
+	}
+
+	if cur.Finished && !prev.Finished {
+		evs = append(evs, with(Event{Kind: Finish, Driver: cur.Leader, FlagSymbol: "🏁"}))
+	}
+	return evs
+}
+
+func find(positions []series.Driver, number string) (series.Driver, bool) {
+	i := slices.IndexFunc(positions, func(d series.Driver) bool { return d.Number == number })
+	if i < 0 {
+		return series.Driver{}, false
+	}
+	return positions[i], true
+}
@@ -0,0 +1,199 @@
+package events
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/series"
+)
+
+// state builds a live state with drivers in running order.
+func state(lap int, flag, symbol string, drivers ...series.Driver) *series.LiveState {
+	positions := make([]series.Driver, len(drivers))
+	for i, d := range drivers {
+		d.Position = i + 1
+		positions[i] = d
+	}
+	st := &series.LiveState{
+		ShortName: "NASCAR", RaceName: "Würth 400",
+		CurrentLap: lap, FlagName: flag, FlagSymbol: symbol, Stage: 1,
+		Positions: positions,
+	}
+	if len(positions) > 0 {
+		st.Leader = positions[0]
+	}
+	return st
+}
+
+func driver(num, name string, pits int) series.Driver {
+	return series.Driver{Number: num, Name: name, PitStops: pits}
+}
+
+func messages(evs []Event) []string {
+	var out []string
+	for _, e := range evs {
+		out = append(out, string(e.Kind)+": "+e.Message())
+	}
+	return out
+}
+
+func TestDetect(t *testing.T) {
+	larson, byron, bell := driver("5", "Larson", 2), driver("24", "Byron", 2), driver("20", "Bell", 2)
+	green := state(56, "Green", "🟢", larson, byron, bell)
+
+	caution := state(57, "Caution", "🟡", larson, byron, bell)
+	caution.Positions[1].PitStops = 3
+
+	// Larson led when stage 1 ended; Byron leads after the stage break.
+	restart := state(62, "Green", "🟢", byron, bell, larson)
+	restart.Stage = 2
+
+	white := state(400, "White", "🏳", byron, bell, larson)
+	finished := state(400, "Checkered", "🏁", byron, bell, larson)
+	finished.Finished = true
+
+	tests := []struct {
+		name      string
+		prev, cur *series.LiveState
+		want      []string
+	}{
+		{"no change", green, green, nil},
+		{"caution and pit", green, caution, []string{
+			"caution: 🟡 Caution, lap 57",
+			"pit: #24 Byron pits, lap 57",
+		}},
+		{"restart", caution, restart, []string{
+			"green: 🟢 Green flag, lap 62",
+			"stage_end: Stage 1 to #5 Larson, lap 62",
+			"lead_change: #24 Byron takes the lead, lap 62",
+			"position: #24 Byron up to P1 (+1), lap 62",
+			"position: #5 Larson down to P3 (-2), lap 62",
+		}},
+		{"white flag", restart, white, []string{"white_flag: 🏳 White flag, lap 400"}},
+		{"finish", white, finished, []string{"finish: 🏁 #24 Byron wins"}},
+		{"first state", nil, green, nil},
+	}
+	for _, tt := range tests {
+		got := messages(Detect(tt.prev, tt.cur, []string{"24", "5", "99"}))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+
+	// F1 flags: safety car, then green.
+	sc := &series.LiveState{ShortName: "F1", RaceName: "Monaco", CurrentLap: 20, FlagName: "SAFETY CAR", FlagSymbol: "🟡"}
+	back := &series.LiveState{ShortName: "F1", RaceName: "Monaco", CurrentLap: 24, FlagName: "GREEN", FlagSymbol: "🟢"}
+	got := messages(append(Detect(back, sc, nil), Detect(sc, back, nil)...))
+	want := []string{"caution: 🟡 SAFETY CAR, lap 20", "green: 🟢 Green flag, lap 24"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("F1 flags = %q, want %q", got, want)
+	}
+}
+
+func TestDetectRules(t *testing.T) {
+	rules, err := CompileRules([]config.Rule{
+		{Name: "Top 5", When: "driver.number == 24 and driver.position <= 5"},
+		{Name: "Toyota leads", When: `driver.leader and driver.make == "toyota"`},
+		{Name: "Long run", When: "driver.favorite and driver.laps_since_pit > 20"},
+		{When: "race.laps_to_go < 10 and race.caution"},
+		{Name: "Broken", When: "driver.position <"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "notify.rules[4]: at 18: expression ends early") || len(rules) != 4 {
+		t.Fatalf("CompileRules = %d rules, %v", len(rules), err)
+	}
+
+	bell, larson := driver("20", "Bell", 1), driver("5", "Larson", 1)
+	bell.Make, larson.Make = "Toyota", "Chevrolet"
+	bell.LastPitLap, larson.LastPitLap = 375, 360
+	byron := driver("24", "Byron", 1)
+	before := state(380, "Green", "🟢", larson, bell, driver("9", "Elliott", 1), driver("12", "Blaney", 1), driver("22", "Logano", 1), byron)
+	after := state(392, "Caution", "🟡", bell, larson, byron, driver("9", "Elliott", 1), driver("12", "Blaney", 1), driver("22", "Logano", 1))
+	before.TotalLaps, after.TotalLaps = 400, 400
+
+	got := messages(DetectRules(before, after, rules, []string{"5", "20"}))
+	want := []string{
+		"rule: Top 5: #24 Byron P3, lap 392",
+		"rule: Toyota leads: #20 Bell P1, lap 392",
+		"rule: Long run: #5 Larson P2, lap 392",
+		"rule: race.laps_to_go < 10 and race.caution, lap 392",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rules fired:\n got %q\nwant %q", got, want)
+	}
+	// Rules that stay true don't fire again.
+	if evs := DetectRules(after, after, rules, []string{"5", "20"}); evs != nil {
+		t.Errorf("repeated: %q", messages(evs))
+	}
+	// Laps to go are unknown in timed sessions.
+	before.TotalLaps, after.TotalLaps = 0, 0
+	if evs := DetectRules(before, after, rules[3:], nil); evs != nil {
+		t.Errorf("timed session: %q", messages(evs))
+	}
+}
+
+func TestDigest(t *testing.T) {
+	pit := func(lap int, num, name string) Event {
+		return Event{Kind: Pit, Lap: lap, Driver: series.Driver{Number: num, Name: name}}
+	}
+	if e := Digest([]Event{pit(80, "24", "Byron")}); e.Message() != "#24 Byron pits, lap 80" {
+		t.Errorf("single event = %q", e.Message())
+	}
+	e := Digest([]Event{pit(80, "24", "Byron"), pit(80, "5", "Larson"), pit(81, "20", "Bell")})
+	if want := "3 pit stops in the last 2 laps; latest #20 Bell pits, lap 81"; e.Message() != want {
+		t.Errorf("digest = %q, want %q", e.Message(), want)
+	}
+	for _, k := range Kinds {
+		if plurals[k] == "" {
+			t.Errorf("no plural for %s", k)
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+	now := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+	green := state(56, "Green", "🟢", driver("5", "Larson", 0))
+	caution := state(57, "Caution", "🟡", driver("5", "Larson", 0))
+
+	tr, err := LoadTracker(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evs := tr.Observe("NASCAR", green, now, nil); evs != nil {
+		t.Errorf("first state: %v", evs)
+	}
+	if err := tr.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next run picks up where the last left off, once.
+	tr, err = LoadTracker(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evs := tr.Observe("NASCAR", caution, now.Add(5*time.Second), nil); len(evs) != 1 || evs[0].Kind != Caution {
+		t.Errorf("after reload: %v", messages(evs))
+	}
+	if evs := tr.Observe("NASCAR", caution, now.Add(10*time.Second), nil); evs != nil {
+		t.Errorf("repeated: %v", messages(evs))
+	}
+
+	// A stale or different race is a new baseline.
+	if evs := tr.Observe("NASCAR", green, now.Add(time.Hour), nil); evs != nil {
+		t.Errorf("after a gap: %v", messages(evs))
+	}
+	other := state(10, "Caution", "🟡")
+	other.RaceName = "Coca-Cola 600"
+	if evs := tr.Observe("NASCAR", other, now.Add(time.Hour+5*time.Second), nil); evs != nil {
+		t.Errorf("new race: %v", messages(evs))
+	}
+
+	tr.Observe("NASCAR", nil, now.Add(2*time.Hour), nil)
+	if _, ok := tr.Last["NASCAR"]; ok {
+		t.Error("state kept after the session ended")
+	}
+}
@@ -0,0 +1,170 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/expr"
+	"github.com/jfmyers/tmux-raceday/internal/series"
+)
+
+// RuleFields are the fields rule conditions can use. A rule using a
+// driver field is checked for every car.
+var RuleFields = expr.Fields{
+	"race.series":     expr.String, // e.g. "NASCAR"
+	"race.name":       expr.String,
+	"race.track":      expr.String,
+	"race.flag":       expr.String, // e.g. "Caution" or "SAFETY CAR"
+	"race.lap":        expr.Number,
+	"race.laps":       expr.Number, // unknown for timed sessions
+	"race.laps_to_go": expr.Number, // unknown for timed sessions
+	"race.stage":      expr.Number,
+	"race.green":      expr.Bool,
+	"race.caution":    expr.Bool, // caution, yellow, safety car or VSC
+	"race.red_flag":   expr.Bool,
+	"race.white_flag": expr.Bool,
+	"race.finished":   expr.Bool,
+
+	"driver.number":         expr.String,
+	"driver.name":           expr.String,
+	"driver.full_name":      expr.String,
+	"driver.team":           expr.String,
+	"driver.make":           expr.String, // e.g. "Toyota"
+	"driver.tire":           expr.String, // e.g. "SOFT"
+	"driver.position":       expr.Number,
+	"driver.laps_down":      expr.Number,
+	"driver.pit_stops":      expr.Number,
+	"driver.laps_since_pit": expr.Number,
+	"driver.favorite":       expr.Bool,
+	"driver.leader":         expr.Bool,
+}
+
+// Rule is a compiled custom alert.
+type Rule struct {
+	Name string
+	when *expr.Expr
+}
+
+// CompileRules compiles the custom alerts rs. Invalid rules are left out
+// and reported in the error.
+func CompileRules(rs []config.Rule) ([]Rule, error) {
+	var out []Rule
+	var errs []error
+	for i, r := range rs {
+		when, err := expr.Compile(r.When, RuleFields)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("notify.rules[%d]: %w", i, err))
+			continue
+		}
+		name := r.Name
+		if name == "" {
+			name = r.When
+		}
+		out = append(out, Rule{Name: name, when: when})
+	}
+	return out, errors.Join(errs...)
+}
+
+// DetectRules returns an event for each rule whose condition became true
+// between prev and cur, two states of the same race. Rules about drivers
+// fire for each car they become true for. A rule that stays true doesn't
+// fire again until it has been false.
+func DetectRules(prev, cur *series.LiveState, rules []Rule, favorites []string) []Event {
+	if prev == nil || cur == nil {
+		return nil
+	}
+	var evs []Event
+	for _, r := range rules {
+		e := Event{Kind: RuleMatch, Series: cur.ShortName, Race: cur.RaceName, Lap: cur.CurrentLap, Rule: r.Name}
+		if !r.when.Uses("driver.") {
+			if r.when.Eval(ruleEnv(cur, nil, favorites)) && !r.when.Eval(ruleEnv(prev, nil, favorites)) {
+				evs = append(evs, e)
+			}
+			continue
+		}
+		for _, d := range cur.Positions {
+			if !r.when.Eval(ruleEnv(cur, &d, favorites)) {
+				continue
+			}
+			if before, ok := find(prev.Positions, d.Number); ok && r.when.Eval(ruleEnv(prev, &before, favorites)) {
+				continue
+			}
+			e.Driver = d
+			evs = append(evs, e)
+		}
+	}
+	return evs
+}
+
+// ruleEnv looks up rule fields in st and, for rules about drivers, d.
+func ruleEnv(st *series.LiveState, d *series.Driver, favorites []string) func(string) expr.Value {
+	num := func(n int) expr.Value { return expr.Num(float64(n)) }
+	return func(name string) expr.Value {
+		switch name {
+		case "race.series":
+			return expr.Str(st.ShortName)
+		case "race.name":
+			return expr.Str(st.RaceName)
+		case "race.track":
+			return expr.Str(st.TrackName)
+		case "race.flag":
+			return expr.Str(st.FlagName)
+		case "race.lap":
+			return num(st.CurrentLap)
+		case "race.laps":
+			if st.TotalLaps == 0 {
+				return expr.Null(expr.Number)
+			}
+			return num(st.TotalLaps)
+		case "race.laps_to_go":
+			if st.TotalLaps == 0 {
+				return expr.Null(expr.Number)
+			}
+			return num(max(st.TotalLaps-st.CurrentLap, 0))
+		case "race.stage":
+			return num(st.Stage)
+		case "race.green":
+			return expr.Boolean(flagOf(st) == flagGreen)
+		case "race.caution":
+			return expr.Boolean(flagOf(st) == flagCaution)
+		case "race.red_flag":
+			return expr.Boolean(flagOf(st) == flagRed)
+		case "race.white_flag":
+			return expr.Boolean(flagOf(st) == flagWhite)
+		case "race.finished":
+			return expr.Boolean(st.Finished)
+		}
+		if d == nil {
+			return expr.Null(RuleFields[name])
+		}
+		switch name {
+		case "driver.number":
+			return expr.Str(d.Number)
+		case "driver.name":
+			return expr.Str(d.Name)
+		case "driver.full_name":
+			return expr.Str(d.FullName)
+		case "driver.team":
+			return expr.Str(d.Team)
+		case "driver.make":
+			return expr.Str(d.Make)
+		case "driver.tire":
+			return expr.Str(d.Compound)
+		case "driver.position":
+			return num(d.Position)
+		case "driver.laps_down":
+			return num(d.LapsDown)
+		case "driver.pit_stops":
+			return num(d.PitStops)
+		case "driver.laps_since_pit":
+			return num(st.CurrentLap - d.LastPitLap)
+		case "driver.favorite":
+			return expr.Boolean(slices.Contains(favorites, d.Number))
+		case "driver.leader":
+			return expr.Boolean(d.Number != "" && d.Number == st.Leader.Number)
+		}
+		return expr.Null(RuleFields[name])
+	}
+}
@@ -0,0 +1,85 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/series"
+)
+
+// MaxGap is how old the last seen state may be and still be diffed. An
+// older one is only a new baseline, so a status line resumed mid-race
+// doesn't report what happened while it was off.
+const MaxGap = 10 * time.Minute
+
+// Path returns where the last seen live states are kept between status
+// runs.
+func Path() string {
+	return filepath.Join(config.StateDir(), "events.json")
+}
+
+// Seen is a series' live state when it was last observed.
+type Seen struct {
+	At    time.Time         `json:"at"`
+	State *series.LiveState `json:"state"`
+}
+
+// Tracker remembers each series' last live state, so events are
+// reported once even though every status run is a new process.
+type Tracker struct {
+	Last map[string]Seen `json:"last"`
+	// Rules are the custom alerts checked alongside the built-in events.
+	Rules []Rule `json:"-"`
+}
+
+// LoadTracker reads the tracker saved at path. A missing file is an
+// empty tracker; an unreadable one is returned empty with the error.
+func LoadTracker(path string) (*Tracker, error) {
+	t := &Tracker{Last: map[string]Seen{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return t, err
+	}
+	if err := json.Unmarshal(data, t); err != nil {
+		return &Tracker{Last: map[string]Seen{}}, fmt.Errorf("%s: %w", path, err)
+	}
+	if t.Last == nil {
+		t.Last = map[string]Seen{}
+	}
+	return t, nil
+}
+
+// Save writes t to path, replacing it atomically.
+func (t *Tracker) Save(path string) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return cache.WriteFile(path, data)
+}
+
+// Observe records cur as name's live state at now and returns the events
+// since the last one, if that was of the same race and recent enough to
+// compare. A nil cur means the series has no live session; its last
+// state is forgotten.
+func (t *Tracker) Observe(name string, cur *series.LiveState, now time.Time, favorites []string) []Event {
+	prev, ok := t.Last[name]
+	if cur == nil {
+		delete(t.Last, name)
+		return nil
+	}
+	t.Last[name] = Seen{At: now, State: cur}
+	if !ok || prev.State == nil || prev.State.RaceName != cur.RaceName || now.Sub(prev.At) > MaxGap {
+		return nil
+	}
+	return append(Detect(prev.State, cur, favorites), DetectRules(prev.State, cur, t.Rules, favorites)...)
+}
@@ -0,0 +1,203 @@
+// Package expr is the small expression language alert rules are written
+// in, e.g. `driver.number == 24 and driver.position <= 5`.
+//
+// Expressions compare fields with literals (numbers, "strings", true and
+// false) using == != < <= > >=, and combine conditions with and/&&, or/||,
+// not/! and parentheses. Strings compare case-insensitively, and a string
+// field may be compared with a number, as car numbers are strings.
+// Comparisons with an unknown value are false.
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Type is the type of a field or value.
+type Type int
+
+const (
+	Bool Type = iota
+	Number
+	String
+)
+
+func (t Type) String() string {
+	switch t {
+	case Bool:
+		return "bool"
+	case Number:
+		return "number"
+	}
+	return "string"
+}
+
+// Value is a field's value. A Null value is unknown.
+type Value struct {
+	Type Type
+	Null bool
+	Num  float64
+	Str  string
+	Bool bool
+}
+
+// Num, Str and Boolean make values; Null makes an unknown one.
+func Num(f float64) Value  { return Value{Type: Number, Num: f} }
+func Str(s string) Value   { return Value{Type: String, Str: s} }
+func Boolean(b bool) Value { return Value{Type: Bool, Bool: b} }
+func Null(t Type) Value    { return Value{Type: t, Null: true} }
+
+func (v Value) truth() bool { return v.Type == Bool && !v.Null && v.Bool }
+
+// number returns v as a number, if it is one or a string that reads as
+// one.
+func (v Value) number() (float64, bool) {
+	if v.Type == Number {
+		return v.Num, true
+	}
+	f, err := strconv.ParseFloat(v.Str, 64)
+	return f, err == nil
+}
+
+// Fields declares the fields an expression may use, and their types.
+type Fields map[string]Type
+
+// Expr is a compiled, type-checked boolean expression.
+type Expr struct {
+	src    string
+	root   node
+	fields []string
+}
+
+// Compile parses src and checks it against fields. The expression must
+// be a condition, that is boolean.
+func Compile(src string, fields Fields) (*Expr, error) {
+	p := &parser{src: src, fields: fields}
+	p.next()
+	root, err := p.or()
+	if p.err != nil {
+		err = p.err
+	}
+	if err == nil && p.tok.kind != tokEOF {
+		err = p.errorf("unexpected %s", p.tok)
+	}
+	if err == nil && root.typ() != Bool {
+		err = fmt.Errorf("%s is not a condition", root.typ())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Expr{src: src, root: root, fields: p.used}, nil
+}
+
+// String returns the expression's source.
+func (e *Expr) String() string { return e.src }
+
+// Uses reports whether e refers to a field starting with prefix.
+func (e *Expr) Uses(prefix string) bool {
+	for _, f := range e.fields {
+		if strings.HasPrefix(f, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Eval evaluates e, looking up fields with get.
+func (e *Expr) Eval(get func(field string) Value) bool {
+	return e.root.eval(get).truth()
+}
+
+type node interface {
+	typ() Type
+	eval(get func(string) Value) Value
+}
+
+type literal struct{ v Value }
+
+func (n literal) typ() Type                     { return n.v.Type }
+func (n literal) eval(func(string) Value) Value { return n.v }
+
+type field struct {
+	name string
+	t    Type
+}
+
+func (n field) typ() Type                         { return n.t }
+func (n field) eval(get func(string) Value) Value { return get(n.name) }
+
+type not struct{ x node }
+
+func (n not) typ() Type { return Bool }
+func (n not) eval(get func(string) Value) Value {
+	return Boolean(!n.x.eval(get).truth())
+}
+
+type logic struct {
+	and  bool
+	l, r node
+}
+
+func (n logic) typ() Type { return Bool }
+func (n logic) eval(get func(string) Value) Value {
+	l := n.l.eval(get).truth()
+	if l != n.and {
+		return Boolean(l) // false and …, true or …
+	}
+	return Boolean(n.r.eval(get).truth())
+}
+
+type compare struct {
+	op   string
+	l, r node
+}
+
+func (n compare) typ() Type { return Bool }
+func (n compare) eval(get func(string) Value) Value {
+	l, r := n.l.eval(get), n.r.eval(get)
+	if l.Null || r.Null {
+		return Boolean(false)
+	}
+	var c int
+	switch {
+	case l.Type == Bool:
+		c = 1
+		if l.Bool == r.Bool {
+			c = 0
+		}
+	case l.Type == String && r.Type == String:
+		c = strings.Compare(strings.ToLower(l.Str), strings.ToLower(r.Str))
+	default:
+		// A number, maybe against a string such as a car number.
+		lf, ok1 := l.number()
+		rf, ok2 := r.number()
+		if !ok1 || !ok2 {
+			return Boolean(n.op == "!=")
+		}
+		c = cmpFloat(lf, rf)
+	}
+	switch n.op {
+	case "==":
+		return Boolean(c == 0)
+	case "!=":
+		return Boolean(c != 0)
+	case "<":
+		return Boolean(c < 0)
+	case "<=":
+		return Boolean(c <= 0)
+	case ">":
+		return Boolean(c > 0)
+	}
+	return Boolean(c >= 0)
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,89 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+)
+
+var fields = Fields{
+	"driver.number":   String,
+	"driver.position": Number,
+	"driver.make":     String,
+	"driver.favorite": Bool,
+	"race.laps_to_go": Number,
+	"race.caution":    Bool,
+}
+
+func env(values map[string]Value) func(string) Value {
+	return func(name string) Value { return values[name] }
+}
+
+func TestEval(t *testing.T) {
+	byron := env(map[string]Value{
+		"driver.number":   Str("24"),
+		"driver.position": Num(4),
+		"driver.make":     Str("Chevrolet"),
+		"driver.favorite": Boolean(true),
+		"race.laps_to_go": Null(Number),
+		"race.caution":    Boolean(false),
+	})
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{`driver.number == 24 and driver.position <= 5`, true},
+		{`driver.number == "24" && driver.position < 4`, false},
+		{`driver.make == "chevrolet"`, true},
+		{`driver.make != 'Toyota' && !race.caution`, true},
+		{`driver.favorite`, true},
+		{`not (driver.position > 3 or race.caution)`, false},
+		{`driver.number > 9`, true}, // numerically, not "24" > "9"
+		{`driver.make == 3`, false},
+		{`race.laps_to_go < 10`, false}, // unknown
+		{`race.laps_to_go >= 10`, false},
+		{`race.laps_to_go < 10 || driver.position == 4`, true},
+	}
+	for _, tt := range tests {
+		e, err := Compile(tt.src, fields)
+		if err != nil {
+			t.Errorf("%s: %v", tt.src, err)
+			continue
+		}
+		if got := e.Eval(byron); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{`driver.postion == 1`, `at 1: unknown field "driver.postion"`},
+		{`driver.position`, `number is not a condition`},
+		{`driver.position == 1 and`, `at 25: expression ends early`},
+		{`driver.favorite < true`, `at 17: cannot compare bool < bool`},
+		{`driver.position && race.caution`, `at 17: && needs conditions on both sides`},
+		{`not driver.make`, `at 1: not needs a condition`},
+		{`(race.caution`, `at 14: expected ) but found end of expression`},
+		{`driver.make == "Ford`, `at 16: unterminated string`},
+		{`driver.position = 1`, `at 17: unexpected "="`},
+		{`race.caution race.caution`, `at 14: unexpected "race.caution"`},
+	}
+	for _, tt := range tests {
+		_, err := Compile(tt.src, fields)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error %v, want %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestUses(t *testing.T) {
+	e, err := Compile(`race.caution and race.laps_to_go < 10`, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Uses("driver.") || !e.Uses("race.") {
+		t.Error("Uses should report the fields referred to")
+	}
+}
@@ -0,0 +1,229 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// parser is a recursive-descent parser that type-checks as it goes:
+//
+//	or      = and { ("or" | "||") and }
+//	and     = unary { ("and" | "&&") unary }
+//	unary   = ("not" | "!") unary | compare
+//	compare = primary [ ("==" | "!=" | "<" | "<=" | ">" | ">=") primary ]
+//	primary = "(" or ")" | number | string | "true" | "false" | field
+type parser struct {
+	src    string
+	pos    int
+	tok    token
+	fields Fields
+	used   []string
+	err    error
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("at %d: %s", p.tok.pos+1, fmt.Sprintf(format, args...))
+}
+
+// next reads the next token into p.tok.
+func (p *parser) next() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = token{tokEOF, "", start}
+		return
+	}
+	c := p.src[p.pos]
+	switch {
+	case c == '"' || c == '\'':
+		end := strings.IndexByte(p.src[p.pos+1:], c)
+		if end < 0 {
+			p.tok = token{tokOp, p.src[p.pos:], start}
+			p.err = fmt.Errorf("at %d: unterminated string", start+1)
+			p.pos = len(p.src)
+			return
+		}
+		p.tok = token{tokString, p.src[p.pos+1 : p.pos+1+end], start}
+		p.pos += end + 2
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.src) && (p.src[p.pos] >= '0' && p.src[p.pos] <= '9' || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		p.tok = token{tokNumber, p.src[start:p.pos], start}
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || p.src[p.pos] == '.' ||
+			unicode.IsLetter(rune(p.src[p.pos])) || unicode.IsDigit(rune(p.src[p.pos]))) {
+			p.pos++
+		}
+		p.tok = token{tokIdent, p.src[start:p.pos], start}
+	default:
+		for _, op := range []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")"} {
+			if strings.HasPrefix(p.src[p.pos:], op) {
+				p.pos += len(op)
+				p.tok = token{tokOp, op, start}
+				return
+			}
+		}
+		p.tok = token{tokOp, string(c), start}
+		p.err = fmt.Errorf("at %d: unexpected %q", start+1, string(c))
+		p.pos = len(p.src)
+	}
+}
+
+// is reports whether the current token is one of words, which are
+// operators or keywords.
+func (p *parser) is(words ...string) bool {
+	if p.tok.kind != tokOp && p.tok.kind != tokIdent {
+		return false
+	}
+	for _, w := range words {
+		if p.tok.text == w {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) or() (node, error) {
+	return p.logic(false, p.and, "or", "||")
+}
+
+func (p *parser) and() (node, error) {
+	return p.logic(true, p.unary, "and", "&&")
+}
+
+func (p *parser) logic(and bool, operand func() (node, error), ops ...string) (node, error) {
+	l, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(ops...) {
+		op := p.tok
+		p.next()
+		r, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if l.typ() != Bool || r.typ() != Bool {
+			return nil, fmt.Errorf("at %d: %s needs conditions on both sides", op.pos+1, op.text)
+		}
+		l = logic{and, l, r}
+	}
+	return l, nil
+}
+
+func (p *parser) unary() (node, error) {
+	if !p.is("not", "!") {
+		return p.compare()
+	}
+	op := p.tok
+	p.next()
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	if x.typ() != Bool {
+		return nil, fmt.Errorf("at %d: %s needs a condition", op.pos+1, op.text)
+	}
+	return not{x}, nil
+}
+
+func (p *parser) compare() (node, error) {
+	l, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.is("==", "!=", "<", "<=", ">", ">=") {
+		return l, nil
+	}
+	op := p.tok
+	p.next()
+	r, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	lt, rt := l.typ(), r.typ()
+	switch {
+	case lt == rt && (lt != Bool || op.text == "==" || op.text == "!="):
+	case lt != Bool && rt != Bool: // strings and numbers, e.g. car numbers
+	default:
+		return nil, fmt.Errorf("at %d: cannot compare %s %s %s", op.pos+1, lt, op.text, rt)
+	}
+	return compare{op.text, l, r}, nil
+}
+
+func (p *parser) primary() (node, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	tok := p.tok
+	switch tok.kind {
+	case tokEOF:
+		return nil, p.errorf("expression ends early")
+	case tokNumber:
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf("bad number %q", tok.text)
+		}
+		p.next()
+		return literal{Num(f)}, nil
+	case tokString:
+		p.next()
+		return literal{Str(tok.text)}, nil
+	case tokIdent:
+		switch tok.text {
+		case "true", "false":
+			p.next()
+			return literal{Boolean(tok.text == "true")}, nil
+		case "and", "or", "not":
+			return nil, p.errorf("unexpected %s", tok)
+		}
+		t, ok := p.fields[tok.text]
+		if !ok {
+			return nil, p.errorf("unknown field %q", tok.text)
+		}
+		p.used = append(p.used, tok.text)
+		p.next()
+		return field{tok.text, t}, nil
+	}
+	if tok.text == "(" {
+		p.next()
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.is(")") {
+			return nil, p.errorf("expected ) but found %s", p.tok)
+		}
+		p.next()
+		return x, nil
+	}
+	return nil, p.errorf("unexpected %s", tok)
+}
@@ -1,6 +1,7 @@
 package f1
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -63,7 +64,7 @@ func sessionDataTTL(sess *Session) time.Duration {
 
 // cachedFetch reads from cache if fresh, otherwise calls fetch and caches result.
 // TTL of 0 bypasses cache reads (always fetches).
-func cachedFetch[T any](cacheKey string, ttl time.Duration, fetch func() (T, error)) (T, error) {
+func cachedFetch[T any](ctx context.Context, cacheKey string, ttl time.Duration, fetch func() (T, error)) (T, error) {
 	if ttl > 0 {
 		if data, ok := fileCache.Read(cacheKey, ttl); ok {
 			var v T
@@ -73,10 +74,16 @@ func cachedFetch[T any](cacheKey string, ttl time.Duration, fetch func() (T, err
 		}
 	}
 
-	v, err := fetch()
+	data, err := fileCache.Refresh(ctx, cacheKey, ttl, func() ([]byte, error) {
+		v, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(v)
+	})
 	if err != nil {
 		// Fall back to stale cache on API failure.
-		if data, _ := fileCache.ReadStale(cacheKey, ttl); data != nil {
+		if data := fileCache.Fallback(ctx, cacheKey); data != nil {
 			var stale T
 			if json.Unmarshal(data, &stale) == nil {
 				return stale, nil
@@ -86,40 +93,72 @@ func cachedFetch[T any](cacheKey string, ttl time.Duration, fetch func() (T, err
 		return zero, err
 	}
 
-	if data, err := json.Marshal(v); err == nil {
-		_ = fileCache.Write(cacheKey, data)
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		var zero T
+		return zero, err
 	}
 	return v, nil
 }
 
-func cachedFetchPositions(sess *Session) ([]Position, error) {
-	return cachedFetch(
-		fmt.Sprintf("positions_%d.json", sess.SessionKey),
+// cachedFetchPositions returns the session's full position history,
+// fetching only entries newer than the stored log.
+func cachedFetchPositions(ctx context.Context, sess *Session) ([]Position, error) {
+	return syncLog(
+		ctx,
+		fmt.Sprintf("position_log_%d.json", sess.SessionKey),
+		sessionDataTTL(sess),
+		func(since string) ([]Position, error) { return FetchPositionsSince(ctx, sess.SessionKey, since) },
+	)
+}
+
+// cachedFetchIntervals returns each driver's latest timing gaps, fetching
+// only entries newer than the stored log.
+func cachedFetchIntervals(ctx context.Context, sess *Session) ([]Interval, error) {
+	return syncLog(
+		ctx,
+		fmt.Sprintf("interval_log_%d.json", sess.SessionKey),
 		sessionDataTTL(sess),
-		func() ([]Position, error) { return FetchPositions(sess.SessionKey) },
+		func(since string) ([]Interval, error) { return FetchIntervalsSince(ctx, sess.SessionKey, since) },
 	)
 }
 
-func cachedFetchDrivers(sess *Session) ([]DriverInfo, error) {
+func cachedFetchDrivers(ctx context.Context, sess *Session) ([]DriverInfo, error) {
 	return cachedFetch(
+		ctx,
 		fmt.Sprintf("drivers_%d.json", sess.SessionKey),
 		sessionDataTTL(sess),
-		func() ([]DriverInfo, error) { return FetchDrivers(sess.SessionKey) },
+		func() ([]DriverInfo, error) { return FetchDrivers(ctx, sess.SessionKey) },
+	)
+}
+
+// cachedFetchRaceControl returns every race control message for the
+// session, fetching only messages newer than the stored log.
+func cachedFetchRaceControl(ctx context.Context, sess *Session) ([]RaceControlMessage, error) {
+	return syncLog(
+		ctx,
+		fmt.Sprintf("race_control_log_%d.json", sess.SessionKey),
+		sessionDataTTL(sess),
+		func(since string) ([]RaceControlMessage, error) {
+			return FetchRaceControlSince(ctx, sess.SessionKey, since)
+		},
 	)
 }
 
-func cachedFetchRaceControl(sess *Session) ([]RaceControlMessage, error) {
+func cachedFetchSessionResult(ctx context.Context, sess *Session) ([]SessionResult, error) {
 	return cachedFetch(
-		fmt.Sprintf("race_control_%d.json", sess.SessionKey),
+		ctx,
+		fmt.Sprintf("session_result_%d.json", sess.SessionKey),
 		sessionDataTTL(sess),
-		func() ([]RaceControlMessage, error) { return FetchRaceControl(sess.SessionKey) },
+		func() ([]SessionResult, error) { return FetchSessionResult(ctx, sess.SessionKey) },
 	)
 }
 
-func cachedFetchStints(sess *Session) ([]Stint, error) {
+func cachedFetchStints(ctx context.Context, sess *Session) ([]Stint, error) {
 	return cachedFetch(
+		ctx,
 		fmt.Sprintf("stints_%d.json", sess.SessionKey),
 		sessionDataTTL(sess),
-		func() ([]Stint, error) { return FetchStints(sess.SessionKey) },
+		func() ([]Stint, error) { return FetchStints(ctx, sess.SessionKey) },
 	)
 }
@@ -1,35 +1,50 @@
 package f1
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"strings"
 	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+	"github.com/jfmyers/tmux-raceday/internal/fetch"
+	"github.com/jfmyers/tmux-raceday/internal/schema"
 )
 
-var baseURL = "https://api.openf1.org/v1"
+// defaultBaseURL is the OpenF1 API root.
+const defaultBaseURL = "https://api.openf1.org/v1"
 
-var httpClient = &http.Client{Timeout: 10 * time.Second}
+// baseURL is where OpenF1 requests go. Override it with SetBaseURL.
+var baseURL = defaultBaseURL
 
-func fetchJSON(url string, v any) error {
-	resp, err := httpClient.Get(url)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("openf1: %s returned %d", url, resp.StatusCode)
+// SetBaseURL points all OpenF1 requests at url, e.g. a mirror or fixture
+// server, and caches their responses apart from OpenF1's. An empty url
+// keeps the current one.
+func SetBaseURL(url string) {
+	if url != "" {
+		baseURL = strings.TrimSuffix(url, "/")
+		fileCache = cache.NewFor("f1", baseURL, defaultBaseURL)
 	}
-	body, err := io.ReadAll(resp.Body)
+}
+
+// ProbeURL returns a lightweight URL for checking that OpenF1 is
+// reachable.
+func ProbeURL() string { return baseURL + "/sessions?session_key=latest" }
+
+// fetchJSON fetches url into v, recording the payload's schema check
+// for feed.
+func fetchJSON(ctx context.Context, feed, url string, v any) error {
+	body, err := fetch.Get(ctx, url)
 	if err != nil {
-		return err
+		return fmt.Errorf("openf1: %w", err)
 	}
+	schema.Record(feed, validators[feed](body))
 	return json.Unmarshal(body, v)
 }
 
 // FetchMeetings returns all meetings for a given year.
-func FetchMeetings(year int) ([]Meeting, error) {
+func FetchMeetings(ctx context.Context, year int) ([]Meeting, error) {
 	cacheKey := fmt.Sprintf("meetings_%d.json", year)
 	if data, ok := fileCache.Read(cacheKey, cacheTTL); ok {
 		var m []Meeting
@@ -38,21 +53,44 @@ func FetchMeetings(year int) ([]Meeting, error) {
 		}
 	}
 
-	url := fmt.Sprintf("%s/meetings?year=%d", baseURL, year)
-	var meetings []Meeting
-	if err := fetchJSON(url, &meetings); err != nil {
-		return nil, err
+	data, err := fileCache.Refresh(ctx, cacheKey, cacheTTL, func() ([]byte, error) {
+		url := fmt.Sprintf("%s/meetings?year=%d", baseURL, year)
+		var meetings []Meeting
+		if err := fetchJSON(ctx, FeedMeetings, url, &meetings); err != nil {
+			return nil, err
+		}
+		return json.Marshal(meetings)
+	})
+	if err != nil {
+		// Fall back to stale cache on API failure.
+		stale := fileCache.Fallback(ctx, cacheKey)
+		if stale == nil {
+			return nil, err
+		}
+		data = stale
 	}
 
-	if data, err := json.Marshal(meetings); err == nil {
-		fileCache.Write(cacheKey, data)
+	var meetings []Meeting
+	if err := json.Unmarshal(data, &meetings); err != nil {
+		return nil, err
 	}
 	return meetings, nil
 }
 
 // FetchRaceSessions returns race sessions for a given year.
-func FetchRaceSessions(year int) ([]Session, error) {
-	cacheKey := fmt.Sprintf("race_sessions_%d.json", year)
+func FetchRaceSessions(ctx context.Context, year int) ([]Session, error) {
+	return fetchSessions(ctx, fmt.Sprintf("race_sessions_%d.json", year),
+		fmt.Sprintf("%s/sessions?year=%d&session_name=Race", baseURL, year))
+}
+
+// FetchSessions returns every session of a given year: practice,
+// qualifying, sprints and races.
+func FetchSessions(ctx context.Context, year int) ([]Session, error) {
+	return fetchSessions(ctx, fmt.Sprintf("sessions_%d.json", year),
+		fmt.Sprintf("%s/sessions?year=%d", baseURL, year))
+}
+
+func fetchSessions(ctx context.Context, cacheKey, url string) ([]Session, error) {
 	if data, ok := fileCache.Read(cacheKey, cacheTTL); ok {
 		var s []Session
 		if err := json.Unmarshal(data, &s); err == nil {
@@ -60,14 +98,25 @@ func FetchRaceSessions(year int) ([]Session, error) {
 		}
 	}
 
-	url := fmt.Sprintf("%s/sessions?year=%d&session_name=Race", baseURL, year)
-	var sessions []Session
-	if err := fetchJSON(url, &sessions); err != nil {
-		return nil, err
+	data, err := fileCache.Refresh(ctx, cacheKey, cacheTTL, func() ([]byte, error) {
+		var sessions []Session
+		if err := fetchJSON(ctx, FeedSessions, url, &sessions); err != nil {
+			return nil, err
+		}
+		return json.Marshal(sessions)
+	})
+	if err != nil {
+		// Fall back to stale cache on API failure.
+		stale := fileCache.Fallback(ctx, cacheKey)
+		if stale == nil {
+			return nil, err
+		}
+		data = stale
 	}
 
-	if data, err := json.Marshal(sessions); err == nil {
-		fileCache.Write(cacheKey, data)
+	var sessions []Session
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, err
 	}
 	return sessions, nil
 }
@@ -75,7 +124,7 @@ func FetchRaceSessions(year int) ([]Session, error) {
 // FetchLatestSession returns the current or most recent session.
 // Results are cached with a proximity-based TTL that shortens as a
 // session approaches so we hit the network less when nothing is live.
-func FetchLatestSession() (*Session, error) {
+func FetchLatestSession(ctx context.Context) (*Session, error) {
 	const cacheKey = "latest_session.json"
 
 	// Compute TTL from previously cached session data.
@@ -87,10 +136,10 @@ func FetchLatestSession() (*Session, error) {
 		}
 	}
 
-	sessions, err := cachedFetch(cacheKey, ttl, func() ([]Session, error) {
+	sessions, err := cachedFetch(ctx, cacheKey, ttl, func() ([]Session, error) {
 		url := baseURL + "/sessions?session_key=latest"
 		var s []Session
-		if err := fetchJSON(url, &s); err != nil {
+		if err := fetchJSON(ctx, FeedSessions, url, &s); err != nil {
 			return nil, err
 		}
 		return s, nil
@@ -105,40 +154,74 @@ func FetchLatestSession() (*Session, error) {
 }
 
 // FetchPositions returns position data for a session.
-func FetchPositions(sessionKey int) ([]Position, error) {
-	url := fmt.Sprintf("%s/position?session_key=%d", baseURL, sessionKey)
+func FetchPositions(ctx context.Context, sessionKey int) ([]Position, error) {
+	return FetchPositionsSince(ctx, sessionKey, "")
+}
+
+// FetchPositionsSince returns position changes recorded after since
+// (an OpenF1 date); an empty since returns the full history.
+func FetchPositionsSince(ctx context.Context, sessionKey int, since string) ([]Position, error) {
+	url := fmt.Sprintf("%s/position?session_key=%d%s", baseURL, sessionKey, sinceFilter(since))
 	var positions []Position
-	if err := fetchJSON(url, &positions); err != nil {
+	if err := fetchJSON(ctx, FeedPositions, url, &positions); err != nil {
 		return nil, err
 	}
 	return positions, nil
 }
 
+// FetchIntervalsSince returns timing gaps recorded after since (an
+// OpenF1 date); an empty since returns the full history.
+func FetchIntervalsSince(ctx context.Context, sessionKey int, since string) ([]Interval, error) {
+	url := fmt.Sprintf("%s/intervals?session_key=%d%s", baseURL, sessionKey, sinceFilter(since))
+	var intervals []Interval
+	if err := fetchJSON(ctx, FeedIntervals, url, &intervals); err != nil {
+		return nil, err
+	}
+	return intervals, nil
+}
+
+// FetchSessionResult returns the classification for a session. It is
+// empty until the session has finished.
+func FetchSessionResult(ctx context.Context, sessionKey int) ([]SessionResult, error) {
+	url := fmt.Sprintf("%s/session_result?session_key=%d", baseURL, sessionKey)
+	var results []SessionResult
+	if err := fetchJSON(ctx, FeedSessionResult, url, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
 // FetchDrivers returns driver info for a session.
-func FetchDrivers(sessionKey int) ([]DriverInfo, error) {
+func FetchDrivers(ctx context.Context, sessionKey int) ([]DriverInfo, error) {
 	url := fmt.Sprintf("%s/drivers?session_key=%d", baseURL, sessionKey)
 	var drivers []DriverInfo
-	if err := fetchJSON(url, &drivers); err != nil {
+	if err := fetchJSON(ctx, FeedDrivers, url, &drivers); err != nil {
 		return nil, err
 	}
 	return drivers, nil
 }
 
 // FetchRaceControl returns race control messages for a session.
-func FetchRaceControl(sessionKey int) ([]RaceControlMessage, error) {
-	url := fmt.Sprintf("%s/race_control?session_key=%d", baseURL, sessionKey)
+func FetchRaceControl(ctx context.Context, sessionKey int) ([]RaceControlMessage, error) {
+	return FetchRaceControlSince(ctx, sessionKey, "")
+}
+
+// FetchRaceControlSince returns race control messages issued after since
+// (an OpenF1 date); an empty since returns every message.
+func FetchRaceControlSince(ctx context.Context, sessionKey int, since string) ([]RaceControlMessage, error) {
+	url := fmt.Sprintf("%s/race_control?session_key=%d%s", baseURL, sessionKey, sinceFilter(since))
 	var msgs []RaceControlMessage
-	if err := fetchJSON(url, &msgs); err != nil {
+	if err := fetchJSON(ctx, FeedRaceControl, url, &msgs); err != nil {
 		return nil, err
 	}
 	return msgs, nil
 }
 
 // FetchStints returns stint data for a session.
-func FetchStints(sessionKey int) ([]Stint, error) {
+func FetchStints(ctx context.Context, sessionKey int) ([]Stint, error) {
 	url := fmt.Sprintf("%s/stints?session_key=%d", baseURL, sessionKey)
 	var stints []Stint
-	if err := fetchJSON(url, &stints); err != nil {
+	if err := fetchJSON(ctx, FeedStints, url, &stints); err != nil {
 		return nil, err
 	}
 	return stints, nil
@@ -0,0 +1,40 @@
+package f1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+	"github.com/jfmyers/tmux-raceday/internal/mockserver"
+	"github.com/jfmyers/tmux-raceday/internal/mockserver/mockservertest"
+)
+
+func TestFetchFromMockServer(t *testing.T) {
+	ts := mockservertest.Start(t, mockserver.Options{})
+	origBase, origCache := baseURL, fileCache
+	SetBaseURL(ts.Endpoints().OpenF1)
+	fileCache = cache.NewDir(t.TempDir())
+	defer func() { baseURL, fileCache = origBase, origCache }()
+	ctx := context.Background()
+
+	sess, err := FetchLatestSession(ctx)
+	if err != nil || sess == nil {
+		t.Fatalf("FetchLatestSession = %v, %v", sess, err)
+	}
+	if sess.SessionKey != 9839 {
+		t.Errorf("latest session = %d, want 9839", sess.SessionKey)
+	}
+
+	msgs, err := FetchRaceControlSince(ctx, sess.SessionKey, "2026-12-06T17:00:00+00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 3 {
+		t.Errorf("race control since 17:00 = %d messages, want 3", len(msgs))
+	}
+
+	drivers, err := FetchDrivers(ctx, sess.SessionKey)
+	if err != nil || len(drivers) != 4 {
+		t.Errorf("FetchDrivers = %d drivers, %v", len(drivers), err)
+	}
+}
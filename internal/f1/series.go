@@ -1,6 +1,7 @@
 package f1
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -17,12 +18,12 @@ func NewSeries() *F1Series { return &F1Series{} }
 func (s *F1Series) Name() string      { return "Formula 1" }
 func (s *F1Series) ShortName() string  { return "F1" }
 
-func (s *F1Series) FetchSchedule(year int) ([]series.Race, error) {
-	meetings, err := FetchMeetings(year)
+func (s *F1Series) FetchSchedule(ctx context.Context, year int) ([]series.Race, error) {
+	meetings, err := FetchMeetings(ctx, year)
 	if err != nil {
 		return nil, fmt.Errorf("f1 meetings: %w", err)
 	}
-	sessions, err := FetchRaceSessions(year)
+	sessions, err := FetchRaceSessions(ctx, year)
 	if err != nil {
 		return nil, fmt.Errorf("f1 race sessions: %w", err)
 	}
@@ -39,41 +40,190 @@ func (s *F1Series) FetchSchedule(year int) ([]series.Race, error) {
 		if !ok {
 			continue
 		}
+		if r, ok := s.race(m, sess, now); ok {
+			races = append(races, r)
+		}
+	}
+	return races, nil
+}
 
-		startTime, err := time.Parse(time.RFC3339, sess.DateStart)
+// FetchSessions returns every session of year's race weekends, each with
+// its weekend's race.
+func (s *F1Series) FetchSessions(ctx context.Context, year int) ([]series.Session, error) {
+	meetings, err := FetchMeetings(ctx, year)
+	if err != nil {
+		return nil, fmt.Errorf("f1 meetings: %w", err)
+	}
+	sessions, err := FetchSessions(ctx, year)
+	if err != nil {
+		return nil, fmt.Errorf("f1 sessions: %w", err)
+	}
+
+	meetingByKey := make(map[int]Meeting, len(meetings))
+	for _, m := range meetings {
+		meetingByKey[m.MeetingKey] = m
+	}
+	now := time.Now()
+	raceByMeeting := map[int]series.Race{}
+	for _, sess := range sessions {
+		if m, ok := meetingByKey[sess.MeetingKey]; ok && sess.SessionName == "Race" {
+			if r, ok := s.race(m, sess, now); ok {
+				raceByMeeting[sess.MeetingKey] = r
+			}
+		}
+	}
+
+	var out []series.Session
+	for _, sess := range sessions {
+		m, ok := meetingByKey[sess.MeetingKey]
+		if !ok {
+			continue
+		}
+		start, err := time.Parse(time.RFC3339, sess.DateStart)
 		if err != nil {
 			continue
 		}
+		r, ok := raceByMeeting[sess.MeetingKey]
+		if !ok {
+			// Testing weekends have no race.
+			r, _ = s.race(m, sess, now)
+		}
+		out = append(out, series.Session{Race: r, Name: sess.SessionName, StartTime: start})
+	}
+	return out, nil
+}
+
+// race describes meeting m's race, held in session sess.
+func (s *F1Series) race(m Meeting, sess Session, now time.Time) (series.Race, bool) {
+	startTime, err := time.Parse(time.RFC3339, sess.DateStart)
+	if err != nil {
+		return series.Race{}, false
+	}
+
+	endTime, _ := time.Parse(time.RFC3339, sess.DateEnd)
+	complete := !endTime.IsZero() && endTime.Before(now)
+
+	lat, lon, _ := CircuitCoords(m.Location)
+
+	return series.Race{
+		SeriesName: s.Name(),
+		ShortName:  s.ShortName(),
+		RaceName:   raceName(m),
+		TrackName:  m.CircuitShortName,
+		StartTime:  startTime,
+		Complete:   complete,
+		Lat:        lat,
+		Lon:        lon,
+	}, true
+}
+
+// raceName names a meeting's race, e.g. "Abu Dhabi Grand Prix".
+func raceName(m Meeting) string {
+	name := m.MeetingName
+	if !strings.Contains(strings.ToLower(name), "grand prix") {
+		name += " Grand Prix"
+	}
+	return name
+}
 
-		endTime, _ := time.Parse(time.RFC3339, sess.DateEnd)
-		complete := !endTime.IsZero() && endTime.Before(now)
+// lastSession returns the race session in sessions that ended most
+// recently before now, or nil if none has.
+func lastSession(sessions []Session, now time.Time) *Session {
+	var last *Session
+	var lastEnd time.Time
+	for i := range sessions {
+		end, err := time.Parse(time.RFC3339, sessions[i].DateEnd)
+		if err != nil || end.After(now) {
+			continue
+		}
+		if last == nil || end.After(lastEnd) {
+			last, lastEnd = &sessions[i], end
+		}
+	}
+	return last
+}
 
-		raceName := m.MeetingName
-		if !strings.Contains(strings.ToLower(raceName), "grand prix") {
-			raceName += " Grand Prix"
+// LastResult returns the classification of the most recent race, looking
+// back into last season early in the year. It returns nil if no race has
+// finished or its results aren't published yet.
+func (s *F1Series) LastResult(ctx context.Context, now time.Time) (*series.Result, error) {
+	var sess *Session
+	for _, year := range []int{now.Year(), now.Year() - 1} {
+		sessions, err := FetchRaceSessions(ctx, year)
+		if err != nil {
+			return nil, fmt.Errorf("f1 race sessions: %w", err)
+		}
+		if sess = lastSession(sessions, now); sess != nil {
+			break
 		}
+	}
+	if sess == nil {
+		return nil, nil
+	}
+	results, err := cachedFetchSessionResult(ctx, sess)
+	if err != nil {
+		return nil, fmt.Errorf("f1 session result: %w", err)
+	}
+	drivers, _ := cachedFetchDrivers(ctx, sess)
+	start, _ := time.Parse(time.RFC3339, sess.DateStart)
+	end, _ := time.Parse(time.RFC3339, sess.DateEnd)
+	meetings, _ := FetchMeetings(ctx, start.Year())
 
-		lat, lon, _ := CircuitCoords(m.Location)
+	res := &series.Result{
+		SeriesName: s.Name(),
+		ShortName:  s.ShortName(),
+		RaceName:   sess.CircuitShortName,
+		TrackName:  sess.CircuitShortName,
+		StartTime:  start,
+		EndTime:    end,
+	}
+	for _, m := range meetings {
+		if m.MeetingKey == sess.MeetingKey {
+			res.RaceName = raceName(m)
+		}
+	}
+	res.Positions = classification(results, drivers)
+	if len(res.Positions) == 0 || res.Positions[0].Position != 1 {
+		return nil, nil
+	}
+	res.Winner = res.Positions[0]
+	return res, nil
+}
 
-		races = append(races, series.Race{
-			SeriesName: s.Name(),
-			ShortName:  s.ShortName(),
-			RaceName:   raceName,
-			TrackName:  m.CircuitShortName,
-			StartTime:  startTime,
-			Complete:   complete,
-			Lat:        lat,
-			Lon:        lon,
+// classification orders results by finishing position, with drivers who
+// weren't classified (Position 0) last.
+func classification(results []SessionResult, drivers []DriverInfo) []series.Driver {
+	driverMap := make(map[int]DriverInfo, len(drivers))
+	for _, d := range drivers {
+		driverMap[d.DriverNumber] = d
+	}
+	out := make([]series.Driver, 0, len(results))
+	for _, r := range results {
+		d := driverMap[r.DriverNumber]
+		out = append(out, series.Driver{
+			Number:   fmt.Sprintf("%d", r.DriverNumber),
+			Name:     d.NameAcronym,
+			FullName: d.FullName,
+			Team:     d.TeamName,
+			Position: r.Position,
+			Points:   r.Points,
 		})
 	}
-	return races, nil
+	sort.SliceStable(out, func(i, j int) bool {
+		pi, pj := out[i].Position, out[j].Position
+		if pi == 0 || pj == 0 {
+			return pj == 0 && pi != 0
+		}
+		return pi < pj
+	})
+	return out
 }
 
 // timeNow is a seam for testing time-dependent behavior.
 var timeNow = time.Now
 
-func (s *F1Series) FetchLiveState() (*series.LiveState, error) {
-	sess, err := FetchLatestSession()
+func (s *F1Series) FetchLiveState(ctx context.Context) (*series.LiveState, error) {
+	sess, err := FetchLatestSession(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -92,25 +242,26 @@ func (s *F1Series) FetchLiveState() (*series.LiveState, error) {
 		return nil, nil
 	}
 
-	positions, err := cachedFetchPositions(sess)
+	positions, err := cachedFetchPositions(ctx, sess)
 	if err != nil {
 		return nil, err
 	}
-	drivers, err := cachedFetchDrivers(sess)
+	drivers, err := cachedFetchDrivers(ctx, sess)
 	if err != nil {
 		return nil, err
 	}
-	rcMsgs, err := cachedFetchRaceControl(sess)
+	rcMsgs, err := cachedFetchRaceControl(ctx, sess)
 	if err != nil {
 		return nil, err
 	}
-	stints, _ := cachedFetchStints(sess)
+	stints, _ := cachedFetchStints(ctx, sess)
+	intervals, _ := cachedFetchIntervals(ctx, sess)
 
-	// Build map of current tire compound per driver.
-	// Stints arrive in order; last entry per driver is the current stint.
-	compoundByDriver := make(map[int]string)
+	// Stints arrive in order; last entry per driver is the current stint,
+	// giving the tire compound and the pit stops made.
+	stintByDriver := make(map[int]Stint)
 	for _, st := range stints {
-		compoundByDriver[st.DriverNumber] = st.Compound
+		stintByDriver[st.DriverNumber] = st
 	}
 
 	driverMap := make(map[int]DriverInfo, len(drivers))
@@ -118,34 +269,37 @@ func (s *F1Series) FetchLiveState() (*series.LiveState, error) {
 		driverMap[d.DriverNumber] = d
 	}
 
-	// Deduplicate positions: keep only the latest entry per driver.
-	latestPos := make(map[int]Position)
-	for _, p := range positions {
-		if existing, ok := latestPos[p.DriverNumber]; !ok || p.Date > existing.Date {
-			latestPos[p.DriverNumber] = p
-		}
-	}
-
-	// Build sorted driver list.
-	var sorted []Position
-	for _, p := range latestPos {
-		sorted = append(sorted, p)
+	intervalByDriver := make(map[int]Interval, len(intervals))
+	for _, iv := range intervals {
+		intervalByDriver[iv.DriverNumber] = iv
 	}
-	sort.Slice(sorted, func(i, j int) bool {
-		return sorted[i].Position < sorted[j].Position
-	})
 
 	var driverList []series.Driver
-	for _, p := range sorted {
+	for _, p := range LatestPositions(positions) {
 		d := driverMap[p.DriverNumber]
-		driverList = append(driverList, series.Driver{
+		iv := intervalByDriver[p.DriverNumber]
+		st := stintByDriver[p.DriverNumber]
+		driver := series.Driver{
 			Number:   fmt.Sprintf("%d", p.DriverNumber),
 			Name:     d.NameAcronym,
 			FullName: d.FullName,
 			Team:     d.TeamName,
 			Position: p.Position,
-			Compound: compoundByDriver[p.DriverNumber],
-		})
+			Gap:      iv.GapToLeader.String(),
+			GapAhead: iv.Interval.String(),
+			LapsDown: iv.GapToLeader.Laps,
+			Compound: st.Compound,
+		}
+		if st.StintNumber > 1 {
+			// The pit stop was on the lap before the stint began.
+			driver.PitStops, driver.LastPitLap = st.StintNumber-1, max(st.LapStart-1, 0)
+		}
+		driverList = append(driverList, driver)
+	}
+	// Each interval is to the car ahead, so the gap behind a driver is the
+	// next driver's interval.
+	for i := 0; i+1 < len(driverList); i++ {
+		driverList[i].GapBehind = driverList[i+1].GapAhead
 	}
 
 	// Determine flag state from last race control flag message.
@@ -1,12 +1,18 @@
 package f1
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+	"github.com/jfmyers/tmux-raceday/internal/mockserver"
+	"github.com/jfmyers/tmux-raceday/internal/mockserver/mockservertest"
 	"github.com/jfmyers/tmux-raceday/internal/series"
 )
 
@@ -105,15 +111,18 @@ func TestFetchLiveState_GracePeriod(t *testing.T) {
 
 			origBase := baseURL
 			origTimeNow := timeNow
+			origCache := fileCache
 			baseURL = srv.URL + "/v1"
 			timeNow = func() time.Time { return tt.now }
+			fileCache = cache.NewDir(t.TempDir())
 			defer func() {
 				baseURL = origBase
 				timeNow = origTimeNow
+				fileCache = origCache
 			}()
 
 			s := NewSeries()
-			state, err := s.FetchLiveState()
+			state, err := s.FetchLiveState(context.Background())
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -153,7 +162,7 @@ func TestFetchLiveState_NotRaceSession(t *testing.T) {
 	defer func() { baseURL = origBase }()
 
 	s := NewSeries()
-	state, err := s.FetchLiveState()
+	state, err := s.FetchLiveState(context.Background())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -161,3 +170,79 @@ func TestFetchLiveState_NotRaceSession(t *testing.T) {
 		t.Errorf("expected nil for non-race session, got %+v", state)
 	}
 }
+
+func TestFetchLiveState_Gaps(t *testing.T) {
+	ts := mockservertest.Start(t, mockserver.Options{})
+	origBase, origCache, origTimeNow := baseURL, fileCache, timeNow
+	SetBaseURL(ts.Endpoints().OpenF1)
+	fileCache = cache.NewDir(t.TempDir())
+	timeNow = func() time.Time { return time.Date(2026, 12, 6, 17, 30, 0, 0, time.UTC) }
+	defer func() { baseURL, fileCache, timeNow = origBase, origCache, origTimeNow }()
+
+	state, err := NewSeries().FetchLiveState(context.Background())
+	if err != nil || state == nil {
+		t.Fatalf("FetchLiveState = %v, %v", state, err)
+	}
+	var got []string
+	for _, d := range state.Positions {
+		got = append(got, fmt.Sprintf("%s:%s/%s/%s/%d", d.Number, d.Gap, d.GapAhead, d.GapBehind, d.LapsDown))
+	}
+	want := "1://+2.116/0 4:+2.116/+2.116/+7.755/0 81:+9.871/+7.755/+31.402/0 16:+1 LAP/+31.402//1"
+	if strings.Join(got, " ") != want {
+		t.Errorf("gaps = %q\nwant   %q", strings.Join(got, " "), want)
+	}
+	if d := state.Positions[1]; d.PitStops != 1 || d.LastPitLap != 17 || d.Compound != "HARD" {
+		t.Errorf("#4 stints: %d stops, last on lap %d, on %s", d.PitStops, d.LastPitLap, d.Compound)
+	}
+}
+
+func TestLastResult(t *testing.T) {
+	ts := mockservertest.Start(t, mockserver.Options{})
+	origBase, origCache := baseURL, fileCache
+	SetBaseURL(ts.Endpoints().OpenF1)
+	fileCache = cache.NewDir(t.TempDir())
+	defer func() { baseURL, fileCache = origBase, origCache }()
+
+	now := time.Date(2026, 12, 7, 12, 0, 0, 0, time.UTC)
+	res, err := NewSeries().LastResult(context.Background(), now)
+	if err != nil || res == nil {
+		t.Fatalf("LastResult = %v, %v", res, err)
+	}
+	if res.RaceName != "Abu Dhabi Grand Prix" || res.Winner.Number != "1" || res.Winner.Points != 25 {
+		t.Errorf("result = %s won by %+v", res.RaceName, res.Winner)
+	}
+	if want := time.Date(2026, 12, 6, 18, 0, 0, 0, time.UTC); !res.EndTime.Equal(want) {
+		t.Errorf("EndTime = %v, want %v", res.EndTime, want)
+	}
+	var got []string
+	for _, d := range res.Positions {
+		got = append(got, fmt.Sprintf("%s:P%d", d.Name, d.Position))
+	}
+	if want := "VER:P1 NOR:P2 PIA:P3 LEC:P0"; strings.Join(got, " ") != want {
+		t.Errorf("classification = %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestFetchSessions(t *testing.T) {
+	ts := mockservertest.Start(t, mockserver.Options{})
+	origBase, origCache := baseURL, fileCache
+	SetBaseURL(ts.Endpoints().OpenF1)
+	fileCache = cache.NewDir(t.TempDir())
+	defer func() { baseURL, fileCache = origBase, origCache }()
+
+	sessions, err := NewSeries().FetchSessions(context.Background(), 2026)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range sessions {
+		got = append(got, fmt.Sprintf("%s %s %s", s.Race.RaceName, s.Name, s.StartTime.Format(time.RFC3339)))
+	}
+	if want := "Abu Dhabi Grand Prix Qualifying 2026-12-05T14:00:00Z"; len(got) != 4 || got[2] != want {
+		t.Fatalf("sessions = %q, want %q third", got, want)
+	}
+	// Qualifying belongs to the weekend's race.
+	if r := sessions[2].Race; !r.StartTime.Equal(time.Date(2026, 12, 6, 16, 0, 0, 0, time.UTC)) || r.TrackName != "Yas Marina Circuit" {
+		t.Errorf("race = %+v", r)
+	}
+}
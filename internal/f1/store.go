@@ -0,0 +1,167 @@
+package f1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// record is an OpenF1 entry that can be appended to a sessionLog.
+type record interface {
+	timestamp() string // ISO-8601 "date" field, used for the date> filter
+	identity() string  // distinguishes entries that share a timestamp
+}
+
+func (p Position) timestamp() string { return p.Date }
+func (p Position) identity() string  { return fmt.Sprintf("%s/%d", p.Date, p.DriverNumber) }
+
+func (iv Interval) timestamp() string { return iv.Date }
+func (iv Interval) identity() string  { return fmt.Sprintf("%s/%d", iv.Date, iv.DriverNumber) }
+func (iv Interval) latestKey() string { return fmt.Sprint(iv.DriverNumber) }
+
+// latest is a record whose newer entries replace older ones with the same
+// key, so the log keeps only the most recent of each.
+type latest interface {
+	latestKey() string
+}
+
+func (m RaceControlMessage) timestamp() string { return m.Date }
+func (m RaceControlMessage) identity() string {
+	return fmt.Sprintf("%s/%s/%s/%d/%s", m.Date, m.Category, m.Flag, m.LapNumber, m.Message)
+}
+
+// sessionLog is the persisted, chronologically ordered history of one
+// kind of record for a session. Each refresh asks OpenF1 only for entries
+// newer than Last and appends them, instead of re-downloading the whole
+// session every poll.
+type sessionLog[T record] struct {
+	Records []T    `json:"records"`
+	Last    string `json:"last"`
+}
+
+// merge appends records not already in the log and keeps it sorted.
+func (l *sessionLog[T]) merge(recs []T) {
+	seen := make(map[string]bool, len(l.Records))
+	for _, r := range l.Records {
+		seen[r.identity()] = true
+	}
+	for _, r := range recs {
+		if seen[r.identity()] {
+			continue
+		}
+		seen[r.identity()] = true
+		l.Records = append(l.Records, r)
+	}
+	sort.SliceStable(l.Records, func(i, j int) bool {
+		return l.Records[i].timestamp() < l.Records[j].timestamp()
+	})
+	l.compact()
+	if n := len(l.Records); n > 0 {
+		l.Last = l.Records[n-1].timestamp()
+	}
+}
+
+// compact drops records superseded by a newer one with the same key, for
+// record types that implement latest.
+func (l *sessionLog[T]) compact() {
+	newest := make(map[string]int)
+	for i, r := range l.Records {
+		k, ok := any(r).(latest)
+		if !ok {
+			return
+		}
+		newest[k.latestKey()] = i
+	}
+	kept := l.Records[:0]
+	for i, r := range l.Records {
+		if newest[any(r).(latest).latestKey()] == i {
+			kept = append(kept, r)
+		}
+	}
+	l.Records = kept
+}
+
+// syncLog extends the session log stored at cacheKey with records newer
+// than its last entry. Logs younger than ttl are returned as-is; a ttl of 0
+// always syncs. On API failure the stored log is returned unchanged.
+func syncLog[T record](ctx context.Context, cacheKey string, ttl time.Duration, fetchSince func(since string) ([]T, error)) ([]T, error) {
+	if ttl > 0 {
+		if data, ok := fileCache.Read(cacheKey, ttl); ok {
+			var l sessionLog[T]
+			if json.Unmarshal(data, &l) == nil {
+				return l.Records, nil
+			}
+		}
+	}
+
+	data, err := fileCache.Refresh(ctx, cacheKey, ttl, func() ([]byte, error) {
+		// Re-read under the lock so concurrent processes append to the
+		// same log rather than overwriting each other's additions.
+		var l sessionLog[T]
+		if prev, _ := fileCache.ReadStale(cacheKey, ttl); prev != nil {
+			_ = json.Unmarshal(prev, &l)
+		}
+		recs, err := fetchSince(l.Last)
+		if err != nil {
+			return nil, err
+		}
+		l.merge(recs)
+		return json.Marshal(l)
+	})
+	if err != nil {
+		// Fall back to the stored log on API failure.
+		stale := fileCache.Fallback(ctx, cacheKey)
+		if stale == nil {
+			return nil, err
+		}
+		data = stale
+	}
+
+	var l sessionLog[T]
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, err
+	}
+	return l.Records, nil
+}
+
+// sinceFilter returns the OpenF1 query suffix selecting entries newer
+// than since, or "" for the full history.
+func sinceFilter(since string) string {
+	if since == "" {
+		return ""
+	}
+	return "&date>" + url.QueryEscape(since)
+}
+
+// LatestPositions reduces a position history to the most recent entry per
+// driver, ordered by position.
+func LatestPositions(history []Position) []Position {
+	latest := make(map[int]Position)
+	for _, p := range history {
+		if existing, ok := latest[p.DriverNumber]; !ok || p.Date > existing.Date {
+			latest[p.DriverNumber] = p
+		}
+	}
+	out := make([]Position, 0, len(latest))
+	for _, p := range latest {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Position < out[j].Position
+	})
+	return out
+}
+
+// DriverHistory returns one driver's position changes in chronological order.
+func DriverHistory(history []Position, driverNumber int) []Position {
+	var out []Position
+	for _, p := range history {
+		if p.DriverNumber == driverNumber {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,189 @@
+package f1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+)
+
+// positionServer serves /v1/position honouring the date> filter over a
+// growing history, and records the filters it was asked for.
+type positionServer struct {
+	mu      sync.Mutex
+	history []Position
+	queries []string
+}
+
+func (ps *positionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	// OpenF1 filters are written as "date>VALUE", without an "=".
+	var since string
+	for _, part := range strings.Split(r.URL.RawQuery, "&") {
+		if v, ok := strings.CutPrefix(part, "date>"); ok {
+			since, _ = url.QueryUnescape(v)
+		}
+	}
+	ps.queries = append(ps.queries, since)
+	var out []Position
+	for _, p := range ps.history {
+		if since == "" || p.Date > since {
+			out = append(out, p)
+		}
+	}
+	json.NewEncoder(w).Encode(out)
+}
+
+func TestCachedFetchPositionsIsIncremental(t *testing.T) {
+	ps := &positionServer{history: []Position{
+		{DriverNumber: 1, Position: 1, Date: "2026-03-15T14:00:00+00:00"},
+		{DriverNumber: 16, Position: 2, Date: "2026-03-15T14:00:00+00:00"},
+	}}
+	srv := httptest.NewServer(ps)
+	defer srv.Close()
+
+	origBase, origCache := baseURL, fileCache
+	baseURL = srv.URL
+	fileCache = cache.NewDir(t.TempDir())
+	defer func() { baseURL, fileCache = origBase, origCache }()
+
+	// Live session: TTL 0, so every call syncs.
+	sess := &Session{SessionKey: 42, DateStart: time.Now().Add(-time.Hour).Format(time.RFC3339)}
+	ctx := context.Background()
+
+	got, err := cachedFetchPositions(ctx, sess)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("first sync = %v, %v", got, err)
+	}
+
+	ps.mu.Lock()
+	ps.history = append(ps.history, Position{DriverNumber: 16, Position: 1, Date: "2026-03-15T14:05:00.250000+00:00"},
+		Position{DriverNumber: 1, Position: 2, Date: "2026-03-15T14:05:00.250000+00:00"})
+	ps.mu.Unlock()
+
+	got, err = cachedFetchPositions(ctx, sess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("merged history has %d entries, want 4", len(got))
+	}
+
+	ps.mu.Lock()
+	queries := append([]string(nil), ps.queries...)
+	ps.mu.Unlock()
+	if len(queries) != 2 || queries[0] != "" || queries[1] != "2026-03-15T14:00:00+00:00" {
+		t.Errorf("date> filters = %q, want full fetch then incremental", queries)
+	}
+
+	latest := LatestPositions(got)
+	if len(latest) != 2 || latest[0].DriverNumber != 16 || latest[1].DriverNumber != 1 {
+		t.Errorf("LatestPositions = %+v", latest)
+	}
+	if h := DriverHistory(got, 1); len(h) != 2 || h[0].Position != 1 || h[1].Position != 2 {
+		t.Errorf("DriverHistory(1) = %+v", h)
+	}
+}
+
+func TestSessionLogMergeDeduplicates(t *testing.T) {
+	var l sessionLog[RaceControlMessage]
+	l.merge([]RaceControlMessage{
+		{Date: "2026-03-15T14:10:00+00:00", Category: "Flag", Flag: "YELLOW", Message: "YELLOW IN SECTOR 2"},
+		{Date: "2026-03-15T14:00:00+00:00", Category: "Flag", Flag: "GREEN", Message: "GREEN LIGHT"},
+	})
+	l.merge([]RaceControlMessage{
+		{Date: "2026-03-15T14:10:00+00:00", Category: "Flag", Flag: "YELLOW", Message: "YELLOW IN SECTOR 2"},
+		{Date: "2026-03-15T14:12:00+00:00", Category: "Flag", Flag: "CLEAR", Message: "CLEAR IN SECTOR 2"},
+	})
+
+	if len(l.Records) != 3 {
+		t.Fatalf("records = %d, want 3", len(l.Records))
+	}
+	if l.Records[0].Flag != "GREEN" || l.Records[2].Flag != "CLEAR" {
+		t.Errorf("records not in chronological order: %+v", l.Records)
+	}
+	if l.Last != "2026-03-15T14:12:00+00:00" {
+		t.Errorf("Last = %q", l.Last)
+	}
+}
+
+func TestSyncLogFallsBackToStoredLog(t *testing.T) {
+	origCache := fileCache
+	fileCache = cache.NewDir(t.TempDir())
+	defer func() { fileCache = origCache }()
+
+	ctx := context.Background()
+	first := []Position{{DriverNumber: 1, Position: 1, Date: "2026-03-15T14:00:00+00:00"}}
+	if _, err := syncLog(ctx, "log.json", 0, func(string) ([]Position, error) { return first, nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := syncLog(ctx, "log.json", 0, func(string) ([]Position, error) {
+		return nil, context.DeadlineExceeded
+	})
+	if err != nil || len(got) != 1 {
+		t.Errorf("fallback = %v, %v; want stored log", got, err)
+	}
+}
+
+func TestSessionLogKeepsLatestInterval(t *testing.T) {
+	var l sessionLog[Interval]
+	l.merge([]Interval{
+		{DriverNumber: 1, GapToLeader: Gap{Valid: true, Seconds: 0.4}, Date: "2026-03-15T14:00:00+00:00"},
+		{DriverNumber: 4, Date: "2026-03-15T14:00:00+00:00"},
+	})
+	l.merge([]Interval{
+		{DriverNumber: 1, GapToLeader: Gap{Valid: true, Laps: 1}, Date: "2026-03-15T14:00:04+00:00"},
+	})
+
+	if len(l.Records) != 2 {
+		t.Fatalf("records = %+v, want one per driver", l.Records)
+	}
+	if got := l.Records[1]; got.DriverNumber != 1 || got.GapToLeader.String() != "+1 LAP" {
+		t.Errorf("driver 1 = %+v, want the newer interval", got)
+	}
+	if l.Last != "2026-03-15T14:00:04+00:00" {
+		t.Errorf("Last = %q", l.Last)
+	}
+}
+
+func TestGapJSON(t *testing.T) {
+	var ivs []Interval
+	data := `[{"driver_number":1,"gap_to_leader":null,"interval":null},
+		{"driver_number":4,"gap_to_leader":1.5,"interval":1.5},
+		{"driver_number":16,"gap_to_leader":"+2 LAPS","interval":"+1 LAP"}]`
+	if err := json.Unmarshal([]byte(data), &ivs); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, iv := range ivs {
+		got = append(got, iv.GapToLeader.String()+"/"+iv.Interval.String())
+	}
+	if want := "/ +1.500/+1.500 +2 LAPS/+1 LAP"; strings.Join(got, " ") != want {
+		t.Errorf("gaps = %q, want %q", strings.Join(got, " "), want)
+	}
+
+	// Gaps survive the round trip through the cache.
+	out, err := json.Marshal(ivs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back []Interval
+	if err := json.Unmarshal(out, &back); err != nil || !reflect.DeepEqual(back, ivs) {
+		t.Errorf("round trip = %+v, %v; want %+v", back, err, ivs)
+	}
+
+	var g Gap
+	if err := json.Unmarshal([]byte(`"DNF"`), &g); err == nil {
+		t.Error(`"DNF" decoded without error`)
+	}
+}
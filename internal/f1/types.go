@@ -1,5 +1,12 @@
 package f1
 
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jfmyers/tmux-raceday/internal/series"
+)
+
 // Meeting represents an F1 race weekend from the OpenF1 API.
 type Meeting struct {
 	MeetingKey       int    `json:"meeting_key"`
@@ -33,12 +40,78 @@ type Position struct {
 	Date         string `json:"date"`
 }
 
+// Interval is a driver's timing gaps at a point in time.
+type Interval struct {
+	DriverNumber int    `json:"driver_number"`
+	GapToLeader  Gap    `json:"gap_to_leader"`
+	Interval     Gap    `json:"interval"` // to the car ahead
+	Date         string `json:"date"`
+}
+
+// SessionResult is a driver's classification at the end of a session.
+type SessionResult struct {
+	DriverNumber int     `json:"driver_number"`
+	Position     int     `json:"position"` // 0 if not classified
+	Points       float64 `json:"points"`
+	NumberOfLaps int     `json:"number_of_laps"`
+	DNF          bool    `json:"dnf"`
+	DNS          bool    `json:"dns"`
+	DSQ          bool    `json:"dsq"`
+}
+
+// Gap is an OpenF1 timing gap: seconds, or a lapped string such as
+// "+1 LAP". It is null for the leader and when timing has no value.
+type Gap struct {
+	Seconds float64
+	Laps    int
+	Valid   bool
+}
+
+func (g *Gap) UnmarshalJSON(data []byte) error {
+	*g = Gap{}
+	if string(data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(data, &g.Seconds); err == nil {
+		g.Valid = true
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("gap: %s is neither seconds nor laps", data)
+	}
+	if _, err := fmt.Sscanf(s, "+%d LAP", &g.Laps); err != nil || g.Laps < 1 {
+		return fmt.Errorf("gap: unrecognised %q", s)
+	}
+	g.Valid = true
+	return nil
+}
+
+func (g Gap) MarshalJSON() ([]byte, error) {
+	switch {
+	case !g.Valid:
+		return []byte("null"), nil
+	case g.Laps > 0:
+		return json.Marshal(g.String())
+	}
+	return json.Marshal(g.Seconds)
+}
+
+// String formats g as series.FormatGap does, or "" if it has no value.
+func (g Gap) String() string {
+	if !g.Valid {
+		return ""
+	}
+	return series.FormatGap(g.Seconds, g.Laps)
+}
+
 // RaceControlMessage represents a race control event (flags, etc).
 type RaceControlMessage struct {
 	Category  string `json:"category"`
 	Flag      string `json:"flag"`
 	Message   string `json:"message"`
 	LapNumber int    `json:"lap_number"`
+	Date      string `json:"date"`
 }
 
 // Stint represents a driver's tire stint in a session.
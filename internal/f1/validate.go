@@ -0,0 +1,209 @@
+package f1
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/schema"
+)
+
+// Feed names used in schema reports.
+const (
+	FeedMeetings      = "openf1 meetings"
+	FeedSessions      = "openf1 sessions"
+	FeedPositions     = "openf1 position"
+	FeedRaceControl   = "openf1 race_control"
+	FeedStints        = "openf1 stints"
+	FeedDrivers       = "openf1 drivers"
+	FeedIntervals     = "openf1 intervals"
+	FeedSessionResult = "openf1 session_result"
+)
+
+// validators maps each feed to its validator; fetchJSON runs them on
+// every payload fetched.
+var validators = map[string]func([]byte) error{
+	FeedMeetings:      ValidateMeetings,
+	FeedSessions:      ValidateSessions,
+	FeedPositions:     ValidatePositions,
+	FeedRaceControl:   ValidateRaceControl,
+	FeedStints:        ValidateStints,
+	FeedDrivers:       ValidateDrivers,
+	FeedIntervals:     ValidateIntervals,
+	FeedSessionResult: ValidateSessionResult,
+}
+
+// ValidateMeetings checks a /meetings payload.
+func ValidateMeetings(data []byte) error {
+	c, _ := schema.Records(data, "meeting_key", "meeting_name", "location",
+		"country_name", "circuit_short_name", "date_start", "date_end", "year")
+	var meetings []Meeting
+	if err := json.Unmarshal(data, &meetings); err != nil {
+		c.Addf("", "decoding: %v", err)
+		return c.Err(FeedMeetings)
+	}
+	for i, m := range meetings {
+		path := fmt.Sprintf("[%d]", i)
+		c.NonEmpty(path+".meeting_name", m.MeetingName)
+		checkDate(c, path+".date_start", m.DateStart)
+	}
+	return c.Err(FeedMeetings)
+}
+
+// ValidateSessions checks a /sessions payload.
+func ValidateSessions(data []byte) error {
+	c, _ := schema.Records(data, "session_key", "session_type", "session_name",
+		"date_start", "date_end", "circuit_short_name", "location", "meeting_key")
+	var sessions []Session
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		c.Addf("", "decoding: %v", err)
+		return c.Err(FeedSessions)
+	}
+	for i, s := range sessions {
+		path := fmt.Sprintf("[%d]", i)
+		if s.SessionKey <= 0 {
+			c.Addf(path+".session_key", "not set")
+		}
+		checkDate(c, path+".date_start", s.DateStart)
+		checkDate(c, path+".date_end", s.DateEnd)
+	}
+	return c.Err(FeedSessions)
+}
+
+// ValidatePositions checks a /position payload.
+func ValidatePositions(data []byte) error {
+	c, _ := schema.Records(data, "driver_number", "position", "date")
+	var positions []Position
+	if err := json.Unmarshal(data, &positions); err != nil {
+		c.Addf("", "decoding: %v", err)
+		return c.Err(FeedPositions)
+	}
+	for i, p := range positions {
+		path := fmt.Sprintf("[%d]", i)
+		c.Range(path+".driver_number", p.DriverNumber, 1, 99)
+		c.Range(path+".position", p.Position, 1, 30)
+		checkDate(c, path+".date", p.Date)
+	}
+	return c.Err(FeedPositions)
+}
+
+// ValidateRaceControl checks a /race_control payload.
+func ValidateRaceControl(data []byte) error {
+	c, _ := schema.Records(data, "category", "flag", "message", "lap_number", "date")
+	var msgs []RaceControlMessage
+	if err := json.Unmarshal(data, &msgs); err != nil {
+		c.Addf("", "decoding: %v", err)
+		return c.Err(FeedRaceControl)
+	}
+	for i, m := range msgs {
+		path := fmt.Sprintf("[%d]", i)
+		c.NonEmpty(path+".category", m.Category)
+		c.Range(path+".lap_number", m.LapNumber, 0, 100)
+		checkDate(c, path+".date", m.Date)
+	}
+	return c.Err(FeedRaceControl)
+}
+
+// knownCompounds are the tyre compounds OpenF1 reports.
+var knownCompounds = map[string]bool{
+	"SOFT": true, "MEDIUM": true, "HARD": true,
+	"INTERMEDIATE": true, "WET": true, "UNKNOWN": true, "TEST_UNKNOWN": true,
+}
+
+// ValidateStints checks a /stints payload.
+func ValidateStints(data []byte) error {
+	c, _ := schema.Records(data, "driver_number", "stint_number", "compound", "lap_start", "lap_end")
+	var stints []Stint
+	if err := json.Unmarshal(data, &stints); err != nil {
+		c.Addf("", "decoding: %v", err)
+		return c.Err(FeedStints)
+	}
+	for i, s := range stints {
+		path := fmt.Sprintf("[%d]", i)
+		c.Range(path+".driver_number", s.DriverNumber, 1, 99)
+		if s.Compound != "" && !knownCompounds[s.Compound] {
+			c.Addf(path+".compound", "unknown compound %q", s.Compound)
+		}
+	}
+	return c.Err(FeedStints)
+}
+
+// ValidateDrivers checks a /drivers payload.
+func ValidateDrivers(data []byte) error {
+	c, _ := schema.Records(data, "driver_number", "full_name", "last_name", "team_name", "name_acronym")
+	var drivers []DriverInfo
+	if err := json.Unmarshal(data, &drivers); err != nil {
+		c.Addf("", "decoding: %v", err)
+		return c.Err(FeedDrivers)
+	}
+	for i, d := range drivers {
+		path := fmt.Sprintf("[%d]", i)
+		c.Range(path+".driver_number", d.DriverNumber, 1, 99)
+		c.NonEmpty(path+".last_name", d.LastName)
+	}
+	return c.Err(FeedDrivers)
+}
+
+// ValidateIntervals checks an /intervals payload.
+func ValidateIntervals(data []byte) error {
+	c, _ := schema.Records(data, "driver_number", "gap_to_leader", "interval", "date")
+	var intervals []Interval
+	if err := json.Unmarshal(data, &intervals); err != nil {
+		c.Addf("", "decoding: %v", err)
+		return c.Err(FeedIntervals)
+	}
+	for i, iv := range intervals {
+		path := fmt.Sprintf("[%d]", i)
+		c.Range(path+".driver_number", iv.DriverNumber, 1, 99)
+		if iv.Interval.Valid && iv.Interval.Laps == 0 && iv.Interval.Seconds < 0 {
+			c.Addf(path+".interval", "negative gap %v", iv.Interval.Seconds)
+		}
+		checkDate(c, path+".date", iv.Date)
+	}
+	return c.Err(FeedIntervals)
+}
+
+// ValidateSessionResult checks a /session_result payload.
+func ValidateSessionResult(data []byte) error {
+	c, n := schema.Records(data, "driver_number", "position", "points")
+	var results []SessionResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		c.Addf("", "decoding: %v", err)
+		return c.Err(FeedSessionResult)
+	}
+	for i, r := range results {
+		path := fmt.Sprintf("[%d]", i)
+		c.Range(path+".driver_number", r.DriverNumber, 1, 99)
+		c.Range(path+".position", r.Position, 0, n)
+		if r.Points < 0 || r.Points > 50 {
+			c.Addf(path+".points", "%v out of range [0, 50]", r.Points)
+		}
+	}
+	return c.Err(FeedSessionResult)
+}
+
+func checkDate(c *schema.Checker, path, s string) {
+	if s == "" {
+		return
+	}
+	if _, err := time.Parse(time.RFC3339, s); err != nil {
+		c.Addf(path, "unparseable date %q", s)
+	}
+}
+
+// FeedChecks lists the OpenF1 feeds with their validators, for
+// diagnostics that fetch them directly. Session feeds use the latest
+// session.
+func FeedChecks(now time.Time) []schema.Check {
+	year := now.Year()
+	return []schema.Check{
+		{Feed: FeedMeetings, URL: fmt.Sprintf("%s/meetings?year=%d", baseURL, year), Validate: ValidateMeetings},
+		{Feed: FeedSessions, URL: fmt.Sprintf("%s/sessions?year=%d&session_name=Race", baseURL, year), Validate: ValidateSessions},
+		{Feed: FeedPositions, URL: baseURL + "/position?session_key=latest", Validate: ValidatePositions},
+		{Feed: FeedRaceControl, URL: baseURL + "/race_control?session_key=latest", Validate: ValidateRaceControl},
+		{Feed: FeedStints, URL: baseURL + "/stints?session_key=latest", Validate: ValidateStints},
+		{Feed: FeedDrivers, URL: baseURL + "/drivers?session_key=latest", Validate: ValidateDrivers},
+		{Feed: FeedIntervals, URL: baseURL + "/intervals?session_key=latest", Validate: ValidateIntervals},
+		{Feed: FeedSessionResult, URL: baseURL + "/session_result?session_key=latest", Validate: ValidateSessionResult},
+	}
+}
@@ -0,0 +1,45 @@
+package f1
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/mockserver"
+	"github.com/jfmyers/tmux-raceday/internal/mockserver/mockservertest"
+)
+
+func TestValidators(t *testing.T) {
+	ts := mockservertest.Start(t, mockserver.Options{})
+	origBase, origCache := baseURL, fileCache
+	SetBaseURL(ts.Endpoints().OpenF1)
+	defer func() { baseURL, fileCache = origBase, origCache }()
+	now := time.Date(2026, 12, 6, 17, 0, 0, 0, time.UTC)
+
+	body := func(url string) []byte {
+		t.Helper()
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return data
+	}
+
+	for _, check := range FeedChecks(now) {
+		if err := check.Validate(body(check.URL)); err != nil {
+			t.Errorf("%s fixture: %v", check.Feed, err)
+		}
+	}
+
+	for _, mode := range []string{mockserver.DriftRename, mockserver.DriftRetype, mockserver.DriftTruncate} {
+		ts.SetDrift(mockserver.AllRoutes, mode)
+		for _, check := range FeedChecks(now) {
+			if err := check.Validate(body(check.URL)); err == nil {
+				t.Errorf("%s with %s drift: no error", check.Feed, mode)
+			}
+		}
+	}
+}
@@ -0,0 +1,437 @@
+// Package fetch is the HTTP layer shared by every data provider. It adds
+// per-host rate limiting, jittered retries and a circuit breaker on top of
+// net/http.
+//
+// Breaker and Retry-After state is persisted in the cache directory so it
+// carries across the short-lived processes tmux spawns for each status
+// refresh: once a host is failing, later refreshes skip it immediately and
+// callers fall back to stale cached data instead of waiting out timeouts.
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+)
+
+// ErrCircuitOpen is returned without making a request while a host's
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// ErrRateLimited is returned when a host asked us (via 429 and
+// Retry-After) to back off for longer than we are willing to wait.
+var ErrRateLimited = errors.New("rate limited")
+
+// ErrOffline is returned without making a request in offline mode.
+var ErrOffline = errors.New("offline mode")
+
+// StatusError reports a non-200 response.
+type StatusError struct {
+	URL  string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned %d", e.URL, e.Code)
+}
+
+// Options configures a Client. Zero values fall back to DefaultOptions,
+// except Retries: zero means no retries.
+type Options struct {
+	Timeout          time.Duration            // per attempt
+	Retries          int                      // extra attempts after the first; negative is 0
+	Backoff          time.Duration            // base delay, doubled per retry and jittered
+	MaxRetryAfter    time.Duration            // longest Retry-After we wait out in-process
+	BreakerThreshold int                      // consecutive failures that open the breaker
+	BreakerCooldown  time.Duration            // how long the breaker stays open
+	RateLimits       map[string]time.Duration // host → minimum interval between requests
+	Offline          bool                     // fail every request with ErrOffline
+}
+
+// DefaultOptions returns the settings used when nothing is configured.
+func DefaultOptions() Options {
+	return Options{
+		Timeout:          10 * time.Second,
+		Retries:          2,
+		Backoff:          250 * time.Millisecond,
+		MaxRetryAfter:    2 * time.Second,
+		BreakerThreshold: 3,
+		BreakerCooldown:  2 * time.Minute,
+		RateLimits: map[string]time.Duration{
+			// OpenF1's free tier allows 3 requests per second.
+			"api.openf1.org": 350 * time.Millisecond,
+		},
+	}
+}
+
+func (o Options) withDefaults() Options {
+	d := DefaultOptions()
+	if o.Timeout <= 0 {
+		o.Timeout = d.Timeout
+	}
+	if o.Retries < 0 {
+		o.Retries = 0
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = d.Backoff
+	}
+	if o.MaxRetryAfter <= 0 {
+		o.MaxRetryAfter = d.MaxRetryAfter
+	}
+	if o.BreakerThreshold <= 0 {
+		o.BreakerThreshold = d.BreakerThreshold
+	}
+	if o.BreakerCooldown <= 0 {
+		o.BreakerCooldown = d.BreakerCooldown
+	}
+	limits := make(map[string]time.Duration, len(d.RateLimits)+len(o.RateLimits))
+	for h, iv := range d.RateLimits {
+		limits[h] = iv
+	}
+	for h, iv := range o.RateLimits {
+		limits[h] = iv
+	}
+	o.RateLimits = limits
+	return o
+}
+
+// Client performs GET requests with retries, rate limiting and circuit
+// breaking. It is safe for concurrent use.
+type Client struct {
+	http  *http.Client
+	opts  Options
+	state *cache.Cache
+
+	mu       sync.Mutex
+	nextSlot map[string]time.Time // host → earliest time for the next request
+}
+
+// New returns a Client that persists host state under the default cache.
+func New(opts Options) *Client {
+	return newClient(opts, cache.New("fetch"))
+}
+
+func newClient(opts Options, state *cache.Cache) *Client {
+	opts = opts.withDefaults()
+	return &Client{
+		http:     &http.Client{Timeout: opts.Timeout},
+		opts:     opts,
+		state:    state,
+		nextSlot: make(map[string]time.Time),
+	}
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultClient = New(DefaultOptions())
+)
+
+// Configure replaces the client used by the package-level Get.
+func Configure(opts Options) {
+	c := New(opts)
+	defaultMu.Lock()
+	defaultClient = c
+	defaultMu.Unlock()
+}
+
+// Get fetches url with the default client.
+func Get(ctx context.Context, rawURL string) ([]byte, error) {
+	defaultMu.RLock()
+	c := defaultClient
+	defaultMu.RUnlock()
+	return c.Get(ctx, rawURL)
+}
+
+// Probe calls Probe on the default client.
+func Probe(ctx context.Context, rawURL string) (time.Duration, error) {
+	defaultMu.RLock()
+	c := defaultClient
+	defaultMu.RUnlock()
+	return c.Probe(ctx, rawURL)
+}
+
+// Status calls Status on the default client.
+func Status(rawURL string) HostStatus {
+	defaultMu.RLock()
+	c := defaultClient
+	defaultMu.RUnlock()
+	return c.Status(rawURL)
+}
+
+// hostState is the persisted per-host breaker and back-off state.
+type hostState struct {
+	Failures   int       `json:"failures"`
+	OpenUntil  time.Time `json:"open_until,omitzero"`
+	RetryAfter time.Time `json:"retry_after,omitzero"`
+}
+
+// Get fetches url and returns the response body. Network errors, 5xx and
+// 429 responses are retried with jittered exponential backoff; other
+// non-200 responses are returned immediately as a *StatusError.
+//
+// Cancelling ctx aborts the request and any pending wait; a cancelled
+// request does not count against the host's breaker.
+func (c *Client) Get(ctx context.Context, rawURL string) ([]byte, error) {
+	start := time.Now()
+	body, err := c.get(ctx, rawURL)
+	elapsed := time.Since(start)
+	switch {
+	case err == nil:
+		slog.Debug("fetch", "url", rawURL, "duration", elapsed, "bytes", len(body))
+	case errors.Is(err, ErrOffline), errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		slog.Debug("fetch skipped", "url", rawURL, "duration", elapsed, "err", err)
+	default:
+		slog.Warn("fetch failed", "url", rawURL, "duration", elapsed, "err", err)
+	}
+	return body, err
+}
+
+func (c *Client) get(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	host := u.Host
+	if c.opts.Offline {
+		return nil, fmt.Errorf("%s: %w", host, ErrOffline)
+	}
+
+	st := c.loadState(host)
+	now := time.Now()
+	if now.Before(st.OpenUntil) {
+		return nil, fmt.Errorf("%s: %w until %s", host, ErrCircuitOpen, st.OpenUntil.Local().Format("15:04:05"))
+	}
+	if wait := st.RetryAfter.Sub(now); wait > 0 {
+		if wait > c.opts.MaxRetryAfter {
+			return nil, fmt.Errorf("%s: %w for %s", host, ErrRateLimited, wait.Round(time.Second))
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.opts.Retries; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, c.backoff(attempt)); err != nil {
+				return nil, err
+			}
+		}
+		if err := c.waitForSlot(ctx, host); err != nil {
+			return nil, err
+		}
+
+		body, retryAfter, err := c.do(ctx, rawURL)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err == nil {
+			if st.Failures > 0 || !st.OpenUntil.IsZero() || !st.RetryAfter.IsZero() {
+				c.updateState(ctx, host, func(st *hostState) { *st = hostState{} })
+			}
+			return body, nil
+		}
+		lastErr = err
+		slog.Debug("fetch attempt failed", "url", rawURL, "attempt", attempt+1, "err", err)
+
+		if retryAfter > 0 {
+			until := time.Now().Add(retryAfter)
+			c.updateState(ctx, host, func(st *hostState) { st.RetryAfter = until })
+			if retryAfter > c.opts.MaxRetryAfter {
+				return nil, fmt.Errorf("%s: %w for %s", host, ErrRateLimited, retryAfter.Round(time.Second))
+			}
+			if attempt == c.opts.Retries {
+				break // no attempt left to wait for
+			}
+			if err := sleep(ctx, retryAfter); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !retryable(err) {
+			return nil, err
+		}
+	}
+
+	c.updateState(ctx, host, func(st *hostState) {
+		st.Failures++
+		if st.Failures >= c.opts.BreakerThreshold {
+			st.OpenUntil = time.Now().Add(c.opts.BreakerCooldown)
+			slog.Warn("circuit open", "host", host, "failures", st.Failures, "until", st.OpenUntil)
+		}
+	})
+	return nil, lastErr
+}
+
+// Probe makes a single request to url, bypassing retries, rate limits and
+// the breaker, and returns how long it took. It is meant for diagnostics
+// and does not update the host's state.
+func (c *Client) Probe(ctx context.Context, rawURL string) (time.Duration, error) {
+	if c.opts.Offline {
+		return 0, ErrOffline
+	}
+	start := time.Now()
+	_, _, err := c.do(ctx, rawURL)
+	return time.Since(start), err
+}
+
+// HostStatus is the persisted breaker state for a host.
+type HostStatus struct {
+	Failures   int       // consecutive failed requests
+	OpenUntil  time.Time // breaker open (requests skipped) until then
+	RetryAfter time.Time // server asked us to wait until then
+}
+
+// Status returns the breaker state for url's host.
+func (c *Client) Status(rawURL string) HostStatus {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return HostStatus{}
+	}
+	st := c.loadState(u.Host)
+	return HostStatus{Failures: st.Failures, OpenUntil: st.OpenUntil, RetryAfter: st.RetryAfter}
+}
+
+// do performs a single request. A positive duration is returned for 429
+// responses carrying a usable Retry-After header.
+func (c *Client) do(ctx context.Context, rawURL string) ([]byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var wait time.Duration
+		if resp.StatusCode == http.StatusTooManyRequests {
+			wait = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		}
+		return nil, wait, &StatusError{URL: rawURL, Code: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, 0, nil
+}
+
+// retryable reports whether err is worth another attempt: transport
+// failures, 5xx and 429 are; other HTTP statuses mean the host is up and
+// answered definitively.
+func retryable(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Code >= 500 || se.Code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// backoff returns the jittered delay before the given retry attempt:
+// a random duration in [d/2, d) where d = Backoff * 2^(attempt-1).
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.opts.Backoff << (attempt - 1)
+	return d/2 + rand.N(d/2+1)
+}
+
+// waitForSlot blocks until the host's rate limit allows another request.
+func (c *Client) waitForSlot(ctx context.Context, host string) error {
+	interval := c.opts.RateLimits[hostname(host)]
+	if interval <= 0 {
+		return nil
+	}
+	c.mu.Lock()
+	now := time.Now()
+	slot := c.nextSlot[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	c.nextSlot[host] = slot.Add(interval)
+	c.mu.Unlock()
+
+	return sleep(ctx, slot.Sub(now))
+}
+
+// sleep waits for d or until ctx is done, whichever comes first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// parseRetryAfter accepts either delay-seconds or an HTTP date.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+func hostname(host string) string {
+	if h, _, ok := strings.Cut(host, ":"); ok {
+		return h
+	}
+	return host
+}
+
+func stateKey(host string) string {
+	return "host_" + strings.NewReplacer(":", "_", "/", "_").Replace(host) + ".json"
+}
+
+func (c *Client) loadState(host string) hostState {
+	var st hostState
+	if data, _ := c.state.ReadStale(stateKey(host), 0); data != nil {
+		_ = json.Unmarshal(data, &st)
+	}
+	return st
+}
+
+// updateState applies fn to host's persisted state under the state's
+// lock, so status processes failing at once each count their failure.
+func (c *Client) updateState(ctx context.Context, host string, fn func(*hostState)) {
+	err := c.state.Update(ctx, stateKey(host), func(data []byte) ([]byte, error) {
+		var st hostState
+		if data != nil {
+			_ = json.Unmarshal(data, &st)
+		}
+		fn(&st)
+		return json.Marshal(st)
+	})
+	if err != nil {
+		slog.Debug("fetch state not saved", "host", host, "err", err)
+	}
+}
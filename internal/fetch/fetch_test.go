@@ -0,0 +1,315 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+)
+
+func testClient(t *testing.T, opts Options) *Client {
+	t.Helper()
+	if opts.Backoff == 0 {
+		opts.Backoff = time.Millisecond
+	}
+	return newClient(opts, cache.NewDir(t.TempDir()))
+}
+
+// flaky fails the first n requests with status, then serves body.
+func flaky(n int32, status int, body string) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= n {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	return srv, &calls
+}
+
+func TestGetRetriesServerErrors(t *testing.T) {
+	srv, calls := flaky(2, http.StatusBadGateway, "ok")
+	defer srv.Close()
+
+	c := testClient(t, Options{Retries: 2})
+	body, err := c.Get(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if string(body) != "ok" {
+		t.Errorf("body = %q", body)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("calls = %d, want 3", n)
+	}
+}
+
+func TestGetDoesNotRetryClientErrors(t *testing.T) {
+	srv, calls := flaky(5, http.StatusNotFound, "ok")
+	defer srv.Close()
+
+	c := testClient(t, Options{Retries: 3})
+	_, err := c.Get(context.Background(), srv.URL)
+	var se *StatusError
+	if !errors.As(err, &se) || se.Code != http.StatusNotFound {
+		t.Fatalf("err = %v, want 404 StatusError", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("calls = %d, want 1", n)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	srv, calls := flaky(100, http.StatusInternalServerError, "ok")
+	defer srv.Close()
+
+	state := cache.NewDir(t.TempDir())
+	opts := Options{Retries: 0, Backoff: time.Millisecond, BreakerThreshold: 2, BreakerCooldown: time.Hour}
+	c := newClient(opts, state)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Get(context.Background(), srv.URL); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("attempt %d: breaker opened early", i)
+		}
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("calls = %d, want 2", n)
+	}
+
+	// A fresh client (as in a new status process) sees the open breaker.
+	c2 := newClient(opts, state)
+	if _, err := c2.Get(context.Background(), srv.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("err = %v, want ErrCircuitOpen", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("open breaker still made a request (calls = %d)", n)
+	}
+}
+
+func TestCircuitBreakerHalfOpenRecovers(t *testing.T) {
+	srv, _ := flaky(1, http.StatusInternalServerError, "ok")
+	defer srv.Close()
+
+	opts := Options{Retries: 0, Backoff: time.Millisecond, BreakerThreshold: 1, BreakerCooldown: 20 * time.Millisecond}
+	c := testClient(t, opts)
+
+	if _, err := c.Get(context.Background(), srv.URL); err == nil {
+		t.Fatal("first request should fail")
+	}
+	if _, err := c.Get(context.Background(), srv.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, err := c.Get(context.Background(), srv.URL); err != nil {
+		t.Fatalf("trial request after cooldown: %v", err)
+	}
+	u, _ := url.Parse(srv.URL)
+	if st := c.loadState(u.Host); st.Failures != 0 || !st.OpenUntil.IsZero() {
+		t.Errorf("state not reset after success: %+v", st)
+	}
+}
+
+func TestGetHonoursRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	t.Run("short wait is absorbed", func(t *testing.T) {
+		c := testClient(t, Options{Retries: 1, MaxRetryAfter: 2 * time.Second})
+		start := time.Now()
+		body, err := c.Get(context.Background(), srv.URL)
+		if err != nil || string(body) != "ok" {
+			t.Fatalf("Get = %q, %v", body, err)
+		}
+		if time.Since(start) < time.Second {
+			t.Error("did not wait for Retry-After")
+		}
+	})
+
+	t.Run("long wait fails fast and is remembered", func(t *testing.T) {
+		calls.Store(0)
+		state := cache.NewDir(t.TempDir())
+		opts := Options{Retries: 1, MaxRetryAfter: 100 * time.Millisecond}
+		if _, err := newClient(opts, state).Get(context.Background(), srv.URL); !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("err = %v, want ErrRateLimited", err)
+		}
+		if _, err := newClient(opts, state).Get(context.Background(), srv.URL); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("second client err = %v, want ErrRateLimited", err)
+		}
+		if n := calls.Load(); n != 1 {
+			t.Errorf("calls = %d, want 1", n)
+		}
+	})
+
+	t.Run("no wait without an attempt left", func(t *testing.T) {
+		calls.Store(0)
+		c := testClient(t, Options{Retries: -1, MaxRetryAfter: 2 * time.Second})
+		start := time.Now()
+		var se *StatusError
+		if _, err := c.Get(context.Background(), srv.URL); !errors.As(err, &se) || se.Code != http.StatusTooManyRequests {
+			t.Fatalf("err = %v, want 429", err)
+		}
+		if d := time.Since(start); d > 500*time.Millisecond {
+			t.Errorf("took %v, waited for a retry that never came", d)
+		}
+	})
+}
+
+func TestBreakerCountsConcurrentFailures(t *testing.T) {
+	srv, _ := flaky(1000, http.StatusServiceUnavailable, "")
+	defer srv.Close()
+	state := cache.NewDir(t.TempDir())
+	opts := Options{Retries: -1, BreakerThreshold: 100, BreakerCooldown: time.Hour}
+
+	// One client per status process, all failing at once.
+	const procs = 8
+	var wg sync.WaitGroup
+	for range procs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			newClient(opts, state).Get(context.Background(), srv.URL)
+		}()
+	}
+	wg.Wait()
+	if got := newClient(opts, state).Status(srv.URL).Failures; got != procs {
+		t.Errorf("failures = %d, want %d", got, procs)
+	}
+}
+
+func TestRateLimitSpacesRequests(t *testing.T) {
+	srv, _ := flaky(0, 0, "ok")
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	interval := 40 * time.Millisecond
+	c := testClient(t, Options{RateLimits: map[string]time.Duration{hostname(u.Host): interval}})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := c.Get(context.Background(), srv.URL); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 2*interval {
+		t.Errorf("3 requests took %v, want at least %v", elapsed, 2*interval)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 15, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"-1", 0},
+		{"Sun, 15 Mar 2026 14:00:30 GMT", 30 * time.Second},
+		{"Sun, 15 Mar 2026 13:00:00 GMT", 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.in, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBackoffJitterBounds(t *testing.T) {
+	c := testClient(t, Options{Backoff: 100 * time.Millisecond})
+	for attempt := 1; attempt <= 3; attempt++ {
+		max := 100 * time.Millisecond << (attempt - 1)
+		for i := 0; i < 50; i++ {
+			d := c.backoff(attempt)
+			if d < max/2 || d > max {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", attempt, d, max/2, max)
+			}
+		}
+	}
+}
+
+func TestOfflineMakesNoRequests(t *testing.T) {
+	srv, calls := flaky(0, 0, "ok")
+	defer srv.Close()
+
+	c := testClient(t, Options{Offline: true, BreakerThreshold: 1})
+	if _, err := c.Get(context.Background(), srv.URL); !errors.Is(err, ErrOffline) {
+		t.Fatalf("err = %v, want ErrOffline", err)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("calls = %d, want 0", n)
+	}
+	u, _ := url.Parse(srv.URL)
+	if st := c.loadState(u.Host); st.Failures != 0 {
+		t.Errorf("offline request counted as failure: %+v", st)
+	}
+}
+
+func TestGetHonoursContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	state := cache.NewDir(t.TempDir())
+	c := newClient(Options{Retries: 2, BreakerThreshold: 1}, state)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.Get(ctx, srv.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Get took %v after deadline", elapsed)
+	}
+
+	u, _ := url.Parse(srv.URL)
+	if st := c.loadState(u.Host); st.Failures != 0 {
+		t.Errorf("cancelled request counted as failure: %+v", st)
+	}
+}
+
+func TestProbeBypassesBreaker(t *testing.T) {
+	srv, calls := flaky(1, http.StatusServiceUnavailable, "ok")
+	defer srv.Close()
+
+	c := testClient(t, Options{Retries: -1, BreakerThreshold: 1, BreakerCooldown: time.Hour})
+	if _, err := c.Get(context.Background(), srv.URL); err == nil {
+		t.Fatal("expected first Get to fail")
+	}
+	st := c.Status(srv.URL)
+	if st.Failures != 1 || !st.OpenUntil.After(time.Now()) {
+		t.Fatalf("Status = %+v, want open breaker", st)
+	}
+
+	if _, err := c.Probe(context.Background(), srv.URL); err != nil {
+		t.Errorf("Probe: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want Probe to reach the server despite the open breaker", calls.Load())
+	}
+	if c.Status(srv.URL).Failures != 1 {
+		t.Error("Probe should not change host state")
+	}
+}
@@ -0,0 +1,158 @@
+// Package logging writes raceday's structured debug log: log/slog
+// records in a size-rotated file, so status-mode runs, whose output is
+// only the final status line, leave a trail of what they fetched, what
+// the cache served and what was chosen for display.
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/jfmyers/tmux-raceday/internal/config"
+)
+
+const (
+	// MaxSize is how large the log grows before it is rotated.
+	MaxSize = 5 << 20
+	// Backups is how many rotated logs (raceday.log.1, .2, …) are kept.
+	Backups = 3
+)
+
+// DefaultPath returns raceday.log in the state directory,
+// $XDG_STATE_HOME/raceday. The log lives outside the cache directory so
+// cache pruning never removes it.
+func DefaultPath() string {
+	return filepath.Join(config.StateDir(), "raceday.log")
+}
+
+// Setup makes the default slog logger write JSON records at level and
+// above to path, rotating it at MaxSize. The returned func closes the
+// file. An empty path disables logging instead, so library code can log
+// unconditionally without writing to the terminal.
+func Setup(path string, level slog.Level) (func() error, error) {
+	if path == "" {
+		slog.SetDefault(slog.New(slog.DiscardHandler))
+		return func() error { return nil }, nil
+	}
+	f, err := OpenRotating(path, MaxSize, Backups)
+	if err != nil {
+		slog.SetDefault(slog.New(slog.DiscardHandler))
+		return nil, fmt.Errorf("opening log: %w", err)
+	}
+	h := slog.NewJSONHandler(f, &slog.HandlerOptions{Level: level, ReplaceAttr: readableDurations})
+	slog.SetDefault(slog.New(h).With("pid", os.Getpid()))
+	return f.Close, nil
+}
+
+// readableDurations writes durations as "1.2s" rather than nanoseconds.
+func readableDurations(_ []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindDuration {
+		a.Value = slog.StringValue(a.Value.Duration().String())
+	}
+	return a
+}
+
+// RotatingFile is an append-only log file that is renamed to path.1
+// (shifting older backups up) once it exceeds its size limit. Several
+// processes may append to the same file; each notices when another has
+// rotated it and reopens path.
+type RotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	backups int
+	f       *os.File
+}
+
+// OpenRotating opens path for appending, creating it and its directory
+// if needed.
+func OpenRotating(path string, maxSize int64, backups int) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, maxSize: maxSize, backups: backups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	r.f = f
+	return nil
+}
+
+// Write appends p, rotating first if the file is already over its limit.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+
+	info, err := r.f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	// Another process rotated the file out from under us.
+	if cur, err := os.Stat(r.path); err != nil || !os.SameFile(info, cur) {
+		if err := r.reopen(); err != nil {
+			return 0, err
+		}
+		if info, err = r.f.Stat(); err != nil {
+			return 0, err
+		}
+	}
+	if r.maxSize > 0 && info.Size() > 0 && info.Size()+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	return r.f.Write(p)
+}
+
+func (r *RotatingFile) reopen() error {
+	r.f.Close()
+	r.f = nil
+	return r.open()
+}
+
+// rotate shifts path.N to path.N+1, dropping the oldest, moves path to
+// path.1 and reopens path.
+func (r *RotatingFile) rotate() error {
+	if r.backups > 0 {
+		os.Remove(r.backup(r.backups))
+		for i := r.backups - 1; i >= 1; i-- {
+			os.Rename(r.backup(i), r.backup(i+1))
+		}
+		if err := os.Rename(r.path, r.backup(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return err
+	}
+	return r.reopen()
+}
+
+func (r *RotatingFile) backup(n int) string {
+	return fmt.Sprintf("%s.%d", r.path, n)
+}
+
+// Close closes the file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "raceday.log")
+	f, err := OpenRotating(path, 100, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	line := []byte(strings.Repeat("x", 39) + "\n")
+	for range 10 {
+		if _, err := f.Write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if info.Size() > 100 {
+			t.Errorf("%s is %d bytes, over the limit", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); err == nil {
+		t.Error("kept more backups than configured")
+	}
+}
+
+func TestRotatingFileFollowsOtherProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "raceday.log")
+	a, err := OpenRotating(path, 100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, err := OpenRotating(path, 100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	a.Write([]byte(strings.Repeat("a", 90) + "\n"))
+	a.Write([]byte("a rotates\n"))
+	b.Write([]byte("b follows\n"))
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "b follows") {
+		t.Errorf("b wrote to the rotated file; current log = %q", data)
+	}
+}
+
+func TestSetup(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	path := filepath.Join(t.TempDir(), "raceday.log")
+	closeLog, err := Setup(path, slog.LevelDebug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slog.Debug("cache", "key", "live_feed.json", "result", "hit", "ttl", 30*time.Second)
+	closeLog()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(data), &rec); err != nil {
+		t.Fatalf("not a JSON record: %q", data)
+	}
+	if rec["msg"] != "cache" || rec["key"] != "live_feed.json" || rec["level"] != "DEBUG" || rec["ttl"] != "30s" {
+		t.Errorf("record = %v", rec)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+	if got := DefaultPath(); got != filepath.Join("/state", "raceday", "raceday.log") {
+		t.Errorf("DefaultPath = %q", got)
+	}
+}
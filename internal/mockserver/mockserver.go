@@ -0,0 +1,390 @@
+// Package mockserver serves recorded or hand-authored fixtures in the shape
+// of the NASCAR CDN, OpenF1 and Open-Meteo APIs, so network code paths can
+// be exercised without the real services. Latency, error codes and schema
+// drift can be injected per route.
+//
+// Routes live under one host, with a prefix per provider:
+//
+//	/nascar/cacher/{year}/race_list_basic.json
+//	/nascar/cacher/{year}/1/{race_id}/weekend-feed.json
+//	/nascar/live/feeds/live-feed.json
+//	/nascar/live/feeds/live-points.json
+//	/openf1/v1/{meetings,sessions,position,race_control,stints,drivers,intervals,session_result}
+//	/open-meteo/v1/forecast
+//
+// Each route is served from the fixture of the same name, e.g.
+// nascar/live-feed.json or openf1/position.json. Schedules look for a
+// year-specific nascar/race_list_basic_{year}.json first, weekend feeds
+// for a race-specific nascar/weekend-feed_{race_id}.json.
+package mockserver
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/config"
+)
+
+//go:embed fixtures
+var builtin embed.FS
+
+// Drift modes rewrite a fixture to simulate an upstream schema change.
+const (
+	DriftRename   = "rename"   // every object key gets a "_v2" suffix
+	DriftRetype   = "retype"   // every number becomes a string
+	DriftTruncate = "truncate" // body is cut in half (malformed JSON)
+)
+
+// AllRoutes is the key that applies a fault or drift to every route.
+const AllRoutes = "*"
+
+// Options configures a Server. Routes are named after their fixture
+// without the extension: race_list_basic, weekend-feed, live-feed,
+// live-points, meetings, sessions, position, race_control, stints,
+// drivers, intervals, session_result, forecast.
+type Options struct {
+	Fixtures string            // directory overriding the built-in fixtures
+	Latency  time.Duration     // added before every response
+	Faults   map[string]int    // route → HTTP status to return instead
+	Drift    map[string]string // route → drift mode
+}
+
+// Server is an http.Handler serving the mock APIs.
+type Server struct {
+	fixtures []fs.FS // searched in order
+
+	mu      sync.Mutex
+	latency time.Duration
+	faults  map[string]int
+	drift   map[string]string
+	hits    map[string]int
+}
+
+// New returns a Server for opts.
+func New(opts Options) *Server {
+	fixtures, _ := fs.Sub(builtin, "fixtures")
+	s := &Server{
+		fixtures: []fs.FS{fixtures},
+		latency:  opts.Latency,
+		faults:   make(map[string]int),
+		drift:    make(map[string]string),
+		hits:     make(map[string]int),
+	}
+	if opts.Fixtures != "" {
+		s.fixtures = append([]fs.FS{os.DirFS(opts.Fixtures)}, s.fixtures...)
+	}
+	for route, code := range opts.Faults {
+		s.faults[route] = code
+	}
+	for route, mode := range opts.Drift {
+		s.drift[route] = mode
+	}
+	return s
+}
+
+// SetLatency changes the delay added before every response.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// SetFault makes route answer with code; 0 clears the fault.
+func (s *Server) SetFault(route string, code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if code == 0 {
+		delete(s.faults, route)
+		return
+	}
+	s.faults[route] = code
+}
+
+// SetDrift applies a drift mode to route; "" clears it.
+func (s *Server) SetDrift(route, mode string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if mode == "" {
+		delete(s.drift, route)
+		return
+	}
+	s.drift[route] = mode
+}
+
+// Hits returns how many requests route has received.
+func (s *Server) Hits(route string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[route]
+}
+
+// Endpoints returns the base URLs to point each provider at, given the
+// server's root URL (e.g. "http://127.0.0.1:8787").
+func Endpoints(root string) config.Endpoints {
+	root = strings.TrimSuffix(root, "/")
+	return config.Endpoints{
+		NASCAR:    root + "/nascar",
+		OpenF1:    root + "/openf1/v1",
+		OpenMeteo: root + "/open-meteo/v1",
+	}
+}
+
+var (
+	scheduleRoute = regexp.MustCompile(`^/nascar/cacher/(\d{4})/race_list_basic\.json$`)
+	weekendRoute  = regexp.MustCompile(`^/nascar/cacher/\d{4}/1/(\d+)/weekend-feed\.json$`)
+)
+
+// route maps a request path to its route name and candidate fixtures.
+func route(p string) (name string, fixtures []string, ok bool) {
+	if m := scheduleRoute.FindStringSubmatch(p); m != nil {
+		return "race_list_basic", []string{
+			"nascar/race_list_basic_" + m[1] + ".json",
+			"nascar/race_list_basic.json",
+		}, true
+	}
+	if m := weekendRoute.FindStringSubmatch(p); m != nil {
+		return "weekend-feed", []string{
+			"nascar/weekend-feed_" + m[1] + ".json",
+			"nascar/weekend-feed.json",
+		}, true
+	}
+	switch p {
+	case "/nascar/live/feeds/live-feed.json":
+		return "live-feed", []string{"nascar/live-feed.json"}, true
+	case "/nascar/live/feeds/live-points.json":
+		return "live-points", []string{"nascar/live-points.json"}, true
+	case "/open-meteo/v1/forecast":
+		return "forecast", []string{"open-meteo/forecast.json"}, true
+	}
+	if name, ok := strings.CutPrefix(p, "/openf1/v1/"); ok {
+		switch name {
+		case "meetings", "sessions", "position", "race_control", "stints", "drivers", "intervals", "session_result":
+			return name, []string{"openf1/" + name + ".json"}, true
+		}
+	}
+	return "", nil, false
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, candidates, ok := route(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	s.hits[name]++
+	latency := s.latency
+	code := s.lookupFault(name)
+	mode := s.lookupDrift(name)
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if code != 0 {
+		if code == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	body, err := s.readFixture(candidates)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, "/openf1/") {
+		if body, err = filterOpenF1(body, r.URL.RawQuery); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if mode != "" {
+		if body, err = applyDrift(body, mode); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+func (s *Server) lookupFault(name string) int {
+	if code, ok := s.faults[name]; ok {
+		return code
+	}
+	return s.faults[AllRoutes]
+}
+
+func (s *Server) lookupDrift(name string) string {
+	if mode, ok := s.drift[name]; ok {
+		return mode
+	}
+	return s.drift[AllRoutes]
+}
+
+func (s *Server) readFixture(candidates []string) ([]byte, error) {
+	for _, name := range candidates {
+		for _, fsys := range s.fixtures {
+			if data, err := fs.ReadFile(fsys, name); err == nil {
+				return data, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no fixture for %s", path.Base(candidates[len(candidates)-1]))
+}
+
+// filterOpenF1 applies OpenF1-style query filters to a fixture array:
+// field=value keeps exact matches, field>value and field<value compare
+// (numerically when both sides are numbers), and session_key=latest or
+// meeting_key=latest keeps the records with the highest key.
+func filterOpenF1(body []byte, rawQuery string) ([]byte, error) {
+	if rawQuery == "" {
+		return body, nil
+	}
+	var records []map[string]any
+	if err := json.Unmarshal(body, &records); err != nil {
+		return nil, fmt.Errorf("fixture is not an array: %w", err)
+	}
+	for _, cond := range strings.Split(rawQuery, "&") {
+		field, op, value, ok := parseCondition(cond)
+		if !ok {
+			continue
+		}
+		if op == "=" && value == "latest" {
+			value = latest(records, field)
+		}
+		kept := records[:0]
+		for _, rec := range records {
+			if v, ok := rec[field]; ok && matches(v, op, value) {
+				kept = append(kept, rec)
+			}
+		}
+		records = kept
+	}
+	if records == nil {
+		records = []map[string]any{}
+	}
+	return json.Marshal(records)
+}
+
+func parseCondition(cond string) (field, op, value string, ok bool) {
+	cond, err := url.QueryUnescape(cond)
+	if err != nil {
+		return "", "", "", false
+	}
+	i := strings.IndexAny(cond, "=<>")
+	if i <= 0 {
+		return "", "", "", false
+	}
+	return cond[:i], cond[i : i+1], cond[i+1:], true
+}
+
+func latest(records []map[string]any, field string) string {
+	var keys []float64
+	for _, rec := range records {
+		if n, ok := rec[field].(float64); ok {
+			keys = append(keys, n)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Float64s(keys)
+	return strconv.FormatFloat(keys[len(keys)-1], 'f', -1, 64)
+}
+
+func matches(v any, op, want string) bool {
+	var cmp int
+	switch v := v.(type) {
+	case float64:
+		n, err := strconv.ParseFloat(want, 64)
+		if err != nil {
+			return false
+		}
+		switch {
+		case v < n:
+			cmp = -1
+		case v > n:
+			cmp = 1
+		}
+	case string:
+		cmp = strings.Compare(v, want)
+	case bool:
+		return op == "=" && strconv.FormatBool(v) == want
+	default:
+		return false
+	}
+	switch op {
+	case "<":
+		return cmp < 0
+	case ">":
+		return cmp > 0
+	}
+	return cmp == 0
+}
+
+// applyDrift rewrites body according to mode.
+func applyDrift(body []byte, mode string) ([]byte, error) {
+	if mode == DriftTruncate {
+		return body[:len(body)/2], nil
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, err
+	}
+	switch mode {
+	case DriftRename:
+		v = walk(v, func(k string) string { return k + "_v2" }, nil)
+	case DriftRetype:
+		v = walk(v, nil, func(n float64) any { return strconv.FormatFloat(n, 'f', -1, 64) })
+	default:
+		return nil, fmt.Errorf("unknown drift mode %q", mode)
+	}
+	return json.Marshal(v)
+}
+
+// walk rebuilds v, renaming object keys with key and replacing numbers
+// with num; either may be nil.
+func walk(v any, key func(string) string, num func(float64) any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			if key != nil {
+				k = key(k)
+			}
+			out[k] = walk(e, key, num)
+		}
+		return out
+	case []any:
+		for i, e := range v {
+			v[i] = walk(e, key, num)
+		}
+		return v
+	case float64:
+		if num != nil {
+			return num(v)
+		}
+	}
+	return v
+}
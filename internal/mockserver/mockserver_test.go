@@ -0,0 +1,185 @@
+package mockserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/mockserver"
+	"github.com/jfmyers/tmux-raceday/internal/mockserver/mockservertest"
+)
+
+func get(t *testing.T, url string) (int, []byte) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading %s: %v", url, err)
+	}
+	return resp.StatusCode, body
+}
+
+func TestRoutesServeFixtures(t *testing.T) {
+	ts := mockservertest.Start(t, mockserver.Options{})
+	ep := ts.Endpoints()
+
+	urls := []string{
+		ep.NASCAR + "/cacher/2026/race_list_basic.json",
+		ep.NASCAR + "/cacher/2026/1/5546/weekend-feed.json",
+		ep.NASCAR + "/live/feeds/live-feed.json",
+		ep.NASCAR + "/live/feeds/live-points.json",
+		ep.OpenF1 + "/meetings?year=2026",
+		ep.OpenF1 + "/sessions?year=2026&session_name=Race",
+		ep.OpenF1 + "/position?session_key=9839",
+		ep.OpenF1 + "/race_control?session_key=9839",
+		ep.OpenF1 + "/stints?session_key=9839",
+		ep.OpenF1 + "/drivers?session_key=9839",
+		ep.OpenF1 + "/intervals?session_key=9839",
+		ep.OpenF1 + "/session_result?session_key=9839",
+		ep.OpenMeteo + "/forecast?latitude=33.3700&longitude=-84.3200&current=temperature_2m",
+	}
+	for _, u := range urls {
+		code, body := get(t, u)
+		if code != http.StatusOK {
+			t.Errorf("GET %s = %d: %s", u, code, body)
+			continue
+		}
+		if !json.Valid(body) {
+			t.Errorf("GET %s returned invalid JSON", u)
+		}
+	}
+
+	if code, _ := get(t, ts.URL+"/nascar/unknown.json"); code != http.StatusNotFound {
+		t.Errorf("unknown route = %d, want 404", code)
+	}
+	if n := ts.Hits("race_list_basic"); n != 1 {
+		t.Errorf("Hits(race_list_basic) = %d, want 1", n)
+	}
+}
+
+func TestOpenF1Filters(t *testing.T) {
+	ts := mockservertest.Start(t, mockserver.Options{})
+	ep := ts.Endpoints()
+
+	count := func(query string) int {
+		t.Helper()
+		_, body := get(t, ep.OpenF1+query)
+		var recs []map[string]any
+		if err := json.Unmarshal(body, &recs); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		return len(recs)
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"/sessions?session_key=latest", 1},
+		{"/sessions?year=2025", 0},
+		{"/position?session_key=9839&date>2026-12-06T16%3A30%3A00%2B00%3A00", 2},
+		{"/race_control?session_key=9839&lap_number>17", 2},
+		{"/drivers?session_key=9839&name_acronym=VER", 1},
+	}
+	for _, tt := range tests {
+		if got := count(tt.query); got != tt.want {
+			t.Errorf("%s returned %d records, want %d", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestFaultsAndLatency(t *testing.T) {
+	ts := mockservertest.Start(t, mockserver.Options{Faults: map[string]int{"live-feed": http.StatusServiceUnavailable}})
+	ep := ts.Endpoints()
+
+	if code, _ := get(t, ep.NASCAR+"/live/feeds/live-feed.json"); code != http.StatusServiceUnavailable {
+		t.Errorf("faulted route = %d, want 503", code)
+	}
+	if code, _ := get(t, ep.NASCAR+"/live/feeds/live-points.json"); code != http.StatusOK {
+		t.Errorf("other route = %d, want 200", code)
+	}
+
+	ts.SetFault("live-feed", 0)
+	ts.SetFault(mockserver.AllRoutes, http.StatusTooManyRequests)
+	resp, err := http.Get(ep.NASCAR + "/live/feeds/live-feed.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+		t.Errorf("429 fault = %d, Retry-After %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+	ts.SetFault(mockserver.AllRoutes, 0)
+
+	ts.SetLatency(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ep.OpenMeteo+"/forecast", nil)
+	start := time.Now()
+	if _, err := http.DefaultClient.Do(req); err == nil {
+		t.Error("expected timeout with injected latency")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("request took %v, want it cut off by ctx", elapsed)
+	}
+}
+
+func TestDrift(t *testing.T) {
+	ts := mockservertest.Start(t, mockserver.Options{})
+	url := ts.Endpoints().NASCAR + "/live/feeds/live-feed.json"
+
+	ts.SetDrift("live-feed", mockserver.DriftRename)
+	_, body := get(t, url)
+	var renamed map[string]any
+	if err := json.Unmarshal(body, &renamed); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := renamed["lap_number_v2"]; !ok {
+		t.Errorf("rename drift: keys = %v", renamed)
+	}
+
+	ts.SetDrift("live-feed", mockserver.DriftRetype)
+	_, body = get(t, url)
+	var retyped map[string]any
+	if err := json.Unmarshal(body, &retyped); err != nil {
+		t.Fatal(err)
+	}
+	if lap, ok := retyped["lap_number"].(string); !ok || lap != "142" {
+		t.Errorf("retype drift: lap_number = %#v, want \"142\"", retyped["lap_number"])
+	}
+
+	ts.SetDrift("live-feed", mockserver.DriftTruncate)
+	if _, body = get(t, url); json.Valid(body) {
+		t.Error("truncate drift returned valid JSON")
+	}
+}
+
+func TestFixturesDirOverrides(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "nascar"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	recorded := `{"series_1":[{"race_id":1,"race_name":"Recorded 2027"}]}`
+	if err := os.WriteFile(filepath.Join(dir, "nascar", "race_list_basic_2027.json"), []byte(recorded), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := mockservertest.Start(t, mockserver.Options{Fixtures: dir})
+	ep := ts.Endpoints()
+	if _, body := get(t, ep.NASCAR+"/cacher/2027/race_list_basic.json"); !strings.Contains(string(body), "Recorded 2027") {
+		t.Errorf("2027 schedule = %s, want recorded fixture", body)
+	}
+	if _, body := get(t, ep.NASCAR+"/cacher/2026/race_list_basic.json"); !strings.Contains(string(body), "DAYTONA 500") {
+		t.Errorf("2026 schedule should fall back to the built-in fixture")
+	}
+}
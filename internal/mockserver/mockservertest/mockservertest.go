@@ -0,0 +1,31 @@
+// Package mockservertest runs the fixture server in tests. It is kept out
+// of package mockserver so the raceday binary doesn't link in testing.
+package mockservertest
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/mockserver"
+)
+
+// TestServer is a mockserver.Server listening on a local httptest server.
+type TestServer struct {
+	*mockserver.Server
+	URL string // root URL, e.g. http://127.0.0.1:54321
+}
+
+// Start runs a mockserver.Server for the duration of t.
+func Start(t testing.TB, opts mockserver.Options) *TestServer {
+	t.Helper()
+	s := mockserver.New(opts)
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return &TestServer{Server: s, URL: srv.URL}
+}
+
+// Endpoints returns the provider base URLs for this server.
+func (ts *TestServer) Endpoints() config.Endpoints {
+	return mockserver.Endpoints(ts.URL)
+}
@@ -1,6 +1,7 @@
 package nascar
 
 import (
+	"context"
 	"encoding/json"
 	"time"
 
@@ -11,7 +12,14 @@ const cacheTTL = 1 * time.Hour
 
 var fileCache = cache.New("")
 
-func fetchLiveFeedCached(nextRaceStart time.Time) (*LiveFeed, error) {
+// FetchLiveFeedCached returns the live feed through the file cache, with
+// a TTL based on how close the next race is. If the feed can't be fetched
+// the last cached copy is returned.
+func FetchLiveFeedCached(ctx context.Context) (*LiveFeed, error) {
+	return fetchLiveFeedCached(ctx, NewSeries().nextRaceStart(ctx))
+}
+
+func fetchLiveFeedCached(ctx context.Context, nextRaceStart time.Time) (*LiveFeed, error) {
 	const key = "live_feed.json"
 
 	ttl := cache.TTLForProximity(nextRaceStart)
@@ -24,10 +32,16 @@ func fetchLiveFeedCached(nextRaceStart time.Time) (*LiveFeed, error) {
 		}
 	}
 
-	feed, err := FetchLiveFeed()
+	data, err := fileCache.Refresh(ctx, key, ttl, func() ([]byte, error) {
+		feed, err := FetchLiveFeed(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(feed)
+	})
 	if err != nil {
 		// Fall back to stale cache on API failure.
-		if data, _ := fileCache.ReadStale(key, ttl); data != nil {
+		if data := fileCache.Fallback(ctx, key); data != nil {
 			var stale LiveFeed
 			if json.Unmarshal(data, &stale) == nil {
 				return &stale, nil
@@ -36,9 +50,9 @@ func fetchLiveFeedCached(nextRaceStart time.Time) (*LiveFeed, error) {
 		return nil, err
 	}
 
-	if data, err := json.Marshal(feed); err == nil {
-		_ = fileCache.Write(key, data)
+	var feed LiveFeed
+	if err := json.Unmarshal(data, &feed); err != nil {
+		return nil, err
 	}
-
-	return feed, nil
+	return &feed, nil
 }
@@ -1,44 +1,81 @@
 package nascar
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"sort"
+	"strings"
 	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+	"github.com/jfmyers/tmux-raceday/internal/fetch"
+	"github.com/jfmyers/tmux-raceday/internal/schema"
+	"github.com/jfmyers/tmux-raceday/internal/series"
 )
 
-const baseURL = "https://cf.nascar.com/cacher"
+// defaultBaseURL is the NASCAR CDN root that schedule, live feed and
+// points paths hang off.
+const defaultBaseURL = "https://cf.nascar.com"
+
+// baseURL is where NASCAR requests go. Override it with SetBaseURL.
+var baseURL = defaultBaseURL
+
+// SetBaseURL points all NASCAR requests at url, e.g. a mirror or fixture
+// server, and caches their responses apart from the CDN's. An empty url
+// keeps the current one.
+func SetBaseURL(url string) {
+	if url != "" {
+		baseURL = strings.TrimSuffix(url, "/")
+		fileCache = cache.NewFor("", baseURL, defaultBaseURL)
+	}
+}
+
+func scheduleURL(year int) string {
+	return fmt.Sprintf("%s/cacher/%d/race_list_basic.json", baseURL, year)
+}
 
-var httpClient = &http.Client{Timeout: 10 * time.Second}
+func liveFeedURL() string { return baseURL + "/live/feeds/live-feed.json" }
+
+func pointsURL() string { return baseURL + "/live/feeds/live-points.json" }
+
+// ProbeURL returns a lightweight URL for checking that the NASCAR CDN is
+// reachable.
+func ProbeURL() string { return liveFeedURL() }
 
 // FetchCupSchedule returns the Cup Series (series_id=1) race schedule for the
 // given year. Results are served from a local file cache when fresh.
-func FetchCupSchedule(year int) ([]Race, error) {
-	cacheKey := fmt.Sprintf("schedule_%d.json", year)
-
-	if data, ok := fileCache.Read(cacheKey, cacheTTL); ok {
-		return parseCupSchedule(data)
-	}
+func FetchCupSchedule(ctx context.Context, year int) ([]Race, error) {
+	return fetchCupSchedule(ctx, year, cacheTTL)
+}
 
-	url := fmt.Sprintf("%s/%d/race_list_basic.json", baseURL, year)
-	resp, err := httpClient.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("fetching schedule: %w", err)
-	}
-	defer resp.Body.Close()
+// fetchCupSchedule is FetchCupSchedule with an explicit TTL; a TTL of 0
+// forces a refresh, falling back to the cached copy if that fails.
+func fetchCupSchedule(ctx context.Context, year int, ttl time.Duration) ([]Race, error) {
+	cacheKey := fmt.Sprintf("schedule_%d.json", year)
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("schedule API returned %d", resp.StatusCode)
+	if ttl > 0 {
+		if data, ok := fileCache.Read(cacheKey, ttl); ok {
+			return parseCupSchedule(data)
+		}
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	data, err := fileCache.Refresh(ctx, cacheKey, ttl, func() ([]byte, error) {
+		data, err := fetch.Get(ctx, scheduleURL(year))
+		if err != nil {
+			return nil, fmt.Errorf("fetching schedule: %w", err)
+		}
+		schema.Record(FeedSchedule, ValidateSchedule(data))
+		return data, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
+		// Fall back to stale cache on API failure.
+		if stale := fileCache.Fallback(ctx, cacheKey); stale != nil {
+			return parseCupSchedule(stale)
+		}
+		return nil, err
 	}
 
-	_ = fileCache.Write(cacheKey, data)
 	return parseCupSchedule(data)
 }
 
@@ -54,21 +91,17 @@ func parseCupSchedule(data []byte) ([]Race, error) {
 	return resp.Series1, nil
 }
 
-// NextRace returns the next upcoming (incomplete) Cup Series race.
-func NextRace(races []Race) *Race {
-	now := time.Now().UTC()
+// ScheduleRace returns the Cup Series schedule entry for r, a race from
+// the series' schedule, with the weekend's events.
+func ScheduleRace(ctx context.Context, r series.Race) (*Race, error) {
+	races, err := FetchCupSchedule(ctx, r.StartTime.Year())
+	if err != nil {
+		return nil, err
+	}
 	for i := range races {
-		if races[i].IsComplete() {
-			continue
-		}
-		start, err := races[i].RaceStartUTC()
-		if err != nil {
-			continue
-		}
-		// Include races that started up to 6 hours ago (may still be running)
-		if start.After(now.Add(-6 * time.Hour)) {
-			return &races[i]
+		if start, err := races[i].RaceStartUTC(); err == nil && start.Equal(r.StartTime) && races[i].RaceName == r.RaceName {
+			return &races[i], nil
 		}
 	}
-	return nil
+	return nil, fmt.Errorf("%s not in the %d schedule", r.RaceName, r.StartTime.Year())
 }
@@ -0,0 +1,106 @@
+package nascar
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+	"github.com/jfmyers/tmux-raceday/internal/mockserver"
+	"github.com/jfmyers/tmux-raceday/internal/mockserver/mockservertest"
+)
+
+// useMockServer points the package at a fixture server with an empty
+// cache for the duration of t.
+func useMockServer(t *testing.T, opts mockserver.Options) *mockservertest.TestServer {
+	t.Helper()
+	ts := mockservertest.Start(t, opts)
+	origBase, origCache := baseURL, fileCache
+	SetBaseURL(ts.Endpoints().NASCAR)
+	fileCache = cache.NewDir(t.TempDir())
+	t.Cleanup(func() { baseURL, fileCache = origBase, origCache })
+	return ts
+}
+
+func TestSetBaseURL(t *testing.T) {
+	ts := useMockServer(t, mockserver.Options{})
+
+	races, err := FetchCupSchedule(context.Background(), 2026)
+	if err != nil {
+		t.Fatalf("FetchCupSchedule: %v", err)
+	}
+	if len(races) == 0 || races[0].RaceName != "DAYTONA 500" {
+		t.Errorf("races = %+v", races)
+	}
+	if _, err := FetchStandings(context.Background()); err != nil {
+		t.Fatalf("FetchStandings: %v", err)
+	}
+	feed, err := FetchLiveFeed(context.Background())
+	if err != nil {
+		t.Fatalf("FetchLiveFeed: %v", err)
+	}
+	if !feed.IsLiveCupRace() || feed.Leader().VehicleNumber != "8" {
+		t.Errorf("feed = lap %d, leader %+v", feed.LapNumber, feed.Leader())
+	}
+	for _, route := range []string{"race_list_basic", "live-points", "live-feed"} {
+		if ts.Hits(route) != 1 {
+			t.Errorf("Hits(%s) = %d, want 1", route, ts.Hits(route))
+		}
+	}
+
+	before := baseURL
+	SetBaseURL("")
+	if baseURL != before {
+		t.Errorf("empty URL changed baseURL to %q", baseURL)
+	}
+}
+
+func TestSetBaseURLSeparatesCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	origBase, origCache := baseURL, fileCache
+	t.Cleanup(func() { baseURL, fileCache = origBase, origCache })
+	a := mockservertest.Start(t, mockserver.Options{})
+	b := mockservertest.Start(t, mockserver.Options{})
+
+	fetchFrom := func(ts *mockservertest.TestServer) {
+		t.Helper()
+		SetBaseURL(ts.Endpoints().NASCAR)
+		if _, err := FetchCupSchedule(context.Background(), 2026); err != nil {
+			t.Fatalf("FetchCupSchedule: %v", err)
+		}
+	}
+	fetchFrom(a)
+	fetchFrom(b) // a's schedule is not b's
+	fetchFrom(a)
+	if a.Hits("race_list_basic") != 1 || b.Hits("race_list_basic") != 1 {
+		t.Errorf("hits = %d and %d, want 1 each", a.Hits("race_list_basic"), b.Hits("race_list_basic"))
+	}
+
+	// Nor is it the CDN's.
+	SetBaseURL(defaultBaseURL)
+	if _, ok := fileCache.Read("schedule_2026.json", time.Hour); ok {
+		t.Error("mock server's schedule cached as the CDN's")
+	}
+}
+
+func TestScheduleRace(t *testing.T) {
+	useMockServer(t, mockserver.Options{})
+	ctx := context.Background()
+
+	races, err := NewSeries().FetchSchedule(ctx, 2026)
+	if err != nil || len(races) == 0 {
+		t.Fatalf("FetchSchedule = %d races, %v", len(races), err)
+	}
+	r, err := ScheduleRace(ctx, races[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.RaceName != "DAYTONA 500" || len(r.Schedule) == 0 {
+		t.Errorf("race = %q with %d events", r.RaceName, len(r.Schedule))
+	}
+
+	races[0].StartTime = races[0].StartTime.Add(time.Hour)
+	if _, err := ScheduleRace(ctx, races[0]); err == nil {
+		t.Error("found a race that isn't scheduled")
+	}
+}
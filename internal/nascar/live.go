@@ -1,13 +1,13 @@
 package nascar
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-)
 
-const liveFeedURL = "https://cf.nascar.com/live/feeds/live-feed.json"
+	"github.com/jfmyers/tmux-raceday/internal/fetch"
+	"github.com/jfmyers/tmux-raceday/internal/schema"
+)
 
 // LiveFeed represents the real-time race data from NASCAR's CDN.
 type LiveFeed struct {
@@ -95,6 +95,20 @@ func (v Vehicle) LapsSinceLastPit(currentLap int) int {
 	return currentLap - last
 }
 
+// Make returns the vehicle's manufacturer, e.g. "Toyota" for the feed's
+// "Tyt".
+func (v Vehicle) Make() string {
+	switch v.VehicleManufacturer {
+	case "Tyt":
+		return "Toyota"
+	case "Chv":
+		return "Chevrolet"
+	case "Frd":
+		return "Ford"
+	}
+	return v.VehicleManufacturer
+}
+
 type DriverInfo struct {
 	DriverID  int    `json:"driver_id"`
 	FullName  string `json:"full_name"`
@@ -117,21 +131,12 @@ func (v Vehicle) TotalLapsLed() int {
 }
 
 // FetchLiveFeed retrieves the current live race feed.
-func FetchLiveFeed() (*LiveFeed, error) {
-	resp, err := httpClient.Get(liveFeedURL)
+func FetchLiveFeed(ctx context.Context) (*LiveFeed, error) {
+	data, err := fetch.Get(ctx, liveFeedURL())
 	if err != nil {
 		return nil, fmt.Errorf("fetching live feed: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("live feed returned %d", resp.StatusCode)
-	}
-
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("reading live feed: %w", err)
-	}
+	schema.Record(FeedLiveFeed, ValidateLiveFeed(data))
 
 	var feed LiveFeed
 	if err := json.Unmarshal(data, &feed); err != nil {
@@ -95,3 +95,11 @@ func TestIsFinished(t *testing.T) {
 		})
 	}
 }
+
+func TestVehicleMake(t *testing.T) {
+	for code, want := range map[string]string{"Tyt": "Toyota", "Chv": "Chevrolet", "Frd": "Ford", "Dge": "Dge"} {
+		if got := (Vehicle{VehicleManufacturer: code}).Make(); got != want {
+			t.Errorf("Make(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package nascar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/fetch"
+	"github.com/jfmyers/tmux-raceday/internal/schema"
+	"github.com/jfmyers/tmux-raceday/internal/series"
+)
+
+// WeekendFeed is a race weekend's summary from the CDN, including the
+// race's official results once they are posted.
+type WeekendFeed struct {
+	WeekendRace []WeekendRace `json:"weekend_race"`
+}
+
+type WeekendRace struct {
+	RaceID   int          `json:"race_id"`
+	RaceName string       `json:"race_name"`
+	Results  []RaceResult `json:"results"`
+}
+
+// RaceResult is one car's official finish.
+type RaceResult struct {
+	DriverID          int    `json:"driver_id"`
+	DriverFullName    string `json:"driver_fullname"`
+	CarNumber         string `json:"car_number"`
+	FinishingPosition int    `json:"finishing_position"`
+	StartingPosition  int    `json:"starting_position"`
+	PointsEarned      int    `json:"points_earned"`
+	LapsCompleted     int    `json:"laps_completed"`
+	FinishingStatus   string `json:"finishing_status"`
+}
+
+func weekendFeedURL(year, raceID int) string {
+	return fmt.Sprintf("%s/cacher/%d/1/%d/weekend-feed.json", baseURL, year, raceID)
+}
+
+// FetchWeekendFeed returns the weekend feed for a Cup race. Results are
+// served from a local file cache when fresh.
+func FetchWeekendFeed(ctx context.Context, year, raceID int) (*WeekendFeed, error) {
+	cacheKey := fmt.Sprintf("weekend_%d_%d.json", year, raceID)
+
+	if data, ok := fileCache.Read(cacheKey, cacheTTL); ok {
+		return parseWeekendFeed(data)
+	}
+
+	data, err := fileCache.Refresh(ctx, cacheKey, cacheTTL, func() ([]byte, error) {
+		data, err := fetch.Get(ctx, weekendFeedURL(year, raceID))
+		if err != nil {
+			return nil, fmt.Errorf("fetching weekend feed: %w", err)
+		}
+		schema.Record(FeedWeekend, ValidateWeekendFeed(data))
+		return data, nil
+	})
+	if err != nil {
+		// Fall back to stale cache on API failure.
+		if stale := fileCache.Fallback(ctx, cacheKey); stale != nil {
+			return parseWeekendFeed(stale)
+		}
+		return nil, err
+	}
+
+	return parseWeekendFeed(data)
+}
+
+func parseWeekendFeed(data []byte) (*WeekendFeed, error) {
+	var feed WeekendFeed
+	if err := json.Unmarshal(data, &feed); err != nil {
+		return nil, fmt.Errorf("parsing weekend feed: %w", err)
+	}
+	return &feed, nil
+}
+
+// LastRace returns the most recent race in races that has a winner and
+// started before now, or nil if there is none.
+func LastRace(races []Race, now time.Time) *Race {
+	var last *Race
+	var lastStart time.Time
+	for i := range races {
+		if !races[i].IsComplete() {
+			continue
+		}
+		start, err := races[i].RaceStartUTC()
+		if err != nil || start.After(now) {
+			continue
+		}
+		if last == nil || start.After(lastStart) {
+			last, lastStart = &races[i], start
+		}
+	}
+	return last
+}
+
+// LastResult returns the official result of the most recent completed
+// Cup race, looking back into last season's schedule early in the year.
+// It returns nil if no completed race has results posted.
+func (s *NASCARSeries) LastResult(ctx context.Context, now time.Time) (*series.Result, error) {
+	var race *Race
+	for _, year := range []int{now.Year(), now.Year() - 1} {
+		races, err := FetchCupSchedule(ctx, year)
+		if err != nil {
+			return nil, fmt.Errorf("nascar schedule: %w", err)
+		}
+		if race = LastRace(races, now); race != nil {
+			break
+		}
+	}
+	if race == nil {
+		return nil, nil
+	}
+	start, _ := race.RaceStartUTC()
+	feed, err := FetchWeekendFeed(ctx, start.Year(), race.RaceID)
+	if err != nil {
+		return nil, fmt.Errorf("nascar results: %w", err)
+	}
+	return s.buildResult(race, start, feed), nil
+}
+
+// buildResult combines a race from the schedule with its official
+// results. It returns nil if the results aren't posted yet.
+func (s *NASCARSeries) buildResult(race *Race, start time.Time, feed *WeekendFeed) *series.Result {
+	var results []RaceResult
+	for _, wr := range feed.WeekendRace {
+		if wr.RaceID == race.RaceID {
+			results = wr.Results
+		}
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].FinishingPosition < results[j].FinishingPosition
+	})
+
+	res := &series.Result{
+		SeriesName: s.Name(),
+		ShortName:  s.ShortName(),
+		RaceName:   race.RaceName,
+		TrackName:  race.TrackName,
+		StartTime:  start,
+		EndTime:    start.Add(estimatedRaceDuration),
+		Positions:  make([]series.Driver, len(results)),
+	}
+	for i, r := range results {
+		res.Positions[i] = series.Driver{
+			Number:   r.CarNumber,
+			Name:     lastName(r.DriverFullName),
+			FullName: r.DriverFullName,
+			Position: r.FinishingPosition,
+			Delta:    float64(r.FinishingPosition - r.StartingPosition),
+			Points:   float64(r.PointsEarned),
+		}
+	}
+	res.Winner = res.Positions[0]
+	return res
+}
+
+// lastName returns the last word of a full name, dropping the suffixes
+// the CDN appends such as "Jr." or "(i)".
+func lastName(full string) string {
+	words := strings.Fields(full)
+	for len(words) > 1 {
+		w := words[len(words)-1]
+		if w == "Jr." || w == "Sr." || w == "II" || w == "III" || strings.HasPrefix(w, "(") {
+			words = words[:len(words)-1]
+			continue
+		}
+		break
+	}
+	if len(words) == 0 {
+		return full
+	}
+	return words[len(words)-1]
+}
@@ -0,0 +1,51 @@
+package nascar
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/mockserver"
+)
+
+func TestLastResult(t *testing.T) {
+	useMockServer(t, mockserver.Options{})
+	s := NewSeries()
+
+	// The Daytona 500 is the only race with a winner in the fixture.
+	now := time.Date(2026, 2, 17, 12, 0, 0, 0, time.UTC)
+	res, err := s.LastResult(context.Background(), now)
+	if err != nil || res == nil {
+		t.Fatalf("LastResult = %v, %v", res, err)
+	}
+	if res.RaceName != "DAYTONA 500" || res.Winner.Number != "24" || res.Winner.Name != "Byron" {
+		t.Errorf("result = %s won by #%s %s", res.RaceName, res.Winner.Number, res.Winner.Name)
+	}
+	if want := time.Date(2026, 2, 15, 23, 30, 0, 0, time.UTC); !res.EndTime.Equal(want) {
+		t.Errorf("EndTime = %v, want %v", res.EndTime, want)
+	}
+	if len(res.Positions) != 5 {
+		t.Fatalf("got %d positions, want 5", len(res.Positions))
+	}
+	if p := res.Positions[1]; p.Number != "8" || p.Position != 2 || p.Points != 41 || p.Delta != -5 {
+		t.Errorf("P2 = %+v", p)
+	}
+
+	// Before the race ran there is nothing to show.
+	if res, err := s.LastResult(context.Background(), now.AddDate(0, 0, -3)); res != nil || err != nil {
+		t.Errorf("before the first race: %v, %v", res, err)
+	}
+}
+
+func TestLastName(t *testing.T) {
+	for full, want := range map[string]string{
+		"William Byron":           "Byron",
+		"Ricky Stenhouse Jr.":     "Stenhouse",
+		"Shane van Gisbergen (i)": "Gisbergen",
+		"Cher":                    "Cher",
+	} {
+		if got := lastName(full); got != want {
+			t.Errorf("lastName(%q) = %q, want %q", full, got, want)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package nascar
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"sort"
 	"time"
 
@@ -21,59 +23,88 @@ func NewSeries() *NASCARSeries { return &NASCARSeries{} }
 func (s *NASCARSeries) Name() string      { return "NASCAR Cup" }
 func (s *NASCARSeries) ShortName() string  { return "NASCAR" }
 
-func (s *NASCARSeries) FetchSchedule(year int) ([]series.Race, error) {
-	races, err := FetchCupSchedule(year)
+func (s *NASCARSeries) FetchSchedule(ctx context.Context, year int) ([]series.Race, error) {
+	races, err := FetchCupSchedule(ctx, year)
 	if err != nil {
 		return nil, fmt.Errorf("nascar schedule: %w", err)
 	}
 
 	out := make([]series.Race, 0, len(races))
 	for _, r := range races {
-		sr := series.Race{
-			SeriesName:  s.Name(),
-			ShortName:   s.ShortName(),
-			RaceName:    r.RaceName,
-			TrackName:   r.TrackName,
-			Broadcaster: r.TelevisionBroadcaster,
-			Complete:    r.IsComplete(),
-		}
-		if t, err := r.RaceStartUTC(); err == nil {
-			sr.StartTime = t
+		out = append(out, s.race(r))
+	}
+	return out, nil
+}
+
+// FetchSessions returns the practice, qualifying and race sessions of
+// each weekend in year, from the schedule's events. Races without events
+// are listed as their race alone.
+func (s *NASCARSeries) FetchSessions(ctx context.Context, year int) ([]series.Session, error) {
+	races, err := FetchCupSchedule(ctx, year)
+	if err != nil {
+		return nil, fmt.Errorf("nascar schedule: %w", err)
+	}
+
+	var out []series.Session
+	for _, r := range races {
+		sr := s.race(r)
+		n := len(out)
+		for _, ev := range r.Schedule {
+			if ev.RunType == 0 {
+				continue // garage openings, driver intros and the like
+			}
+			t, err := time.Parse("2006-01-02T15:04:05", ev.StartTimeUTC)
+			if err != nil {
+				continue
+			}
+			out = append(out, series.Session{Race: sr, Name: ev.EventName, StartTime: t})
 		}
-		if lat, lon, ok := TrackCoords(r.TrackID); ok {
-			sr.Lat, sr.Lon = lat, lon
+		if len(out) == n && !sr.StartTime.IsZero() {
+			out = append(out, series.Session{Race: sr, Name: "Race", StartTime: sr.StartTime})
 		}
-		out = append(out, sr)
 	}
 	return out, nil
 }
 
-func (s *NASCARSeries) nextRaceStart() time.Time {
-	races, err := FetchCupSchedule(timeNow().Year())
-	if err != nil {
-		return time.Time{}
+// race converts a schedule entry.
+func (s *NASCARSeries) race(r Race) series.Race {
+	sr := series.Race{
+		SeriesName:  s.Name(),
+		ShortName:   s.ShortName(),
+		RaceName:    r.RaceName,
+		TrackName:   r.TrackName,
+		Broadcaster: r.TelevisionBroadcaster,
+		Complete:    r.IsComplete(),
 	}
-	next := NextRace(races)
-	if next == nil {
-		return time.Time{}
+	if t, err := r.RaceStartUTC(); err == nil {
+		sr.StartTime = t
 	}
-	t, err := next.RaceStartUTC()
-	if err != nil {
+	if lat, lon, ok := TrackCoords(r.TrackID); ok {
+		sr.Lat, sr.Lon = lat, lon
+	}
+	return sr
+}
+
+// nextRaceStart returns when the next race starts, or started if it may
+// still be running, for sizing the live feed's cache TTL.
+func (s *NASCARSeries) nextRaceStart(ctx context.Context) time.Time {
+	next, err := series.NextRace(ctx, s, timeNow().Add(-6*time.Hour), series.DefaultHorizon)
+	if err != nil || next == nil {
 		return time.Time{}
 	}
-	return t
+	return next.StartTime
 }
 
-func (s *NASCARSeries) FetchLiveState() (*series.LiveState, error) {
-	feed, err := fetchLiveFeedCached(s.nextRaceStart())
+func (s *NASCARSeries) FetchLiveState(ctx context.Context) (*series.LiveState, error) {
+	feed, err := fetchLiveFeedCached(ctx, s.nextRaceStart(ctx))
 	if err != nil {
-		return nil, nil
+		return nil, err
 	}
 	if !feed.IsLiveCupRace() {
 		return nil, nil
 	}
 
-	if feed.IsFinished() && s.raceOver(feed.RaceID) {
+	if feed.IsFinished() && s.raceOver(ctx, feed.RaceID) {
 		return nil, nil
 	}
 
@@ -86,6 +117,7 @@ func (s *NASCARSeries) FetchLiveState() (*series.LiveState, error) {
 		TotalLaps:  feed.LapsInRace,
 		FlagSymbol: FlagSymbol(feed.FlagState),
 		FlagName:   flagName(feed.FlagState),
+		Stage:      feed.Stage.StageNum,
 		Finished:   feed.IsFinished(),
 	}
 
@@ -106,6 +138,7 @@ func (s *NASCARSeries) FetchLiveState() (*series.LiveState, error) {
 	for i := range sorted {
 		state.Positions[i] = vehicleToDriver(&sorted[i])
 	}
+	setGaps(state.Positions, sorted)
 
 	return state, nil
 }
@@ -117,11 +150,52 @@ func vehicleToDriver(v *Vehicle) series.Driver {
 		FullName: v.Driver.FullName,
 		Position: v.RunningPosition,
 		Delta:    float64(v.RunningPosition - v.StartingPosition),
+		PitStops: v.PitCount(),
+
+		LastPitLap: v.LastPitLap(),
+		Make:       v.Make(),
 	}
 }
 
-func scheduleCacheKey() string {
-	return fmt.Sprintf("schedule_%d.json", timeNow().Year())
+// setGaps fills in the gaps between neighbours, laps down and the lucky
+// dog order for drivers, which are built from sorted (in running order).
+//
+// The feed's delta is the time behind the leader for cars on the lead lap;
+// for lapped cars it holds the laps down instead, as a negative number, so
+// laps are taken from it and time gaps are only given between lead-lap
+// cars. (laps_completed won't do: lead-lap cars trail the leader's count
+// until they cross the line themselves.) The lucky dog (free pass) goes to
+// the first lapped car in the running order.
+func setGaps(drivers []series.Driver, sorted []Vehicle) {
+	if len(sorted) == 0 {
+		return
+	}
+	for i := range drivers {
+		drivers[i].LapsDown = max(int(math.Round(-sorted[i].Delta)), 0)
+	}
+	gap := func(ahead, behind int) string {
+		if laps := drivers[behind].LapsDown - drivers[ahead].LapsDown; laps > 0 {
+			return series.FormatGap(0, laps)
+		}
+		if drivers[behind].LapsDown > 0 {
+			return ""
+		}
+		return series.FormatGap(math.Abs(sorted[behind].Delta)-math.Abs(sorted[ahead].Delta), 0)
+	}
+	inLine := 0
+	for i := range drivers {
+		if i > 0 {
+			drivers[i].Gap = gap(0, i)
+			drivers[i].GapAhead = gap(i-1, i)
+		}
+		if i < len(drivers)-1 {
+			drivers[i].GapBehind = gap(i, i+1)
+		}
+		if drivers[i].LapsDown > 0 {
+			inLine++
+			drivers[i].LuckyDog = inLine
+		}
+	}
 }
 
 // raceOver returns true when we should stop displaying a finished race.
@@ -129,13 +203,13 @@ func scheduleCacheKey() string {
 // API confirms a winner (WinnerDriverID set). Either is sufficient when
 // combined with IsFinished from the live feed.
 //
-// On first call after the race finishes, the schedule cache is invalidated
-// so the next fetch can pick up WinnerDriverID from the API.
-func (s *NASCARSeries) raceOver(raceID int) bool {
-	fileCache.Invalidate(scheduleCacheKey())
-
+// While the race is finished but not yet over, the schedule is refetched
+// on every call so WinnerDriverID is picked up as soon as the API sets it.
+// If that fetch fails the cached schedule is used, so the grace period
+// still expires offline.
+func (s *NASCARSeries) raceOver(ctx context.Context, raceID int) bool {
 	year := timeNow().Year()
-	races, err := FetchCupSchedule(year)
+	races, err := fetchCupSchedule(ctx, year, 0)
 	if err != nil {
 		return false
 	}
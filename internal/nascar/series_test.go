@@ -0,0 +1,81 @@
+package nascar
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/mockserver"
+	"github.com/jfmyers/tmux-raceday/internal/series"
+)
+
+func TestSetGaps(t *testing.T) {
+	sorted := []Vehicle{
+		{RunningPosition: 1, VehicleNumber: "8", Delta: 0, LapsCompleted: 142},
+		{RunningPosition: 2, VehicleNumber: "24", Delta: 0.412, LapsCompleted: 142},
+		{RunningPosition: 3, VehicleNumber: "11", Delta: 1.087, LapsCompleted: 142},
+		{RunningPosition: 4, VehicleNumber: "48", Delta: -1, LapsCompleted: 141},
+		{RunningPosition: 5, VehicleNumber: "5", Delta: -1, LapsCompleted: 141},
+		{RunningPosition: 6, VehicleNumber: "9", Delta: -3, LapsCompleted: 139},
+	}
+	drivers := make([]series.Driver, len(sorted))
+	for i := range sorted {
+		drivers[i] = vehicleToDriver(&sorted[i])
+	}
+	setGaps(drivers, sorted)
+
+	want := []struct {
+		gap, ahead, behind string
+		lapsDown, luckyDog int
+	}{
+		{"", "", "+0.412", 0, 0},
+		{"+0.412", "+0.412", "+0.675", 0, 0},
+		{"+1.087", "+0.675", "+1 LAP", 0, 0},
+		{"+1 LAP", "+1 LAP", "", 1, 1},
+		{"+1 LAP", "", "+2 LAPS", 1, 2},
+		{"+3 LAPS", "+2 LAPS", "", 3, 3},
+	}
+	for i, w := range want {
+		d := drivers[i]
+		if d.Gap != w.gap || d.GapAhead != w.ahead || d.GapBehind != w.behind ||
+			d.LapsDown != w.lapsDown || d.LuckyDog != w.luckyDog {
+			t.Errorf("#%s: got gap %q ahead %q behind %q down %d lucky dog %d, want %+v",
+				d.Number, d.Gap, d.GapAhead, d.GapBehind, d.LapsDown, d.LuckyDog, w)
+		}
+	}
+
+	// The leader has crossed the line but the cars behind it on the lead
+	// lap haven't yet: they are still on the lead lap.
+	sorted[0].LapsCompleted = 143
+	setGaps(drivers, sorted)
+	for i, w := range want {
+		if d := drivers[i]; d.LapsDown != w.lapsDown || d.LuckyDog != w.luckyDog || d.Gap != w.gap {
+			t.Errorf("mid-crossing #%s: got gap %q down %d lucky dog %d, want %+v", d.Number, d.Gap, d.LapsDown, d.LuckyDog, w)
+		}
+	}
+}
+
+func TestFetchSessions(t *testing.T) {
+	useMockServer(t, mockserver.Options{})
+
+	sessions, err := NewSeries().FetchSessions(context.Background(), 2026)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range sessions[:4] {
+		got = append(got, fmt.Sprintf("%s %s %s", s.Race.RaceName, s.Name, s.StartTime.Format(time.RFC3339)))
+	}
+	want := "DAYTONA 500 Practice 2026-02-11T22:05:00Z, " +
+		"DAYTONA 500 Qualifying 2026-02-12T01:15:00Z, " +
+		"DAYTONA 500 Race 2026-02-15T19:30:00Z, " +
+		"Ambetter Health 400 Race 2026-02-22T20:00:00Z"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("sessions = %q\nwant       %q", strings.Join(got, ", "), want)
+	}
+	if r := sessions[0].Race; r.Broadcaster != "FOX" || r.Lat == 0 {
+		t.Errorf("race = %+v", r)
+	}
+}
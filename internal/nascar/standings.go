@@ -1,13 +1,13 @@
 package nascar
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-)
 
-const pointsURL = "https://cf.nascar.com/live/feeds/live-points.json"
+	"github.com/jfmyers/tmux-raceday/internal/fetch"
+	"github.com/jfmyers/tmux-raceday/internal/schema"
+)
 
 type PointsEntry struct {
 	CarNumber        string `json:"car_number"`
@@ -33,29 +33,29 @@ type PointsEntry struct {
 
 // FetchStandings retrieves the current points standings.
 // Results are served from a local file cache when fresh.
-func FetchStandings() ([]PointsEntry, error) {
+func FetchStandings(ctx context.Context) ([]PointsEntry, error) {
 	const cacheKey = "live-points.json"
 
 	if data, ok := fileCache.Read(cacheKey, cacheTTL); ok {
 		return parseStandings(data)
 	}
 
-	resp, err := httpClient.Get(pointsURL)
-	if err != nil {
-		return nil, fmt.Errorf("fetching standings: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("standings API returned %d", resp.StatusCode)
-	}
-
-	data, err := io.ReadAll(resp.Body)
+	data, err := fileCache.Refresh(ctx, cacheKey, cacheTTL, func() ([]byte, error) {
+		data, err := fetch.Get(ctx, pointsURL())
+		if err != nil {
+			return nil, fmt.Errorf("fetching standings: %w", err)
+		}
+		schema.Record(FeedPoints, ValidateStandings(data))
+		return data, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("reading standings: %w", err)
+		// Fall back to stale cache on API failure.
+		if stale := fileCache.Fallback(ctx, cacheKey); stale != nil {
+			return parseStandings(stale)
+		}
+		return nil, err
 	}
 
-	_ = fileCache.Write(cacheKey, data)
 	return parseStandings(data)
 }
 
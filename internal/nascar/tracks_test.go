@@ -1,7 +1,11 @@
 package nascar
 
 import (
+	"context"
 	"testing"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/mockserver"
 )
 
 func TestTrackCoords_KnownTrack(t *testing.T) {
@@ -25,10 +29,21 @@ func TestAllScheduleTracksHaveCoords(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test in short mode")
 	}
+	checkScheduleTrackCoords(t, time.Now().Year())
+}
+
+func TestFixtureScheduleTracksHaveCoords(t *testing.T) {
+	useMockServer(t, mockserver.Options{})
+	checkScheduleTrackCoords(t, 2026)
+}
 
-	races, err := FetchCupSchedule(2025)
+// checkScheduleTrackCoords checks that every track on year's schedule has
+// coordinates for the weather lookup.
+func checkScheduleTrackCoords(t *testing.T, year int) {
+	t.Helper()
+	races, err := FetchCupSchedule(context.Background(), year)
 	if err != nil {
-		t.Fatalf("fetching 2025 schedule: %v", err)
+		t.Fatalf("fetching %d schedule: %v", year, err)
 	}
 	if len(races) == 0 {
 		t.Fatal("schedule returned 0 races")
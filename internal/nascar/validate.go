@@ -0,0 +1,158 @@
+package nascar
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/schema"
+)
+
+// Feed names used in schema reports.
+const (
+	FeedSchedule = "nascar schedule"
+	FeedLiveFeed = "nascar live feed"
+	FeedPoints   = "nascar points"
+	FeedWeekend  = "nascar weekend feed"
+)
+
+// maxOvertimeLaps bounds how far past laps_in_race a feed may run.
+const maxOvertimeLaps = 30
+
+// ValidateLiveFeed checks a live-feed.json payload for the fields and
+// value ranges raceday relies on.
+func ValidateLiveFeed(data []byte) error {
+	c := &schema.Checker{}
+	obj, err := schema.Object(data)
+	if err != nil {
+		c.Addf("", "invalid JSON: %v", err)
+		return c.Err(FeedLiveFeed)
+	}
+	c.Keys("", obj, "lap_number", "laps_in_race", "laps_to_go", "flag_state",
+		"race_id", "track_id", "run_type", "series_id", "run_name", "vehicles")
+	if vehicles := c.Objects("vehicles", obj["vehicles"]); len(vehicles) > 0 {
+		c.Keys("vehicles[0]", vehicles[0], "running_position", "vehicle_number",
+			"driver", "delta", "laps_completed", "starting_position", "pit_stops")
+		if d, ok := vehicles[0]["driver"].(map[string]any); ok {
+			c.Keys("vehicles[0].driver", d, "full_name", "last_name")
+		}
+	}
+
+	var feed LiveFeed
+	if err := json.Unmarshal(data, &feed); err != nil {
+		c.Addf("", "decoding: %v", err)
+		return c.Err(FeedLiveFeed)
+	}
+	maxLap := 1000
+	if feed.LapsInRace > 0 {
+		maxLap = feed.LapsInRace + maxOvertimeLaps
+	}
+	c.Range("lap_number", feed.LapNumber, 0, maxLap)
+	c.Range("laps_to_go", feed.LapsToGo, 0, maxLap)
+	c.Range("flag_state", feed.FlagState, 0, FlagWhite)
+	n := len(feed.Vehicles)
+	for i, v := range feed.Vehicles {
+		path := fmt.Sprintf("vehicles[%d]", i)
+		c.Range(path+".running_position", v.RunningPosition, 1, n)
+		c.NonEmpty(path+".vehicle_number", v.VehicleNumber)
+		c.NonEmpty(path+".driver.last_name", v.Driver.LastName)
+	}
+	return c.Err(FeedLiveFeed)
+}
+
+// ValidateSchedule checks a race_list_basic.json payload.
+func ValidateSchedule(data []byte) error {
+	c := &schema.Checker{}
+	obj, err := schema.Object(data)
+	if err != nil {
+		c.Addf("", "invalid JSON: %v", err)
+		return c.Err(FeedSchedule)
+	}
+	c.Keys("", obj, "series_1")
+	if races := c.Objects("series_1", obj["series_1"]); len(races) > 0 {
+		c.Keys("series_1[0]", races[0], "race_id", "race_name", "track_id", "track_name",
+			"date_scheduled", "winner_driver_id", "television_broadcaster", "schedule")
+	} else if _, ok := obj["series_1"]; ok {
+		c.Addf("series_1", "no races")
+	}
+
+	var resp ScheduleResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		c.Addf("", "decoding: %v", err)
+		return c.Err(FeedSchedule)
+	}
+	for i, r := range resp.Series1 {
+		path := fmt.Sprintf("series_1[%d]", i)
+		if r.RaceID <= 0 {
+			c.Addf(path+".race_id", "not set")
+		}
+		c.NonEmpty(path+".race_name", r.RaceName)
+		if _, err := r.RaceStartUTC(); err != nil {
+			c.Addf(path+".date_scheduled", "unparseable start time %q", r.DateScheduled)
+		}
+	}
+	return c.Err(FeedSchedule)
+}
+
+// ValidateStandings checks a live-points.json payload.
+func ValidateStandings(data []byte) error {
+	c, n := schema.Records(data, "car_number", "first_name", "last_name",
+		"driver_id", "points", "points_position", "delta_leader", "wins")
+	var entries []PointsEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		c.Addf("", "decoding: %v", err)
+		return c.Err(FeedPoints)
+	}
+	for i, e := range entries {
+		path := fmt.Sprintf("[%d]", i)
+		c.Range(path+".points_position", e.PointsPosition, 0, n)
+		c.Range(path+".points", e.Points, 0, 10000)
+		c.NonEmpty(path+".last_name", e.LastName)
+	}
+	return c.Err(FeedPoints)
+}
+
+// ValidateWeekendFeed checks a weekend-feed.json payload. Results are
+// empty until the race is run.
+func ValidateWeekendFeed(data []byte) error {
+	c := &schema.Checker{}
+	obj, err := schema.Object(data)
+	if err != nil {
+		c.Addf("", "invalid JSON: %v", err)
+		return c.Err(FeedWeekend)
+	}
+	c.Keys("", obj, "weekend_race")
+	if races := c.Objects("weekend_race", obj["weekend_race"]); len(races) > 0 {
+		c.Keys("weekend_race[0]", races[0], "race_id", "results")
+		if results := c.Objects("weekend_race[0].results", races[0]["results"]); len(results) > 0 {
+			c.Keys("weekend_race[0].results[0]", results[0], "driver_fullname", "car_number",
+				"finishing_position", "points_earned")
+		}
+	}
+
+	var feed WeekendFeed
+	if err := json.Unmarshal(data, &feed); err != nil {
+		c.Addf("", "decoding: %v", err)
+		return c.Err(FeedWeekend)
+	}
+	for i, wr := range feed.WeekendRace {
+		n := len(wr.Results)
+		for j, r := range wr.Results {
+			path := fmt.Sprintf("weekend_race[%d].results[%d]", i, j)
+			c.Range(path+".finishing_position", r.FinishingPosition, 1, n)
+			c.Range(path+".points_earned", r.PointsEarned, 0, 100)
+			c.NonEmpty(path+".car_number", r.CarNumber)
+		}
+	}
+	return c.Err(FeedWeekend)
+}
+
+// FeedChecks lists the NASCAR feeds with their validators, for
+// diagnostics that fetch them directly.
+func FeedChecks(now time.Time) []schema.Check {
+	return []schema.Check{
+		{Feed: FeedSchedule, URL: scheduleURL(now.Year()), Validate: ValidateSchedule},
+		{Feed: FeedLiveFeed, URL: liveFeedURL(), Validate: ValidateLiveFeed},
+		{Feed: FeedPoints, URL: pointsURL(), Validate: ValidateStandings},
+	}
+}
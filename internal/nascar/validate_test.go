@@ -0,0 +1,97 @@
+package nascar
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/mockserver"
+	"github.com/jfmyers/tmux-raceday/internal/schema"
+)
+
+func getBody(t *testing.T, url string) []byte {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestValidators(t *testing.T) {
+	ts := useMockServer(t, mockserver.Options{})
+	now := time.Date(2026, 2, 22, 20, 0, 0, 0, time.UTC)
+
+	for _, check := range FeedChecks(now) {
+		if err := check.Validate(getBody(t, check.URL)); err != nil {
+			t.Errorf("%s fixture: %v", check.Feed, err)
+		}
+	}
+
+	for _, mode := range []string{mockserver.DriftRename, mockserver.DriftRetype, mockserver.DriftTruncate} {
+		ts.SetDrift(mockserver.AllRoutes, mode)
+		for _, check := range FeedChecks(now) {
+			if err := check.Validate(getBody(t, check.URL)); err == nil {
+				t.Errorf("%s with %s drift: no error", check.Feed, mode)
+			}
+		}
+	}
+}
+
+func TestValidateLiveFeedRanges(t *testing.T) {
+	data := []byte(`{"lap_number": 400, "laps_in_race": 200, "laps_to_go": 0, "flag_state": 1,
+		"race_id": 1, "track_id": 105, "run_type": 3, "series_id": 1, "run_name": "X",
+		"vehicles": [{"running_position": 3, "vehicle_number": "", "driver": {"full_name": "A B", "last_name": "B"},
+			"delta": 0, "laps_completed": 1, "starting_position": 1, "pit_stops": []}]}`)
+	err := ValidateLiveFeed(data)
+	se, ok := err.(*schema.Error)
+	if !ok {
+		t.Fatalf("err = %v, want *schema.Error", err)
+	}
+	want := map[string]bool{"lap_number": true, "vehicles[0].running_position": true, "vehicles[0].vehicle_number": true}
+	for _, is := range se.Issues {
+		if !want[is.Path] {
+			t.Errorf("unexpected issue %s", is)
+		}
+		delete(want, is.Path)
+	}
+	for path := range want {
+		t.Errorf("missing issue for %s", path)
+	}
+}
+
+func TestFetchRecordsSchemaProblems(t *testing.T) {
+	ts := useMockServer(t, mockserver.Options{Drift: map[string]string{"live-feed": mockserver.DriftRename}})
+	defer schema.Record(FeedLiveFeed, nil)
+
+	hasProblem := func() bool {
+		for _, p := range schema.Problems() {
+			if p.Feed == FeedLiveFeed {
+				return true
+			}
+		}
+		return false
+	}
+
+	if _, err := FetchLiveFeed(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !hasProblem() {
+		t.Error("drifted live feed not recorded")
+	}
+
+	ts.SetDrift("live-feed", "")
+	if _, err := FetchLiveFeed(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if hasProblem() {
+		t.Error("problem not cleared after a valid live feed")
+	}
+}
@@ -1,23 +1,196 @@
+// Package notify sends race events to sinks: tmux, the desktop, webhooks,
+// push services and shell commands.
 package notify
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os/exec"
-	"runtime"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/events"
 )
 
-// Tmux sends a message to the tmux status line.
-func Tmux(msg string) {
-	_ = exec.Command("tmux", "display-message", msg).Run()
+// Sink delivers events to one place.
+type Sink interface {
+	// Name identifies the sink in logs, e.g. "webhook hooks.slack.com".
+	Name() string
+	Send(ctx context.Context, e events.Event) error
+}
+
+// Wants reports whether cfg's switches ask to be told about events of
+// kind k. Sinks without their own event list follow them.
+func Wants(cfg config.Notify, k events.Kind) bool {
+	switch k {
+	case events.Caution, events.Green, events.RedFlag, events.WhiteFlag:
+		return cfg.Cautions
+	case events.LeadChange:
+		return cfg.LeadChanges
+	case events.Pit:
+		return cfg.Pits
+	case events.Position:
+		return cfg.Positions
+	case events.StageEnd:
+		return cfg.Stages
+	case events.Finish:
+		return cfg.Finish
+	case events.Reminder:
+		return len(cfg.Reminders) > 0
+	case events.RuleMatch:
+		return len(cfg.Rules) > 0
+	}
+	return false
+}
+
+type route struct {
+	sink  Sink
+	wants func(events.Kind) bool
+}
+
+// Dispatcher sends each event to the sinks that want it.
+type Dispatcher struct {
+	routes []route
+	policy policy
+	// logPath is where deliveries are recorded; empty for none, which
+	// also turns off the policy.
+	logPath string
+}
+
+// Add routes the events wants accepts to s.
+func (d *Dispatcher) Add(s Sink, wants func(events.Kind) bool) {
+	d.routes = append(d.routes, route{s, wants})
+}
+
+// Wants reports whether any sink wants events of kind k.
+func (d *Dispatcher) Wants(k events.Kind) bool {
+	for _, r := range d.routes {
+		if r.wants(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Send delivers evs to every sink that wants them, along with digests
+// of events held back earlier; evs may be empty to send just those.
+// Sinks are sent to concurrently, each in event order; failures are
+// logged.
+func (d *Dispatcher) Send(ctx context.Context, evs []events.Event) {
+	batches := d.plan(ctx, evs)
+	var wg sync.WaitGroup
+	for i, r := range d.routes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, e := range batches[i] {
+				if err := r.sink.Send(ctx, e); err != nil {
+					slog.Warn("notify failed", "sink", r.sink.Name(), "kind", e.Kind, "err", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// plan returns the events to send each route, following the policy and
+// the delivery log, which it updates. The log is locked meanwhile, so
+// each event is sent once however many processes found it. Without the
+// log every route gets the events it wants.
+func (d *Dispatcher) plan(ctx context.Context, evs []events.Event) [][]events.Event {
+	batches := make([][]events.Event, len(d.routes))
+	if d.logPath != "" {
+		unlock, err := cache.Lock(ctx, d.logPath)
+		if err == nil {
+			defer unlock()
+			now := timeNow()
+			log := loadLog(d.logPath)
+			seen := map[string]int{}
+			for i, r := range d.routes {
+				// Sinks are told apart by name, and by order among namesakes.
+				name := r.sink.Name()
+				if seen[name]++; seen[name] > 1 {
+					name += fmt.Sprintf(" #%d", seen[name])
+				}
+				batches[i] = log.sink(name).plan(evs, r.wants, d.policy, now)
+			}
+			if err := log.save(d.logPath); err != nil {
+				slog.Warn("notify log not saved", "err", err)
+			}
+			return batches
+		}
+		slog.Warn("notify log unavailable, sending without it", "err", err)
+	}
+	for i, r := range d.routes {
+		for _, e := range evs {
+			if r.wants(e.Kind) {
+				batches[i] = append(batches[i], e)
+			}
+		}
+	}
+	return batches
+}
+
+// New builds the dispatcher for cfg: its sinks, or tmux and (with
+// Desktop) the desktop if none are listed, with cfg's quiet hours and
+// cooldowns and the delivery log at LogPath. Sinks that need the network
+// are left out when offline. Misconfigured sinks are left out too and
+// reported in the error, as are misconfigured policies.
+func New(cfg config.Notify, offline bool) (*Dispatcher, error) {
+	p, err := newPolicy(cfg)
+	d := &Dispatcher{policy: p, logPath: LogPath()}
+	defaults := func(k events.Kind) bool { return Wants(cfg, k) }
+	if len(cfg.Sinks) == 0 {
+		d.Add(tmuxSink{}, defaults)
+		if cfg.Desktop {
+			d.Add(desktopSink{}, defaults)
+		}
+		return d, err
+	}
+	errs := []error{err}
+	for i, sc := range cfg.Sinks {
+		s, err := newSink(sc)
+		if err == nil {
+			err = checkKinds(sc.Events)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("notify.sinks[%d]: %w", i, err))
+			continue
+		}
+		if offline && isNetwork(sc.Type) {
+			slog.Debug("notify sink skipped offline", "sink", s.Name())
+			continue
+		}
+		wants := defaults
+		if len(sc.Events) > 0 {
+			kinds := sc.Events
+			wants = func(k events.Kind) bool {
+				return slices.Contains(kinds, string(k)) || slices.Contains(kinds, "all")
+			}
+		}
+		d.Add(s, wants)
+	}
+	return d, errors.Join(errs...)
+}
+
+// checkKinds reports event names raceday doesn't know.
+func checkKinds(names []string) error {
+	for _, n := range names {
+		if n != "all" && !slices.Contains(events.Kinds, events.Kind(n)) {
+			return fmt.Errorf("unknown event %q (want all or one of %s)", n, kindList())
+		}
+	}
+	return nil
 }
 
-// Desktop sends a native desktop notification.
-func Desktop(title, msg string) {
-	switch runtime.GOOS {
-	case "darwin":
-		script := fmt.Sprintf(`display notification %q with title %q`, msg, title)
-		_ = exec.Command("osascript", "-e", script).Run()
-	case "linux":
-		_ = exec.Command("notify-send", title, msg).Run()
+func kindList() string {
+	names := make([]string, len(events.Kinds))
+	for i, k := range events.Kinds {
+		names[i] = string(k)
 	}
+	return strings.Join(names, ", ")
 }
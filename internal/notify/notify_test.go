@@ -0,0 +1,405 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/events"
+	"github.com/jfmyers/tmux-raceday/internal/series"
+)
+
+var caution = events.Event{
+	Kind: events.Caution, Series: "NASCAR", Race: "Würth 400", Lap: 57,
+	FlagSymbol: "🟡", FlagName: "Caution",
+}
+
+var pit = events.Event{
+	Kind: events.Pit, Series: "NASCAR", Race: "Würth 400", Lap: 80,
+	Driver: series.Driver{Number: "24", Name: "Byron", Position: 3},
+}
+
+func names(d *Dispatcher) []string {
+	var out []string
+	for _, r := range d.routes {
+		out = append(out, r.sink.Name())
+	}
+	return out
+}
+
+func TestNew(t *testing.T) {
+	cfg := config.Notify{Cautions: true, Desktop: true}
+	d, err := New(cfg, false)
+	if err != nil || !slices.Equal(names(d), []string{"tmux", "desktop"}) {
+		t.Errorf("defaults = %v, %v", names(d), err)
+	}
+	if !d.Wants(events.Caution) || d.Wants(events.Pit) {
+		t.Error("default sinks should follow the notify switches")
+	}
+	if d.Wants(events.Reminder) {
+		t.Error("reminders should be off without offsets")
+	}
+
+	cfg.Sinks = []config.Sink{
+		{Type: "webhook", URL: "https://hooks.slack.com/services/x", Format: "slack", Events: []string{"pit"}},
+		{Type: "ntfy", URL: "https://ntfy.sh/raceday"},
+		{Type: "command", Command: "true", Events: []string{"all"}},
+		{Type: "webhook"},
+		{Type: "pager", URL: "https://example.com"},
+		{Type: "tmux", Events: []string{"yellow"}},
+		{Type: "webhook", URL: "https://example.com", Format: "teams"},
+	}
+	d, err = New(cfg, false)
+	want := []string{"webhook hooks.slack.com", "ntfy ntfy.sh", "command true"}
+	if !slices.Equal(names(d), want) {
+		t.Errorf("sinks = %v, want %v", names(d), want)
+	}
+	for _, msg := range []string{
+		`notify.sinks[3]: webhook: url is required`,
+		`notify.sinks[4]: unknown type "pager"`,
+		`notify.sinks[5]: unknown event "yellow"`,
+		`notify.sinks[6]: webhook: unknown format "teams"`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("error %v does not mention %q", err, msg)
+		}
+	}
+	if r := d.routes[0]; !r.wants(events.Pit) || r.wants(events.Caution) {
+		t.Error("sink events should replace the notify switches")
+	}
+	if r := d.routes[2]; !r.wants(events.Position) {
+		t.Error("all should match every event")
+	}
+
+	// Offline, only local sinks are kept.
+	d, _ = New(cfg, true)
+	if !slices.Equal(names(d), []string{"command true"}) {
+		t.Errorf("offline sinks = %v", names(d))
+	}
+}
+
+// capture serves one request and records it.
+func capture(t *testing.T) (*httptest.Server, *http.Request, *string) {
+	t.Helper()
+	var req http.Request
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		req, body = *r, string(b)
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &req, &body
+}
+
+func TestWebhook(t *testing.T) {
+	tests := []struct {
+		format, template, want string
+	}{
+		{"", "", `{"kind":"pit","series":"NASCAR","race":"Würth 400","lap":80,"title":"NASCAR · Würth 400",` +
+			`"message":"#24 Byron pits, lap 80","driver":{"number":"24","name":"Byron","position":3}}` + "\n"},
+		{"slack", "", `{"text": "*NASCAR · Würth 400*: #24 Byron pits, lap 80"}`},
+		{"discord", "", `{"content": "**NASCAR · Würth 400**: #24 Byron pits, lap 80"}`},
+		{"", `{"msg": {{json .Message}}, "car": {{json .Driver.Number}}}`, `{"msg": "#24 Byron pits, lap 80", "car": "24"}`},
+	}
+	for _, tt := range tests {
+		ts, req, body := capture(t)
+		s, err := newSink(config.Sink{Type: "webhook", URL: ts.URL, Format: tt.format, Template: tt.template})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Send(context.Background(), pit); err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		if *body != tt.want || req.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("format %q posted %q (%s)\nwant %q", tt.format, *body, req.Header.Get("Content-Type"), tt.want)
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer ts.Close()
+	s, _ := newSink(config.Sink{Type: "webhook", URL: ts.URL})
+	if err := s.Send(context.Background(), pit); err == nil || !strings.Contains(err.Error(), "invalid_token") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestReminderPayload(t *testing.T) {
+	ts, _, body := capture(t)
+	s, _ := newSink(config.Sink{Type: "webhook", URL: ts.URL})
+	e := events.Event{
+		Kind: events.Reminder, Series: "NASCAR", Race: "DAYTONA 500", Session: "Race",
+		Start: time.Date(2026, 2, 15, 19, 30, 0, 0, time.UTC), Until: 10 * time.Minute, Broadcaster: "FOX",
+	}
+	if err := s.Send(context.Background(), e); err != nil {
+		t.Fatal(err)
+	}
+	want := `"session":"Race","start":"2026-02-15T19:30:00Z","broadcaster":"FOX"}`
+	if !strings.Contains(*body, want) {
+		t.Errorf("posted %q, want it to end %q", *body, want)
+	}
+}
+
+func TestPush(t *testing.T) {
+	ts, req, body := capture(t)
+	s, _ := newSink(config.Sink{Type: "ntfy", URL: ts.URL + "/raceday", Token: "tk_1"})
+	if err := s.Send(context.Background(), caution); err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.Path != "/raceday" || *body != "🟡 Caution, lap 57" || req.Header.Get("Title") != "NASCAR · Würth 400" ||
+		req.Header.Get("Priority") != "high" || req.Header.Get("Tags") != "caution" || req.Header.Get("Authorization") != "Bearer tk_1" {
+		t.Errorf("ntfy got %s %q headers %v", req.URL.Path, *body, req.Header)
+	}
+
+	s, _ = newSink(config.Sink{Type: "gotify", URL: ts.URL + "/", Token: "app"})
+	if err := s.Send(context.Background(), pit); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"message":"#24 Byron pits, lap 80","priority":5,"title":"NASCAR · Würth 400"}`
+	if req.URL.Path != "/message" || *body != want || req.Header.Get("X-Gotify-Key") != "app" {
+		t.Errorf("gotify got %s %q headers %v", req.URL.Path, *body, req.Header)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	s, _ := newSink(config.Sink{Type: "command", Command: `echo "$RACEDAY_EVENT $RACEDAY_DRIVER_NUMBER P$RACEDAY_POSITION: $RACEDAY_MESSAGE" > ` + out})
+	if err := s.Send(context.Background(), pit); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(out)
+	if want := "pit 24 P3: #24 Byron pits, lap 80\n"; string(got) != want {
+		t.Errorf("command wrote %q, want %q", got, want)
+	}
+
+	s, _ = newSink(config.Sink{Type: "command", Command: "echo broken >&2; exit 3"})
+	if err := s.Send(context.Background(), pit); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestDispatcherSend(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	var ran [][]string
+	run = func(cmd *exec.Cmd) error {
+		ran = append(ran, cmd.Args)
+		return nil
+	}
+	t.Cleanup(func() { run = func(cmd *exec.Cmd) error { return cmd.Run() } })
+
+	d, _ := New(config.Notify{Cautions: true}, false)
+	d.Send(context.Background(), []events.Event{caution, pit})
+	want := []string{"tmux", "display-message", "NASCAR · Würth 400: 🟡 Caution, lap 57"}
+	if len(ran) != 1 || !slices.Equal(ran[0], want) {
+		t.Errorf("ran %q, want only %q", ran, want)
+	}
+}
+
+// recordSink collects the messages it is sent.
+type recordSink struct {
+	mu  sync.Mutex
+	got []string
+}
+
+func (r *recordSink) Name() string { return "record" }
+
+func (r *recordSink) Send(_ context.Context, e events.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.got = append(r.got, e.Message())
+	return nil
+}
+
+func (r *recordSink) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	got := r.got
+	r.got = nil
+	return got
+}
+
+func leadChange(lap int, num string) events.Event {
+	return events.Event{Kind: events.LeadChange, Series: "NASCAR", Race: "Würth 400", Lap: lap, Driver: series.Driver{Number: num}}
+}
+
+func TestPolicy(t *testing.T) {
+	cfg := config.Notify{
+		QuietHours: config.QuietHours{Start: "23:00", End: "07:00", Allow: []string{"finish", "yellow"}},
+		Cooldowns:  map[string]config.Duration{"lead_change": config.Duration(time.Minute), "laps": 0},
+	}
+	_, err := New(cfg, false)
+	for _, msg := range []string{
+		`notify.quiet_hours.allow: unknown event "yellow"`,
+		`notify.cooldowns: unknown event "laps"`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("error %v does not mention %q", err, msg)
+		}
+	}
+
+	p, _ := newPolicy(cfg)
+	day := time.Date(2026, 5, 1, 0, 0, 0, 0, time.Local)
+	for _, tt := range []struct {
+		at   time.Duration
+		kind events.Kind
+		want bool
+	}{
+		{22*time.Hour + 59*time.Minute, events.Caution, false},
+		{23 * time.Hour, events.Caution, true},
+		{26 * time.Hour, events.Caution, true},
+		{26 * time.Hour, events.Finish, false},
+		{31 * time.Hour, events.Caution, false},
+	} {
+		if got := p.quiet(tt.kind, day.Add(tt.at)); got != tt.want {
+			t.Errorf("quiet(%s, %v) = %v, want %v", tt.kind, tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestCooldownDigest(t *testing.T) {
+	now := time.Date(2026, 5, 1, 18, 0, 0, 0, time.Local)
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+
+	rec := &recordSink{}
+	p, _ := newPolicy(config.Notify{Cooldowns: map[string]config.Duration{"all": config.Duration(time.Minute)}})
+	d := &Dispatcher{policy: p, logPath: filepath.Join(t.TempDir(), "notify.json")}
+	d.Add(rec, func(events.Kind) bool { return true })
+
+	step := func(after time.Duration, evs ...events.Event) []string {
+		now = now.Add(after)
+		d.Send(context.Background(), evs)
+		return rec.take()
+	}
+	if got := step(0, leadChange(210, "5"), caution); len(got) != 2 {
+		t.Errorf("first events = %q", got)
+	}
+	if got := step(10*time.Second, leadChange(211, "24")); got != nil {
+		t.Errorf("within the cooldown: %q", got)
+	}
+	if got := step(10*time.Second, leadChange(212, "5"), leadChange(212, "5")); got != nil {
+		t.Errorf("within the cooldown: %q", got)
+	}
+	want := []string{"2 lead changes in the last 2 laps; latest #5 takes the lead, lap 212"}
+	if got := step(45 * time.Second); !slices.Equal(got, want) {
+		t.Errorf("digest = %q, want %q", got, want)
+	}
+	if got := step(5 * time.Minute); got != nil {
+		t.Errorf("digest sent again: %q", got)
+	}
+}
+
+func TestLongCooldownDigest(t *testing.T) {
+	now := time.Date(2026, 5, 1, 18, 0, 0, 0, time.Local)
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+
+	rec := &recordSink{}
+	p, _ := newPolicy(config.Notify{Cooldowns: map[string]config.Duration{"lead_change": config.Duration(15 * time.Minute)}})
+	d := &Dispatcher{policy: p, logPath: filepath.Join(t.TempDir(), "notify.json")}
+	d.Add(rec, func(events.Kind) bool { return true })
+
+	step := func(after time.Duration, evs ...events.Event) []string {
+		now = now.Add(after)
+		d.Send(context.Background(), evs)
+		return rec.take()
+	}
+	step(0, leadChange(210, "5"))
+	step(time.Minute, leadChange(211, "24"))
+	step(time.Minute, leadChange(212, "5"))
+	// The burst is older than events.MaxGap when the cooldown ends, but
+	// it was held for the digest, not forgotten.
+	want := []string{"2 lead changes in the last 2 laps; latest #5 takes the lead, lap 212"}
+	if got := step(13 * time.Minute); !slices.Equal(got, want) {
+		t.Errorf("digest = %q, want %q", got, want)
+	}
+
+	// With no status run for a while after the cooldown ends, the digest
+	// is old news.
+	step(0, leadChange(213, "24"))
+	if got := step(15*time.Minute + events.MaxGap + time.Minute); got != nil {
+		t.Errorf("late digest: %q", got)
+	}
+}
+
+func TestQuietHoursDiscard(t *testing.T) {
+	now := time.Date(2026, 5, 1, 22, 59, 0, 0, time.Local)
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+
+	rec := &recordSink{}
+	p, _ := newPolicy(config.Notify{
+		QuietHours: config.QuietHours{Start: "23:00", End: "23:10"},
+		Cooldowns:  map[string]config.Duration{"all": config.Duration(time.Minute)},
+	})
+	d := &Dispatcher{policy: p, logPath: filepath.Join(t.TempDir(), "notify.json")}
+	d.Add(rec, func(events.Kind) bool { return true })
+
+	step := func(after time.Duration, evs ...events.Event) []string {
+		now = now.Add(after)
+		d.Send(context.Background(), evs)
+		return rec.take()
+	}
+	if got := step(0, leadChange(210, "5")); len(got) != 1 {
+		t.Fatalf("before quiet hours: %q", got)
+	}
+	// Held for the cooldown, which ends in quiet hours.
+	if got := step(10*time.Second, leadChange(211, "24")); got != nil {
+		t.Errorf("within the cooldown: %q", got)
+	}
+	// Quiet hours drop events for good: they are neither held nor sent
+	// later, even as a digest, and a repeat is still a duplicate.
+	if got := step(2*time.Minute, caution, leadChange(212, "5")); got != nil {
+		t.Errorf("in quiet hours: %q", got)
+	}
+	if got := step(9*time.Minute, caution); got != nil {
+		t.Errorf("after quiet hours: %q", got)
+	}
+	if got := step(time.Second, leadChange(213, "24")); len(got) != 1 {
+		t.Errorf("new event after quiet hours: %q", got)
+	}
+}
+
+func TestDeliveryLogAcrossProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.json")
+	rec := &recordSink{}
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Each status process builds its own dispatcher.
+			d := &Dispatcher{logPath: path}
+			d.Add(rec, func(events.Kind) bool { return true })
+			d.Send(context.Background(), []events.Event{caution, pit})
+		}()
+	}
+	wg.Wait()
+	if got := rec.take(); len(got) != 2 {
+		t.Errorf("sent %q, want each event once", got)
+	}
+
+	// Reminders of the same session an hour and 10 minutes out both go.
+	start := time.Date(2026, 2, 15, 19, 30, 0, 0, time.UTC)
+	remind := func(until time.Duration) events.Event {
+		return events.Event{Kind: events.Reminder, Series: "NASCAR", Race: "DAYTONA 500", Session: "Race", Start: start, Until: until}
+	}
+	d := &Dispatcher{logPath: path}
+	d.Add(rec, func(events.Kind) bool { return true })
+	d.Send(context.Background(), []events.Event{remind(time.Hour)})
+	d.Send(context.Background(), []events.Event{remind(10 * time.Minute)})
+	if got := rec.take(); len(got) != 2 {
+		t.Errorf("reminders sent %q", got)
+	}
+}
@@ -0,0 +1,215 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/events"
+)
+
+// timeNow is a seam for testing time-dependent behavior.
+var timeNow = time.Now
+
+// keepSent is how long delivered events are remembered, to drop
+// duplicates from other status processes.
+const keepSent = 24 * time.Hour
+
+// LogPath returns where each sink's deliveries are recorded.
+func LogPath() string {
+	return filepath.Join(config.StateDir(), "notify.json")
+}
+
+// policy decides when each sink is sent events: not during quiet hours,
+// and no more often than the cooldowns allow.
+type policy struct {
+	quietStart, quietEnd int // minutes after midnight; equal for no quiet hours
+	allow                []events.Kind
+	cooldowns            map[string]time.Duration // by event kind or "all"
+}
+
+func newPolicy(cfg config.Notify) (policy, error) {
+	p := policy{cooldowns: map[string]time.Duration{}}
+	var errs []error
+	if q := cfg.QuietHours; q.Start != "" || q.End != "" {
+		start, err1 := time.Parse("15:04", q.Start)
+		end, err2 := time.Parse("15:04", q.End)
+		if err := errors.Join(err1, err2); err != nil {
+			errs = append(errs, fmt.Errorf("notify.quiet_hours: %w", err))
+		} else {
+			p.quietStart, p.quietEnd = start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+		}
+		if err := checkKinds(q.Allow); err != nil {
+			errs = append(errs, fmt.Errorf("notify.quiet_hours.allow: %w", err))
+		}
+		for _, k := range q.Allow {
+			p.allow = append(p.allow, events.Kind(k))
+		}
+	}
+	for k, d := range cfg.Cooldowns {
+		if err := checkKinds([]string{k}); err != nil {
+			errs = append(errs, fmt.Errorf("notify.cooldowns: %w", err))
+			continue
+		}
+		p.cooldowns[k] = time.Duration(d)
+	}
+	return p, errors.Join(errs...)
+}
+
+// quiet reports whether events of kind k are silenced at t.
+func (p policy) quiet(k events.Kind, t time.Time) bool {
+	if p.quietStart == p.quietEnd || slices.Contains(p.allow, k) || slices.Contains(p.allow, "all") {
+		return false
+	}
+	t = t.Local()
+	m := t.Hour()*60 + t.Minute()
+	if p.quietStart < p.quietEnd {
+		return m >= p.quietStart && m < p.quietEnd
+	}
+	return m >= p.quietStart || m < p.quietEnd // over midnight
+}
+
+func (p policy) cooldown(k events.Kind) time.Duration {
+	if d, ok := p.cooldowns[string(k)]; ok {
+		return d
+	}
+	return p.cooldowns["all"]
+}
+
+// held is an event waiting out its kind's cooldown.
+type held struct {
+	At    time.Time    `json:"at"`
+	Event events.Event `json:"event"`
+}
+
+// sinkLog is what one sink has been sent.
+type sinkLog struct {
+	Sent    map[string]time.Time      `json:"sent"` // event key → when
+	Last    map[events.Kind]time.Time `json:"last"` // latest delivery of each kind
+	Pending map[events.Kind][]held    `json:"pending,omitempty"`
+}
+
+// deliveryLog records each sink's deliveries between status runs, by
+// sink name.
+type deliveryLog struct {
+	Sinks map[string]*sinkLog `json:"sinks"`
+}
+
+func (l *deliveryLog) sink(name string) *sinkLog {
+	s := l.Sinks[name]
+	if s == nil {
+		s = &sinkLog{}
+		l.Sinks[name] = s
+	}
+	if s.Sent == nil {
+		s.Sent = map[string]time.Time{}
+	}
+	if s.Last == nil {
+		s.Last = map[events.Kind]time.Time{}
+	}
+	if s.Pending == nil {
+		s.Pending = map[events.Kind][]held{}
+	}
+	return s
+}
+
+// plan picks which of evs, and of the events held earlier, to send the
+// sink now, and records them as sent. Duplicates are dropped, as are
+// events in quiet hours: those are discarded, not held for a digest, as
+// the night's events are old news by morning. Events of a kind sent
+// within its cooldown are held, then sent together as a digest once it
+// has passed, unless that is in quiet hours or no status run came by
+// within events.MaxGap of the cooldown ending.
+func (s *sinkLog) plan(evs []events.Event, wants func(events.Kind) bool, p policy, now time.Time) []events.Event {
+	var out []events.Event
+	for _, k := range slices.Sorted(maps.Keys(s.Pending)) {
+		ready := s.Last[k].Add(p.cooldown(k))
+		if now.Before(ready) {
+			continue
+		}
+		hs := s.Pending[k]
+		delete(s.Pending, k)
+		if now.Sub(ready) > events.MaxGap || p.quiet(k, now) {
+			continue // old news, or quiet hours
+		}
+		digest := make([]events.Event, len(hs))
+		for i, h := range hs {
+			digest[i] = h.Event
+		}
+		s.Last[k] = now
+		out = append(out, events.Digest(digest))
+	}
+
+	for _, e := range evs {
+		if !wants(e.Kind) {
+			continue
+		}
+		key := eventKey(e)
+		if _, dup := s.Sent[key]; dup {
+			continue
+		}
+		s.Sent[key] = now
+		if p.quiet(e.Kind, now) {
+			continue
+		}
+		if now.Sub(s.Last[e.Kind]) < p.cooldown(e.Kind) {
+			s.Pending[e.Kind] = append(s.Pending[e.Kind], held{now, e})
+			continue
+		}
+		s.Last[e.Kind] = now
+		out = append(out, e)
+	}
+
+	for key, at := range s.Sent {
+		if now.Sub(at) > keepSent {
+			delete(s.Sent, key)
+		}
+	}
+	return out
+}
+
+// eventKey identifies an event, so the same one found by two status
+// processes is sent once.
+func eventKey(e events.Event) string {
+	parts := []string{
+		string(e.Kind), e.Series, e.Race, fmt.Sprint(e.Lap), e.FlagName, e.Driver.Number,
+		fmt.Sprint(e.Driver.Position), fmt.Sprint(e.From), fmt.Sprint(e.Stage), e.Rule, e.Session,
+	}
+	if !e.Start.IsZero() {
+		// Reminders of one session differ in how long is left.
+		parts = append(parts, e.Start.UTC().Format(time.RFC3339), e.Until.Round(time.Minute).String())
+	}
+	return strings.Join(parts, "|")
+}
+
+// loadLog reads the delivery log at path. A missing or unreadable log
+// is empty.
+func loadLog(path string) *deliveryLog {
+	l := &deliveryLog{}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, l); err != nil {
+			l = &deliveryLog{}
+		}
+	}
+	if l.Sinks == nil {
+		l.Sinks = map[string]*sinkLog{}
+	}
+	return l
+}
+
+// save writes l to path, replacing it atomically.
+func (l *deliveryLog) save(path string) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	return cache.WriteFile(path, data)
+}
@@ -0,0 +1,291 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/events"
+)
+
+// run starts external notifiers. Tests replace it.
+var run = func(cmd *exec.Cmd) error { return cmd.Run() }
+
+// httpClient posts to webhooks and push services. Callers bound requests
+// with their context.
+var httpClient = &http.Client{}
+
+// newSink builds the sink sc describes.
+func newSink(sc config.Sink) (Sink, error) {
+	needURL := func() error {
+		if sc.URL == "" {
+			return fmt.Errorf("%s: url is required", sc.Type)
+		}
+		if _, err := url.Parse(sc.URL); err != nil {
+			return fmt.Errorf("%s: %w", sc.Type, err)
+		}
+		return nil
+	}
+	switch sc.Type {
+	case "tmux":
+		return tmuxSink{}, nil
+	case "desktop":
+		return desktopSink{}, nil
+	case "webhook":
+		if err := needURL(); err != nil {
+			return nil, err
+		}
+		return newWebhook(sc)
+	case "ntfy", "gotify":
+		if err := needURL(); err != nil {
+			return nil, err
+		}
+		return pushSink{kind: sc.Type, url: sc.URL, token: sc.Token}, nil
+	case "command":
+		if sc.Command == "" {
+			return nil, fmt.Errorf("command: command is required")
+		}
+		return commandSink{command: sc.Command}, nil
+	}
+	return nil, fmt.Errorf("unknown type %q (want tmux, desktop, webhook, ntfy, gotify or command)", sc.Type)
+}
+
+// isNetwork reports whether sinks of type t send over the network.
+func isNetwork(t string) bool {
+	return t == "webhook" || t == "ntfy" || t == "gotify"
+}
+
+// urgent reports whether events of kind k should interrupt, for services
+// with priorities.
+func urgent(k events.Kind) bool {
+	return k == events.Caution || k == events.RedFlag
+}
+
+// tmuxSink shows events on the tmux status line.
+type tmuxSink struct{}
+
+func (tmuxSink) Name() string { return "tmux" }
+
+func (tmuxSink) Send(ctx context.Context, e events.Event) error {
+	return run(exec.CommandContext(ctx, "tmux", "display-message", e.Title()+": "+e.Message()))
+}
+
+// desktopSink sends native desktop notifications.
+type desktopSink struct{}
+
+func (desktopSink) Name() string { return "desktop" }
+
+func (desktopSink) Send(ctx context.Context, e events.Event) error {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf(`display notification %q with title %q`, e.Message(), e.Title())
+		return run(exec.CommandContext(ctx, "osascript", "-e", script))
+	case "linux":
+		return run(exec.CommandContext(ctx, "notify-send", e.Title(), e.Message()))
+	}
+	return fmt.Errorf("no desktop notifier on %s", runtime.GOOS)
+}
+
+// commandSink runs a shell command with the event in its environment.
+type commandSink struct {
+	command string
+}
+
+func (s commandSink) Name() string { return "command " + s.command }
+
+func (s commandSink) Send(ctx context.Context, e events.Event) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.command)
+	cmd.Env = append(os.Environ(),
+		"RACEDAY_EVENT="+string(e.Kind),
+		"RACEDAY_SERIES="+e.Series,
+		"RACEDAY_RACE="+e.Race,
+		"RACEDAY_LAP="+strconv.Itoa(e.Lap),
+		"RACEDAY_TITLE="+e.Title(),
+		"RACEDAY_MESSAGE="+e.Message(),
+		"RACEDAY_DRIVER_NUMBER="+e.Driver.Number,
+		"RACEDAY_DRIVER_NAME="+e.Driver.Name,
+		"RACEDAY_POSITION="+strconv.Itoa(e.Driver.Position),
+		"RACEDAY_SESSION="+e.Session,
+		"RACEDAY_START="+start(e),
+		"RACEDAY_COUNT="+strconv.Itoa(max(e.Count, 1)),
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// payload is the event as the generic webhook posts it, and what webhook
+// templates see.
+type payload struct {
+	Kind    events.Kind `json:"kind"`
+	Series  string      `json:"series"`
+	Race    string      `json:"race"`
+	Lap     int         `json:"lap,omitempty"`
+	Title   string      `json:"title"`
+	Message string      `json:"message"`
+	Driver  *driver     `json:"driver,omitempty"`
+	Count   int         `json:"count,omitempty"` // events in a digest
+
+	Session     string `json:"session,omitempty"`
+	Start       string `json:"start,omitempty"`
+	Broadcaster string `json:"broadcaster,omitempty"`
+	Weather     string `json:"weather,omitempty"`
+}
+
+type driver struct {
+	Number   string `json:"number"`
+	Name     string `json:"name"`
+	Position int    `json:"position,omitempty"`
+}
+
+func newPayload(e events.Event) payload {
+	p := payload{
+		Kind: e.Kind, Series: e.Series, Race: e.Race, Lap: e.Lap,
+		Title: e.Title(), Message: e.Message(), Count: e.Count,
+		Session: e.Session, Start: start(e), Broadcaster: e.Broadcaster, Weather: e.Weather,
+	}
+	if e.Driver.Number != "" {
+		p.Driver = &driver{e.Driver.Number, e.Driver.Name, e.Driver.Position}
+	}
+	return p
+}
+
+// start is when a reminder's session starts, in RFC 3339; empty for
+// other events.
+func start(e events.Event) string {
+	if e.Start.IsZero() {
+		return ""
+	}
+	return e.Start.UTC().Format(time.RFC3339)
+}
+
+// webhookTemplates are the built-in webhook formats besides plain JSON.
+var webhookTemplates = map[string]string{
+	"slack":   `{"text": {{json (printf "*%s*: %s" .Title .Message)}}}`,
+	"discord": `{"content": {{json (printf "**%s**: %s" .Title .Message)}}}`,
+}
+
+// webhookSink posts events as JSON.
+type webhookSink struct {
+	url  string
+	tmpl *template.Template // nil for the generic payload
+}
+
+func newWebhook(sc config.Sink) (Sink, error) {
+	text := sc.Template
+	switch {
+	case text != "":
+	case sc.Format == "" || sc.Format == "json":
+		return webhookSink{url: sc.URL}, nil
+	case webhookTemplates[sc.Format] != "":
+		text = webhookTemplates[sc.Format]
+	default:
+		return nil, fmt.Errorf("webhook: unknown format %q (want json, slack or discord)", sc.Format)
+	}
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": jsonString}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+	return webhookSink{url: sc.URL, tmpl: tmpl}, nil
+}
+
+// jsonString quotes v for use inside a JSON template.
+func jsonString(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func (s webhookSink) Name() string { return "webhook " + host(s.url) }
+
+func (s webhookSink) Send(ctx context.Context, e events.Event) error {
+	var body bytes.Buffer
+	if s.tmpl == nil {
+		if err := json.NewEncoder(&body).Encode(newPayload(e)); err != nil {
+			return err
+		}
+	} else if err := s.tmpl.Execute(&body, newPayload(e)); err != nil {
+		return err
+	}
+	return post(ctx, s.url, "application/json", &body, nil)
+}
+
+// pushSink sends events to an ntfy topic or a Gotify server.
+type pushSink struct {
+	kind  string // ntfy or gotify
+	url   string
+	token string
+}
+
+func (s pushSink) Name() string { return s.kind + " " + host(s.url) }
+
+func (s pushSink) Send(ctx context.Context, e events.Event) error {
+	headers := map[string]string{}
+	if s.kind == "ntfy" {
+		headers["Title"] = e.Title()
+		headers["Tags"] = string(e.Kind)
+		if urgent(e.Kind) {
+			headers["Priority"] = "high"
+		}
+		if s.token != "" {
+			headers["Authorization"] = "Bearer " + s.token
+		}
+		return post(ctx, s.url, "text/plain; charset=utf-8", strings.NewReader(e.Message()), headers)
+	}
+
+	priority := 5
+	if urgent(e.Kind) {
+		priority = 8
+	}
+	body, err := json.Marshal(map[string]any{"title": e.Title(), "message": e.Message(), "priority": priority})
+	if err != nil {
+		return err
+	}
+	if s.token != "" {
+		headers["X-Gotify-Key"] = s.token
+	}
+	return post(ctx, strings.TrimSuffix(s.url, "/")+"/message", "application/json", bytes.NewReader(body), headers)
+}
+
+// post sends body to rawURL and fails on a non-2xx response.
+func post(ctx context.Context, rawURL, contentType string, body io.Reader, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// host returns rawURL's host, which names a sink without its secrets.
+func host(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "?"
+	}
+	return u.Host
+}
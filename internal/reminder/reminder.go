@@ -0,0 +1,134 @@
+// Package reminder finds the race weekend sessions about to start that
+// the notify config asks to be reminded of, and makes sure each reminder
+// is sent once.
+package reminder
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/events"
+	"github.com/jfmyers/tmux-raceday/internal/series"
+)
+
+// Keep is how long sent reminders are remembered.
+const Keep = 7 * 24 * time.Hour
+
+// Dir returns where sent reminders are recorded, one file each.
+func Dir() string {
+	return filepath.Join(config.StateDir(), "reminders")
+}
+
+// Reminder is a session whose reminder is due.
+type Reminder struct {
+	Session series.Session
+	Before  time.Duration // the reminder offset, e.g. 10m
+}
+
+// Due returns the reminders due at now for the sessions matching names.
+// A session gets the shortest of its offsets that has passed, so a status
+// line started late sends one reminder rather than a burst. Sessions that
+// have started get none.
+func Due(sessions []series.Session, before []time.Duration, names []string, now time.Time) []Reminder {
+	var out []Reminder
+	for _, s := range sessions {
+		if !s.StartTime.After(now) || !Matches(s.Name, names) {
+			continue
+		}
+		due := time.Duration(0)
+		for _, b := range before {
+			if !now.Before(s.StartTime.Add(-b)) && (due == 0 || b < due) {
+				due = b
+			}
+		}
+		if due > 0 {
+			out = append(out, Reminder{Session: s, Before: due})
+		}
+	}
+	return out
+}
+
+// Matches reports whether a session called name is one of names. It is if
+// its name contains one of them, ignoring case, so "qualifying" also
+// covers "Sprint Qualifying".
+func Matches(name string, names []string) bool {
+	name = strings.ToLower(name)
+	return slices.ContainsFunc(names, func(n string) bool {
+		return n != "" && strings.Contains(name, strings.ToLower(n))
+	})
+}
+
+// Key identifies r among sent reminders, e.g.
+// "NASCAR_2026-02-15T19-30-00Z_Race_10m0s".
+func (r Reminder) Key() string {
+	key := strings.Join([]string{
+		r.Session.Race.ShortName,
+		r.Session.StartTime.UTC().Format(time.RFC3339),
+		r.Session.Name,
+		r.Before.String(),
+	}, "_")
+	return strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '.', c == '_':
+			return c
+		}
+		return '-'
+	}, key)
+}
+
+// Claim records r as sent in dir and reports whether this call was the
+// first to. The record is created exclusively, so of several status runs
+// racing for the same reminder only one sends it.
+func Claim(dir string, r Reminder) (bool, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return false, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, r.Key()), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, f.Close()
+}
+
+// Prune forgets reminders sent more than Keep before now.
+func Prune(dir string, now time.Time) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || now.Sub(info.ModTime()) <= Keep {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, e.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Event is r's reminder event at now, with weather the conditions at the
+// track.
+func (r Reminder) Event(now time.Time, weather string) events.Event {
+	race := r.Session.Race
+	return events.Event{
+		Kind:        events.Reminder,
+		Series:      race.ShortName,
+		Race:        race.RaceName,
+		Session:     r.Session.Name,
+		Start:       r.Session.StartTime,
+		Until:       r.Session.StartTime.Sub(now),
+		Broadcaster: race.Broadcaster,
+		Weather:     weather,
+	}
+}
@@ -0,0 +1,100 @@
+package reminder
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jfmyers/tmux-raceday/internal/series"
+)
+
+var (
+	daytona = series.Race{ShortName: "NASCAR", RaceName: "DAYTONA 500", Broadcaster: "FOX"}
+	start   = time.Date(2026, 2, 15, 19, 30, 0, 0, time.UTC)
+	race    = series.Session{Race: daytona, Name: "Race", StartTime: start}
+	quali   = series.Session{Race: daytona, Name: "Qualifying", StartTime: start.Add(-3 * 24 * time.Hour)}
+	before  = []time.Duration{time.Hour, 10 * time.Minute}
+)
+
+func due(now time.Time, names ...string) []string {
+	var out []string
+	for _, r := range Due([]series.Session{quali, race}, before, names, now) {
+		out = append(out, r.Session.Name+" "+r.Before.String())
+	}
+	return out
+}
+
+func TestDue(t *testing.T) {
+	tests := []struct {
+		name  string
+		now   time.Time
+		names []string
+		want  []string
+	}{
+		{"too early", start.Add(-61 * time.Minute), []string{"race"}, nil},
+		{"an hour out", start.Add(-time.Hour), []string{"race"}, []string{"Race 1h0m0s"}},
+		{"started late", start.Add(-5 * time.Minute), []string{"race"}, []string{"Race 10m0s"}},
+		{"underway", start, []string{"race"}, nil},
+		{"not asked for", start.Add(-time.Hour), []string{"qualifying"}, nil},
+		{"qualifying", quali.StartTime.Add(-time.Minute), []string{"Qualifying", "race"}, []string{"Qualifying 10m0s"}},
+	}
+	for _, tt := range tests {
+		if got := due(tt.now, tt.names...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if !Matches("Sprint Qualifying", []string{"qualifying"}) || Matches("Practice 1", []string{"race", ""}) {
+		t.Error("Matches should compare names by substring, ignoring case")
+	}
+}
+
+func TestClaim(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reminders")
+	r := Reminder{Session: series.Session{Race: daytona, Name: "Practice 1", StartTime: start}, Before: time.Hour}
+	if want := "NASCAR_2026-02-15T19-30-00Z_Practice-1_1h0m0s"; r.Key() != want {
+		t.Errorf("Key = %q, want %q", r.Key(), want)
+	}
+
+	if ok, err := Claim(dir, r); !ok || err != nil {
+		t.Fatalf("first claim = %v, %v", ok, err)
+	}
+	if ok, err := Claim(dir, r); ok || err != nil {
+		t.Errorf("second claim = %v, %v", ok, err)
+	}
+
+	old := Reminder{Session: race, Before: time.Hour}
+	if _, err := Claim(dir, old); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	stale := now.Add(-Keep - time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, old.Key()), stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := Prune(dir, now); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != r.Key() {
+		t.Errorf("after prune: %v", entries)
+	}
+}
+
+func TestEvent(t *testing.T) {
+	e := Reminder{Session: race, Before: 10 * time.Minute}.Event(start.Add(-9*time.Minute-40*time.Second), "72°F ☀️ 5mph ↗")
+	local := start.Local().Format("3:04 PM")
+	if got, want := e.Message(), "Race in 10m, "+local+" on FOX · 72°F ☀️ 5mph ↗"; got != want {
+		t.Errorf("Message = %q, want %q", got, want)
+	}
+	if e.Title() != "NASCAR · DAYTONA 500" {
+		t.Errorf("Title = %q", e.Title())
+	}
+
+	e = Reminder{Session: quali, Before: time.Hour}.Event(quali.StartTime.Add(-90*time.Minute), "")
+	if got, want := e.Message(), "Qualifying in 1h30m, "+quali.StartTime.Local().Format("3:04 PM")+" on FOX"; got != want {
+		t.Errorf("Message = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,187 @@
+// Package schema checks provider payloads against the shape raceday
+// expects. The upstream feeds are undocumented, and a renamed field
+// otherwise decodes silently as a zero value.
+//
+// Providers validate each payload they fetch from the network and
+// Record the result, so the most recent verdict per feed is available
+// to the UI through Problems.
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Issue is one way a payload departs from the expected schema.
+type Issue struct {
+	Path string // e.g. "vehicles[3].driver.full_name"
+	Msg  string
+}
+
+func (i Issue) String() string {
+	if i.Path == "" {
+		return i.Msg
+	}
+	return i.Path + ": " + i.Msg
+}
+
+// Error reports the schema issues found in one feed.
+type Error struct {
+	Feed   string
+	Issues []Issue
+}
+
+func (e *Error) Error() string {
+	const shown = 3
+	var parts []string
+	for i, is := range e.Issues {
+		if i == shown {
+			parts = append(parts, fmt.Sprintf("and %d more", len(e.Issues)-shown))
+			break
+		}
+		parts = append(parts, is.String())
+	}
+	return fmt.Sprintf("%s: unexpected schema: %s", e.Feed, strings.Join(parts, "; "))
+}
+
+// Checker accumulates issues while a validator walks a payload.
+type Checker struct {
+	issues []Issue
+}
+
+// Addf records an issue at path.
+func (c *Checker) Addf(path, format string, args ...any) {
+	c.issues = append(c.issues, Issue{Path: path, Msg: fmt.Sprintf(format, args...)})
+}
+
+// Keys records a missing-key issue for each key absent from obj.
+func (c *Checker) Keys(path string, obj map[string]any, keys ...string) {
+	for _, k := range keys {
+		if _, ok := obj[k]; !ok {
+			c.Addf(join(path, k), "missing")
+		}
+	}
+}
+
+// Range records an issue if v is outside [min, max].
+func (c *Checker) Range(path string, v, min, max int) {
+	if v < min || v > max {
+		c.Addf(path, "%d out of range [%d, %d]", v, min, max)
+	}
+}
+
+// NonEmpty records an issue if s is empty.
+func (c *Checker) NonEmpty(path, s string) {
+	if s == "" {
+		c.Addf(path, "empty")
+	}
+}
+
+// Err returns the accumulated issues as an *Error for feed, or nil.
+func (c *Checker) Err(feed string) error {
+	if len(c.issues) == 0 {
+		return nil
+	}
+	return &Error{Feed: feed, Issues: c.issues}
+}
+
+// Object decodes data as a JSON object.
+func Object(data []byte) (map[string]any, error) {
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Objects returns the elements of v that are JSON objects, recording an
+// issue for any that aren't. v is typically a decoded JSON array.
+func (c *Checker) Objects(path string, v any) []map[string]any {
+	arr, ok := v.([]any)
+	if !ok {
+		if v != nil {
+			c.Addf(path, "not an array")
+		}
+		return nil
+	}
+	out := make([]map[string]any, 0, len(arr))
+	for i, e := range arr {
+		obj, ok := e.(map[string]any)
+		if !ok {
+			c.Addf(fmt.Sprintf("%s[%d]", path, i), "not an object")
+			continue
+		}
+		out = append(out, obj)
+	}
+	return out
+}
+
+// Records decodes data as a JSON array of objects, as the OpenF1 and
+// standings endpoints return, and checks the first record has the
+// required keys; a renamed key shows up in every record, so it is
+// reported once. Decoding errors are reported as issues. It returns the
+// number of records so callers can add checks on their typed decode.
+func Records(data []byte, required ...string) (*Checker, int) {
+	c := &Checker{}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		c.Addf("", "invalid JSON: %v", err)
+		return c, 0
+	}
+	recs := c.Objects("", v)
+	if len(recs) > 0 {
+		c.Keys("[0]", recs[0], required...)
+	}
+	return c, len(recs)
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// Check describes how to fetch and validate one feed, for diagnostics
+// that bypass the cache.
+type Check struct {
+	Feed     string
+	URL      string
+	Validate func([]byte) error
+}
+
+var (
+	mu     sync.Mutex
+	latest = map[string]*Error{}
+)
+
+// Record stores the latest validation result for feed; a nil err clears
+// any earlier problem. Errors other than *Error are ignored.
+func Record(feed string, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	var se *Error
+	switch {
+	case err == nil:
+		delete(latest, feed)
+	case errors.As(err, &se):
+		latest[feed] = se
+	}
+}
+
+// Problems returns the feeds whose latest payload failed validation,
+// sorted by feed name.
+func Problems() []*Error {
+	mu.Lock()
+	defer mu.Unlock()
+	out := make([]*Error, 0, len(latest))
+	for _, err := range latest {
+		out = append(out, err)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Feed < out[j].Feed })
+	return out
+}
@@ -0,0 +1,54 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRecords(t *testing.T) {
+	c, n := Records([]byte(`[{"a": 1, "b": 2}, {"a": 3}]`), "a", "b", "c")
+	if n != 2 {
+		t.Errorf("n = %d, want 2", n)
+	}
+	err := c.Err("feed")
+	se, ok := err.(*Error)
+	if !ok || len(se.Issues) != 1 || se.Issues[0].String() != "[0].c: missing" {
+		t.Errorf("err = %v, want one missing-key issue for c", err)
+	}
+
+	c, _ = Records([]byte(`{"detail": "oops"}`), "a")
+	if c.Err("feed") == nil {
+		t.Error("object instead of array should be an issue")
+	}
+}
+
+func TestErrorSummarizes(t *testing.T) {
+	c := &Checker{}
+	for i := range 5 {
+		c.Addf(fmt.Sprintf("f%d", i), "missing")
+	}
+	msg := c.Err("nascar live feed").Error()
+	if !strings.HasPrefix(msg, "nascar live feed: unexpected schema: f0: missing") || !strings.HasSuffix(msg, "and 2 more") {
+		t.Errorf("Error() = %q", msg)
+	}
+}
+
+func TestRecordAndProblems(t *testing.T) {
+	c := &Checker{}
+	c.Range("lap", 500, 0, 200)
+	Record("b feed", c.Err("b feed"))
+	Record("a feed", c.Err("a feed"))
+	Record("c feed", fmt.Errorf("network down")) // not a schema error
+
+	got := Problems()
+	if len(got) != 2 || got[0].Feed != "a feed" || got[1].Feed != "b feed" {
+		t.Fatalf("Problems() = %v", got)
+	}
+
+	Record("a feed", nil)
+	Record("b feed", nil)
+	if got := Problems(); len(got) != 0 {
+		t.Errorf("Problems() after clearing = %v", got)
+	}
+}
@@ -1,6 +1,10 @@
 package series
 
-import "time"
+import (
+	"context"
+	"fmt"
+	"time"
+)
 
 // PostRaceGracePeriod is how long to keep displaying results after a race
 // finishes. Both NASCAR and F1 use this to show final standings before the
@@ -29,6 +33,28 @@ type Driver struct {
 	Gap      string  // "+1.234" or "+1 LAP"
 	Delta    float64 // delta from starting position
 	Compound string  // tire compound: SOFT, MEDIUM, HARD, INTERMEDIATE, WET
+
+	GapAhead  string // to the car ahead, as Gap; empty for the leader or if unknown
+	GapBehind string // to the car behind; empty for the last car or if unknown
+	LapsDown  int    // laps behind the leader
+	LuckyDog  int    // place in line for the free pass when lapped (1 gets it); 0 if not tracked
+
+	Points     float64 // points earned in a finished race; 0 if unknown
+	PitStops   int     // pit stops made so far; 0 if not tracked
+	LastPitLap int     // lap of the latest pit stop; 0 if none or not tracked
+	Make       string  // car manufacturer, e.g. "Toyota"; empty if not tracked
+}
+
+// FormatGap formats a gap between two cars: whole laps when laps is
+// positive ("+1 LAP", "+2 LAPS"), otherwise seconds ("+0.412").
+func FormatGap(seconds float64, laps int) string {
+	switch {
+	case laps == 1:
+		return "+1 LAP"
+	case laps > 1:
+		return fmt.Sprintf("+%d LAPS", laps)
+	}
+	return fmt.Sprintf("+%.3f", seconds)
 }
 
 // LiveState represents real-time session data from any series.
@@ -41,40 +67,154 @@ type LiveState struct {
 	TotalLaps  int // 0 if unknown (e.g. F1 timed sessions)
 	FlagSymbol string
 	FlagName   string
+	Stage      int // current stage, for series that run them; 0 otherwise
 	Finished   bool
 	Leader     Driver
 	Positions  []Driver
 	Lat, Lon   float64
 }
 
+// Result is the outcome of a finished race.
+type Result struct {
+	SeriesName string
+	ShortName  string
+	RaceName   string
+	TrackName  string
+	StartTime  time.Time
+	EndTime    time.Time // estimated where the series doesn't publish it
+	Winner     Driver
+	Positions  []Driver // finishing order
+}
+
 // Series is the interface each racing series must implement.
+// Implementations should return promptly once ctx is done, serving
+// cached data where they have it.
 type Series interface {
 	Name() string
 	ShortName() string
-	FetchSchedule(year int) ([]Race, error)
-	FetchLiveState() (*LiveState, error)
+	FetchSchedule(ctx context.Context, year int) ([]Race, error)
+	FetchLiveState(ctx context.Context) (*LiveState, error)
+}
+
+// ResultSeries is implemented by series that can report the result of
+// their most recent race.
+type ResultSeries interface {
+	// LastResult returns the result of the latest race finished before
+	// now, or nil if there is none.
+	LastResult(ctx context.Context, now time.Time) (*Result, error)
+}
+
+// Session is one on-track session of a race weekend, such as qualifying
+// or the race itself.
+type Session struct {
+	Race      Race   // the weekend's race
+	Name      string // e.g. "Qualifying", "Sprint" or "Race"
+	StartTime time.Time
+}
+
+// SessionSeries is implemented by series that publish every session of a
+// race weekend, not only the races.
+type SessionSeries interface {
+	FetchSessions(ctx context.Context, year int) ([]Session, error)
+}
+
+// Sessions returns s's sessions in year: its weekend sessions if it
+// publishes them, otherwise one "Race" session per race.
+func Sessions(ctx context.Context, s Series, year int) ([]Session, error) {
+	if ss, ok := s.(SessionSeries); ok {
+		return ss.FetchSessions(ctx, year)
+	}
+	races, err := s.FetchSchedule(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Session, len(races))
+	for i, r := range races {
+		out[i] = Session{Race: r, Name: "Race", StartTime: r.StartTime}
+	}
+	return out, nil
+}
+
+// DefaultHorizon is how far ahead NextRace looks for the next race. A year
+// covers the off-season, when the next race is in next year's schedule.
+const DefaultHorizon = 365 * 24 * time.Hour
+
+// NextRace returns the soonest upcoming (incomplete) race in s that starts
+// within horizon of now, or nil if none is scheduled. Schedules are fetched
+// year by year from now.Year() until a race is found, so the lookup spans
+// the season boundary. A horizon of 0 searches the current year only.
+// If the current year's schedule can't be fetched, that is an error:
+// next year's opener is no answer mid-season. Later years' schedules may
+// not be published yet, so those are skipped if they fail.
+func NextRace(ctx context.Context, s Series, now time.Time, horizon time.Duration) (*Race, error) {
+	var end time.Time
+	last := now.Year()
+	if horizon > 0 {
+		end = now.Add(horizon)
+		last = end.Year()
+	}
+	for year := now.Year(); year <= last; year++ {
+		races, err := s.FetchSchedule(ctx, year)
+		if err != nil {
+			if year == now.Year() {
+				return nil, err
+			}
+			continue
+		}
+		if best := soonest(races, now, end); best != nil {
+			return best, nil
+		}
+	}
+	return nil, nil
+}
+
+// soonest returns the earliest incomplete race starting in [now, end].
+// A zero end means no upper bound.
+func soonest(races []Race, now, end time.Time) *Race {
+	var best *Race
+	for i := range races {
+		r := &races[i]
+		if r.Complete {
+			continue
+		}
+		if r.StartTime.Before(now) || (!end.IsZero() && r.StartTime.After(end)) {
+			continue
+		}
+		if best == nil || r.StartTime.Before(best.StartTime) {
+			best = r
+		}
+	}
+	return best
+}
+
+// SeasonSchedule returns the schedule for the current season: now's year,
+// or next year's once every race this year is complete or past. If next
+// year's schedule isn't available yet the current year's is returned.
+func SeasonSchedule(ctx context.Context, s Series, now time.Time) ([]Race, error) {
+	races, err := s.FetchSchedule(ctx, now.Year())
+	if err != nil {
+		return nil, err
+	}
+	if soonest(races, now, time.Time{}) != nil {
+		return races, nil
+	}
+	if next, err := s.FetchSchedule(ctx, now.Year()+1); err == nil && len(next) > 0 {
+		return next, nil
+	}
+	return races, nil
 }
 
 // NextRaceAcrossAll returns the soonest upcoming (incomplete) race
-// across all provided series. Returns nil if none found.
-func NextRaceAcrossAll(allSeries []Series, now time.Time) *Race {
+// across all provided series within horizon. Returns nil if none found.
+func NextRaceAcrossAll(ctx context.Context, allSeries []Series, now time.Time, horizon time.Duration) *Race {
 	var best *Race
 	for _, s := range allSeries {
-		races, err := s.FetchSchedule(now.Year())
-		if err != nil {
+		r, err := NextRace(ctx, s, now, horizon)
+		if err != nil || r == nil {
 			continue
 		}
-		for i := range races {
-			r := &races[i]
-			if r.Complete {
-				continue
-			}
-			if r.StartTime.Before(now) {
-				continue
-			}
-			if best == nil || r.StartTime.Before(best.StartTime) {
-				best = r
-			}
+		if best == nil || r.StartTime.Before(best.StartTime) {
+			best = r
 		}
 	}
 	return best
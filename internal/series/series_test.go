@@ -1,6 +1,7 @@
 package series
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -10,19 +11,27 @@ type mockSeries struct {
 	name      string
 	shortName string
 	races     []Race
+	byYear    map[int][]Race // overrides races when set
 	live      *LiveState
 	err       error
 }
 
 func (m *mockSeries) Name() string      { return m.name }
 func (m *mockSeries) ShortName() string  { return m.shortName }
-func (m *mockSeries) FetchSchedule(year int) ([]Race, error) {
+func (m *mockSeries) FetchSchedule(ctx context.Context, year int) ([]Race, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
+	if m.byYear != nil {
+		races, ok := m.byYear[year]
+		if !ok {
+			return nil, fmt.Errorf("no schedule for %d", year)
+		}
+		return races, nil
+	}
 	return m.races, nil
 }
-func (m *mockSeries) FetchLiveState() (*LiveState, error) {
+func (m *mockSeries) FetchLiveState(ctx context.Context) (*LiveState, error) {
 	return m.live, m.err
 }
 
@@ -44,7 +53,7 @@ func TestNextRaceAcrossAll(t *testing.T) {
 	f1 := &mockSeries{name: "Formula 1", shortName: "F1", races: []Race{f1Race}}
 
 	t.Run("NASCAR race soonest", func(t *testing.T) {
-		got := NextRaceAcrossAll([]Series{nascar, f1}, now)
+		got := NextRaceAcrossAll(context.Background(), []Series{nascar, f1}, now, DefaultHorizon)
 		if got == nil {
 			t.Fatal("expected a race, got nil")
 		}
@@ -61,7 +70,7 @@ func TestNextRaceAcrossAll(t *testing.T) {
 				StartTime: now.Add(72 * time.Hour),
 			}},
 		}
-		got := NextRaceAcrossAll([]Series{laterNascar, f1}, now)
+		got := NextRaceAcrossAll(context.Background(), []Series{laterNascar, f1}, now, DefaultHorizon)
 		if got == nil {
 			t.Fatal("expected a race, got nil")
 		}
@@ -75,14 +84,14 @@ func TestNextRaceAcrossAll(t *testing.T) {
 			name: "NASCAR Cup", shortName: "NASCAR",
 			races: []Race{{Complete: true, StartTime: now.Add(time.Hour)}},
 		}
-		got := NextRaceAcrossAll([]Series{done}, now)
+		got := NextRaceAcrossAll(context.Background(), []Series{done}, now, DefaultHorizon)
 		if got != nil {
 			t.Errorf("expected nil, got %+v", got)
 		}
 	})
 
 	t.Run("empty series list returns nil", func(t *testing.T) {
-		got := NextRaceAcrossAll([]Series{}, now)
+		got := NextRaceAcrossAll(context.Background(), []Series{}, now, DefaultHorizon)
 		if got != nil {
 			t.Errorf("expected nil, got %+v", got)
 		}
@@ -90,7 +99,7 @@ func TestNextRaceAcrossAll(t *testing.T) {
 
 	t.Run("skips series with fetch errors", func(t *testing.T) {
 		failing := &mockSeries{name: "Broken", err: fmt.Errorf("network error")}
-		got := NextRaceAcrossAll([]Series{failing, f1}, now)
+		got := NextRaceAcrossAll(context.Background(), []Series{failing, f1}, now, DefaultHorizon)
 		if got == nil {
 			t.Fatal("expected a race, got nil")
 		}
@@ -107,9 +116,63 @@ func TestNextRaceAcrossAll(t *testing.T) {
 				StartTime: now.Add(-24 * time.Hour),
 			}},
 		}
-		got := NextRaceAcrossAll([]Series{pastOnly}, now)
+		got := NextRaceAcrossAll(context.Background(), []Series{pastOnly}, now, DefaultHorizon)
 		if got != nil {
 			t.Errorf("expected nil for past races, got %+v", got)
 		}
 	})
 }
+
+func TestNextRaceSpansYearBoundary(t *testing.T) {
+	now := time.Date(2026, 11, 20, 12, 0, 0, 0, time.UTC)
+	finale := Race{RaceName: "Championship", StartTime: time.Date(2026, 11, 8, 19, 0, 0, 0, time.UTC), Complete: true}
+	opener := Race{RaceName: "Daytona 500", StartTime: time.Date(2027, 2, 14, 19, 30, 0, 0, time.UTC)}
+	exhibition := Race{RaceName: "Clash", StartTime: time.Date(2026, 12, 20, 20, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name    string
+		byYear  map[int][]Race
+		horizon time.Duration
+		want    string
+		wantErr bool
+	}{
+		{"next season", map[int][]Race{2026: {finale}, 2027: {opener}}, DefaultHorizon, "Daytona 500", false},
+		{"beyond horizon", map[int][]Race{2026: {finale}, 2027: {opener}}, 30 * 24 * time.Hour, "", false},
+		{"current year only", map[int][]Race{2026: {finale}, 2027: {opener}}, 0, "", false},
+		{"current year only, race left", map[int][]Race{2026: {finale, exhibition}, 2027: {opener}}, 0, "Clash", false},
+		{"next season unpublished", map[int][]Race{2026: {finale}}, DefaultHorizon, "", false},
+		{"current year unavailable", map[int][]Race{2027: {opener}}, DefaultHorizon, "", true},
+		{"nothing available", map[int][]Race{}, DefaultHorizon, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &mockSeries{name: "NASCAR Cup", byYear: tt.byYear}
+			got, err := NextRace(context.Background(), s, now, tt.horizon)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			name := ""
+			if got != nil {
+				name = got.RaceName
+			}
+			if name != tt.want {
+				t.Errorf("got %q, want %q", name, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeasonSchedule(t *testing.T) {
+	this := []Race{{RaceName: "Abu Dhabi GP", StartTime: time.Date(2026, 12, 6, 13, 0, 0, 0, time.UTC)}}
+	next := []Race{{RaceName: "Australian GP", StartTime: time.Date(2027, 3, 14, 5, 0, 0, 0, time.UTC)}}
+	s := &mockSeries{byYear: map[int][]Race{2026: this, 2027: next}}
+
+	got, err := SeasonSchedule(context.Background(), s, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || len(got) != 1 || got[0].RaceName != "Abu Dhabi GP" {
+		t.Errorf("mid-season = %+v, %v; want this year's schedule", got, err)
+	}
+	got, err = SeasonSchedule(context.Background(), s, time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC))
+	if err != nil || len(got) != 1 || got[0].RaceName != "Australian GP" {
+		t.Errorf("off-season = %+v, %v; want next year's schedule", got, err)
+	}
+}
@@ -0,0 +1,148 @@
+// Package spoiler keeps the positions and results of selected races out
+// of view until they have been marked watched, for people catching up on
+// a recording.
+package spoiler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+	"github.com/jfmyers/tmux-raceday/internal/config"
+)
+
+// Path returns where `raceday spoilers` keeps its state.
+func Path() string {
+	return filepath.Join(config.StateDir(), "spoilers.json")
+}
+
+// Race picks out a race in one season. Name is matched case-insensitively
+// against any part of a race or track name, so "daytona" covers the
+// DAYTONA 500.
+type Race struct {
+	Year int    `json:"year"`
+	Name string `json:"name"`
+}
+
+func (r Race) String() string { return fmt.Sprintf("%s (%d)", r.Name, r.Year) }
+
+// matches reports whether r covers the race in year known by any of names.
+func (r Race) matches(year int, names []string) bool {
+	return r.Year == year && matchName(r.Name, names)
+}
+
+func (r Race) equal(o Race) bool {
+	return r.Year == o.Year && strings.EqualFold(r.Name, o.Name)
+}
+
+func matchName(pattern string, names []string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return false
+	}
+	for _, n := range names {
+		if strings.Contains(strings.ToLower(n), pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// State is what `raceday spoilers` records.
+type State struct {
+	All     bool   `json:"all"` // hide every race until it is watched
+	Hidden  []Race `json:"hidden,omitempty"`
+	Watched []Race `json:"watched,omitempty"`
+}
+
+// Load reads the state at path. A missing file is an empty state.
+func Load(path string) (State, error) {
+	var s State
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Save writes s to path, replacing it atomically.
+func (s State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return cache.WriteFile(path, append(data, '\n'))
+}
+
+// Hide keeps r spoiler-free, even if it was marked watched before.
+func (s *State) Hide(r Race) {
+	s.Watched = slices.DeleteFunc(s.Watched, r.equal)
+	if !slices.ContainsFunc(s.Hidden, r.equal) {
+		s.Hidden = append(s.Hidden, r)
+	}
+}
+
+// Watch marks r watched, which lifts spoiler-free mode for it.
+func (s *State) Watch(r Race) {
+	s.Hidden = slices.DeleteFunc(s.Hidden, r.equal)
+	if !slices.ContainsFunc(s.Watched, r.equal) {
+		s.Watched = append(s.Watched, r)
+	}
+}
+
+// Filter decides which races are spoiler-free: those selected by the
+// config or by `raceday spoilers`, until they are marked watched.
+type Filter struct {
+	State
+	Config config.SpoilerFree
+}
+
+// New returns the Filter for cfg and the state at path. A state that
+// can't be read is logged and treated as empty, so the config still
+// applies.
+func New(cfg config.SpoilerFree, path string) *Filter {
+	st, err := Load(path)
+	if err != nil {
+		slog.Warn("spoiler state ignored", "err", err)
+	}
+	return &Filter{State: st, Config: cfg}
+}
+
+// Hides reports whether the race in year known by names (its race and
+// track names) is spoiler-free. A nil Filter hides nothing.
+func (f *Filter) Hides(year int, names ...string) bool {
+	if f == nil {
+		return false
+	}
+	for _, w := range f.Watched {
+		if w.matches(year, names) {
+			return false
+		}
+	}
+	if f.All || f.Config.All {
+		return true
+	}
+	for _, h := range f.Hidden {
+		if h.matches(year, names) {
+			return true
+		}
+	}
+	for _, pattern := range f.Config.Races {
+		if matchName(pattern, names) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package spoiler
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jfmyers/tmux-raceday/internal/config"
+)
+
+func TestFilterHides(t *testing.T) {
+	f := &Filter{
+		State: State{
+			Hidden:  []Race{{2026, "bristol"}},
+			Watched: []Race{{2026, "daytona"}},
+		},
+		Config: config.SpoilerFree{Races: []string{"Daytona 500", "Abu Dhabi"}},
+	}
+	tests := []struct {
+		year  int
+		names []string
+		want  bool
+	}{
+		{2026, []string{"DAYTONA 500"}, false}, // configured, but watched
+		{2027, []string{"DAYTONA 500"}, true},  // configured every season
+		{2026, []string{"Food City 500", "Bristol Motor Speedway"}, true},
+		{2027, []string{"Food City 500", "Bristol Motor Speedway"}, false},
+		{2026, []string{"Yas Marina Circuit", "Abu Dhabi Grand Prix"}, true},
+		{2026, []string{"Ambetter Health 400"}, false},
+	}
+	for _, tt := range tests {
+		if got := f.Hides(tt.year, tt.names...); got != tt.want {
+			t.Errorf("Hides(%d, %q) = %v, want %v", tt.year, tt.names, got, tt.want)
+		}
+	}
+
+	f.All = true
+	if !f.Hides(2026, "Ambetter Health 400") || f.Hides(2026, "DAYTONA 500") {
+		t.Error("all should hide every race except watched ones")
+	}
+	if (*Filter)(nil).Hides(2026, "DAYTONA 500") {
+		t.Error("nil Filter hides")
+	}
+}
+
+func TestStateHideWatch(t *testing.T) {
+	var s State
+	s.Hide(Race{2026, "Daytona"})
+	s.Hide(Race{2026, "daytona"})
+	if len(s.Hidden) != 1 {
+		t.Errorf("Hidden = %v, want one entry", s.Hidden)
+	}
+	s.Watch(Race{2026, "DAYTONA"})
+	if len(s.Hidden) != 0 || len(s.Watched) != 1 {
+		t.Errorf("after Watch: hidden %v, watched %v", s.Hidden, s.Watched)
+	}
+	s.Hide(Race{2026, "Daytona"})
+	if len(s.Hidden) != 1 || len(s.Watched) != 0 {
+		t.Errorf("after re-Hide: hidden %v, watched %v", s.Hidden, s.Watched)
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "spoilers.json")
+	if s, err := Load(path); err != nil || !reflect.DeepEqual(s, State{}) {
+		t.Fatalf("missing file = %+v, %v", s, err)
+	}
+	want := State{All: true, Hidden: []Race{{2026, "bristol"}}, Watched: []Race{{2026, "daytona"}}}
+	if err := want.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(path)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Load = %+v, %v; want %+v", got, err, want)
+	}
+}
@@ -1,7 +1,9 @@
 package ui
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"sort"
 	"strconv"
 	"strings"
@@ -10,10 +12,15 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/jfmyers/tmux-raceday/internal/cache"
+	"github.com/jfmyers/tmux-raceday/internal/delay"
 	"github.com/jfmyers/tmux-raceday/internal/f1"
 	"github.com/jfmyers/tmux-raceday/internal/nascar"
+	"github.com/jfmyers/tmux-raceday/internal/schema"
 	"github.com/jfmyers/tmux-raceday/internal/series"
+	"github.com/jfmyers/tmux-raceday/internal/spoiler"
 	"github.com/jfmyers/tmux-raceday/internal/weather"
+	"github.com/mattn/go-runewidth"
 )
 
 var (
@@ -45,6 +52,9 @@ var (
 	statusBarStyle = lipgloss.NewStyle().
 			Background(lipgloss.Color("236")).
 			Foreground(lipgloss.Color("248"))
+
+	warnStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("208"))
 )
 
 type tickMsg time.Time
@@ -90,15 +100,28 @@ type Model struct {
 	seriesLocked bool // true once user manually switches series
 	f1Live       *series.LiveState
 	f1Schedule   []series.Race
-}
-
-func NewModel(driverNum int) Model {
+	stale        map[string]time.Time // source -> mtime of stale cached data
+	drift        []*schema.Error      // feeds whose last payload failed validation
+	spoilers     *spoiler.Filter      // races whose positions and results stay hidden
+	delay        time.Duration        // how far behind the feeds the live views run
+	horizon      time.Duration        // how far ahead to look for the next race
+	feedBuf      delay.Buffer[*nascar.LiveFeed]
+	f1Buf        delay.Buffer[*series.LiveState]
+}
+
+// NewModel returns the TUI model. spoilers may be nil to hide nothing;
+// live data is shown d after it is fetched, to match a delayed broadcast.
+// The next race is looked for within horizon, as in the status line.
+func NewModel(driverNum int, spoilers *spoiler.Filter, d, horizon time.Duration) Model {
 	fav := ""
 	if driverNum > 0 {
 		fav = strconv.Itoa(driverNum)
 	}
 	return Model{
 		favDriver: fav,
+		spoilers:  spoilers,
+		delay:     delay.Clamp(d),
+		horizon:   horizon,
 		sortCol:   0, // position
 		sortAsc:   true,
 	}
@@ -109,25 +132,73 @@ type standingsMsg []nascar.PointsEntry
 type f1LiveStateMsg struct{ state *series.LiveState }
 type f1ScheduleMsg struct{ races []series.Race }
 
+// Data sources tracked for staleness.
+const (
+	sourceFeed       = "feed"
+	sourceSchedule   = "schedule"
+	sourceStandings  = "standings"
+	sourceF1Live     = "f1"
+	sourceF1Schedule = "f1 calendar"
+	sourceWeather    = "weather"
+)
+
+// sourceMsg wraps a fetch result with whether it was served from stale
+// cache (offline or the provider failed).
+type sourceMsg struct {
+	source string
+	stale  time.Time // zero when the data is fresh
+	msg    tea.Msg
+}
+
+// tracked turns a fetch function into a command that reports staleness
+// for source alongside its result.
+func tracked(source string, fn func(context.Context) tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		ctx, tracker := cache.Track(context.Background())
+		start := time.Now()
+		msg := fn(ctx)
+		var since time.Time
+		if stale, oldest := tracker.Stale(); stale {
+			since = oldest
+		}
+		if err, ok := msg.(errMsg); ok {
+			slog.Warn("refresh failed", "source", source, "duration", time.Since(start), "err", error(err))
+		} else {
+			slog.Debug("refresh", "source", source, "duration", time.Since(start), "stale", !since.IsZero())
+		}
+		return sourceMsg{source: source, stale: since, msg: msg}
+	}
+}
+
+var (
+	fetchFeedCmd       = tracked(sourceFeed, fetchFeed)
+	fetchStandingsCmd  = tracked(sourceStandings, fetchStandings)
+	fetchF1LiveCmd     = tracked(sourceF1Live, fetchF1Live)
+	fetchF1ScheduleCmd = tracked(sourceF1Schedule, fetchF1Schedule)
+)
+
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(fetchFeed, fetchSchedule, fetchStandings, fetchF1Live, fetchF1Schedule, tickCmd(5*time.Second), weatherTickCmd())
+	fetchScheduleCmd := tracked(sourceSchedule, func(ctx context.Context) tea.Msg { return fetchSchedule(ctx, m.horizon) })
+	return tea.Batch(fetchFeedCmd, fetchScheduleCmd, fetchStandingsCmd, fetchF1LiveCmd, fetchF1ScheduleCmd, tickCmd(5*time.Second), weatherTickCmd())
 }
 
-func fetchSchedule() tea.Msg {
-	year := time.Now().Year()
-	races, err := nascar.FetchCupSchedule(year)
+func fetchSchedule(ctx context.Context, horizon time.Duration) tea.Msg {
+	next, err := series.NextRace(ctx, nascar.NewSeries(), time.Now(), horizon)
 	if err != nil {
 		return errMsg(err)
 	}
-	race := nascar.NextRace(races)
-	if race == nil {
+	if next == nil {
 		return scheduleMsg(nil)
 	}
+	race, err := nascar.ScheduleRace(ctx, *next)
+	if err != nil {
+		return errMsg(err)
+	}
 	return scheduleMsg(race)
 }
 
-func fetchStandings() tea.Msg {
-	entries, err := nascar.FetchStandings()
+func fetchStandings(ctx context.Context) tea.Msg {
+	entries, err := nascar.FetchStandings(ctx)
 	if err != nil {
 		return errMsg(err)
 	}
@@ -147,23 +218,23 @@ func tickCmd(d time.Duration) tea.Cmd {
 	})
 }
 
-func fetchFeed() tea.Msg {
-	feed, err := nascar.FetchLiveFeed()
+func fetchFeed(ctx context.Context) tea.Msg {
+	feed, err := nascar.FetchLiveFeedCached(ctx)
 	if err != nil {
 		return errMsg(err)
 	}
 	return feedMsg(feed)
 }
 
-func fetchF1Live() tea.Msg {
+func fetchF1Live(ctx context.Context) tea.Msg {
 	s := f1.NewSeries()
-	state, _ := s.FetchLiveState()
+	state, _ := s.FetchLiveState(ctx)
 	return f1LiveStateMsg{state: state}
 }
 
-func fetchF1Schedule() tea.Msg {
+func fetchF1Schedule(ctx context.Context) tea.Msg {
 	s := f1.NewSeries()
-	races, _ := s.FetchSchedule(time.Now().Year())
+	races, _ := series.SeasonSchedule(ctx, s, time.Now())
 	return f1ScheduleMsg{races: races}
 }
 
@@ -174,16 +245,16 @@ func weatherTickCmd() tea.Cmd {
 }
 
 func fetchWeatherCmd(lat, lon float64) tea.Cmd {
-	return func() tea.Msg {
+	return tracked(sourceWeather, func(ctx context.Context) tea.Msg {
 		if lat == 0 && lon == 0 {
 			return weatherMsg(nil)
 		}
-		cond, err := weather.FetchCurrent(lat, lon)
+		cond, err := weather.FetchCurrent(ctx, lat, lon)
 		if err != nil {
 			return weatherMsg(nil)
 		}
 		return weatherMsg(cond)
-	}
+	})
 }
 
 func (m Model) weatherCoords() (float64, float64) {
@@ -211,11 +282,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 
+	case sourceMsg:
+		m.setStale(msg.source, msg.stale)
+		m.drift = schema.Problems()
+		return m.Update(msg.msg)
+
 	case tickMsg:
-		return m, tea.Batch(fetchFeed, fetchF1Live, tickCmd(m.tickInterval()))
+		return m, tea.Batch(fetchFeedCmd, fetchF1LiveCmd, tickCmd(m.tickInterval()))
 
 	case feedMsg:
-		feed := (*nascar.LiveFeed)(msg)
+		now := time.Now()
+		m.feedBuf.Push(now, (*nascar.LiveFeed)(msg))
+		feed, _ := m.feedBuf.At(now.Add(-m.delay))
 		m.feed = feed
 		m.err = nil
 		if feed == nil || !feed.IsLiveCupRace() {
@@ -246,7 +324,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.standings = msg
 
 	case f1LiveStateMsg:
-		m.f1Live = msg.state
+		now := time.Now()
+		m.f1Buf.Push(now, msg.state)
+		m.f1Live, _ = m.f1Buf.At(now.Add(-m.delay))
 		if !m.seriesLocked {
 			m.autoDetectSeries()
 		}
@@ -297,6 +377,10 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.jumpToFavorite()
 	case key.Matches(msg, keys.SwitchSeries):
 		m.switchSeries()
+	case key.Matches(msg, keys.DelayUp):
+		return m.setDelay(m.delay + delay.Step)
+	case key.Matches(msg, keys.DelayDown):
+		return m.setDelay(m.delay - delay.Step)
 	case key.Matches(msg, keys.View1):
 		if m.activeSeries == SeriesF1 {
 			m.activeView = ViewF1Leaderboard
@@ -321,6 +405,31 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// delaySettingPath is where a delay chosen in the TUI is saved for the
+// status line. Tests replace it.
+var delaySettingPath = delay.SettingPath
+
+// setDelay changes how far behind the feeds the live views run, replays
+// the buffered data from that far back and saves the delay so the status
+// line follows it.
+func (m Model) setDelay(d time.Duration) (tea.Model, tea.Cmd) {
+	m.delay = delay.Clamp(d)
+	now := time.Now()
+	if feed, ok := m.feedBuf.At(now.Add(-m.delay)); ok {
+		m.feed = feed
+	}
+	if st, ok := m.f1Buf.At(now.Add(-m.delay)); ok {
+		m.f1Live = st
+	}
+	d = m.delay
+	return m, func() tea.Msg {
+		if err := delay.SaveSetting(delaySettingPath(), d); err != nil {
+			slog.Warn("delay not saved", "err", err)
+		}
+		return nil
+	}
+}
+
 func (m *Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.Type {
 	case tea.KeyEsc:
@@ -353,6 +462,8 @@ var keys = struct {
 	View2        key.Binding
 	View3        key.Binding
 	View4        key.Binding
+	DelayUp      key.Binding
+	DelayDown    key.Binding
 }{
 	Quit:         key.NewBinding(key.WithKeys("q", "ctrl+c")),
 	Up:           key.NewBinding(key.WithKeys("k", "up")),
@@ -365,6 +476,8 @@ var keys = struct {
 	View2:        key.NewBinding(key.WithKeys("2")),
 	View3:        key.NewBinding(key.WithKeys("3")),
 	View4:        key.NewBinding(key.WithKeys("4")),
+	DelayUp:      key.NewBinding(key.WithKeys("+", "=")),
+	DelayDown:    key.NewBinding(key.WithKeys("-", "_")),
 }
 
 func (m *Model) jumpToFavorite() {
@@ -439,7 +552,11 @@ func (m Model) vehicleCount() int {
 
 func (m Model) visibleRows() int {
 	// header bar (3 lines) + column header (1) + status bar (1)
-	return m.height - 5
+	rows := m.height - 5
+	if len(m.drift) > 0 {
+		rows-- // schema warning
+	}
+	return rows
 }
 
 func (m Model) sortedVehicles() []nascar.Vehicle {
@@ -476,6 +593,50 @@ func (m Model) View() string {
 		return ""
 	}
 
+	var content string
+	// Schedules give nothing away; every other view shows positions.
+	if race := m.hiddenRace(); race != "" && m.activeView != ViewSchedule && m.activeView != ViewF1Schedule {
+		content = renderSpoilerView(race, m.width)
+	} else {
+		content = m.renderView()
+	}
+
+	if warning := m.renderDriftWarning(); warning != "" {
+		content += "\n" + warning
+	}
+	return content + "\n" + m.renderStatusBar()
+}
+
+// hiddenRace returns the name of the spoiler-free race behind the active
+// series' positions, standings and entry list, or "" if they can be shown.
+func (m Model) hiddenRace() string {
+	year := time.Now().Year()
+	if m.activeSeries == SeriesF1 {
+		if st := m.f1Live; st != nil && m.spoilers.Hides(year, st.RaceName, st.TrackName) {
+			return st.RaceName
+		}
+		return ""
+	}
+	// The feed keeps the last race after it ends, and standings include
+	// its points, so both stay hidden until it is watched.
+	if f := m.feed; f != nil && f.RunName != "" && m.spoilers.Hides(year, f.RunName, f.TrackName) {
+		return f.RunName
+	}
+	return ""
+}
+
+// renderSpoilerView stands in for a spoiler-free race's positions.
+func renderSpoilerView(race string, width int) string {
+	text := fmt.Sprintf("🙈 %s is spoiler-free", race)
+	hint := fmt.Sprintf("Positions and results are hidden. Run `raceday spoilers watched %q` once you've seen it.", race)
+	if width > 0 {
+		hint = runewidth.Truncate(hint, width, "…")
+	}
+	return titleStyle.Render(text) + "\n\n" + dimStyle.Render(hint)
+}
+
+// renderView renders the active view.
+func (m Model) renderView() string {
 	var content string
 	switch m.activeView {
 	case ViewF1Leaderboard:
@@ -501,8 +662,25 @@ func (m Model) View() string {
 			content = m.renderLeaderboard()
 		}
 	}
+	return content
+}
 
-	return content + "\n" + m.renderStatusBar()
+// renderDriftWarning flags feeds that no longer match the expected
+// schema, since their data may be silently wrong.
+func (m Model) renderDriftWarning() string {
+	if len(m.drift) == 0 {
+		return ""
+	}
+	feeds := make([]string, len(m.drift))
+	for i, e := range m.drift {
+		feeds[i] = e.Feed
+	}
+	text := fmt.Sprintf("⚠ Unexpected data from %s (%s) — run `raceday doctor --feeds`",
+		strings.Join(feeds, ", "), m.drift[0].Issues[0])
+	if m.width > 0 {
+		text = runewidth.Truncate(text, m.width, "…")
+	}
+	return warnStyle.Render(text)
 }
 
 func (m Model) renderLeaderboard() string {
@@ -689,6 +867,75 @@ func (m Model) renderRow(v nascar.Vehicle, selected bool) string {
 	}
 }
 
+// setStale records whether source's latest data came from stale cache.
+// The map is copied so earlier Model values are left untouched.
+func (m *Model) setStale(source string, since time.Time) {
+	if _, ok := m.stale[source]; !ok && since.IsZero() {
+		return
+	}
+	next := make(map[string]time.Time, len(m.stale)+1)
+	for k, v := range m.stale {
+		next[k] = v
+	}
+	if since.IsZero() {
+		delete(next, source)
+	} else {
+		next[source] = since
+	}
+	m.stale = next
+}
+
+// viewSources lists the data sources shown by the active view.
+func (m Model) viewSources() []string {
+	switch m.activeView {
+	case ViewLeaderboard, ViewEntryList:
+		return []string{sourceFeed, sourceWeather}
+	case ViewSchedule:
+		return []string{sourceSchedule, sourceWeather}
+	case ViewStandings:
+		return []string{sourceStandings}
+	case ViewF1Leaderboard:
+		return []string{sourceF1Live, sourceWeather}
+	case ViewF1Schedule:
+		return []string{sourceF1Schedule}
+	}
+	return nil
+}
+
+// staleLabel describes cached data shown by the active view that is past
+// its freshness window, e.g. "⚠ cached: feed 12m old". Empty when fresh.
+func (m Model) staleLabel(now time.Time) string {
+	var names []string
+	var oldest time.Time
+	for _, src := range m.viewSources() {
+		since, ok := m.stale[src]
+		if !ok {
+			continue
+		}
+		names = append(names, src)
+		if oldest.IsZero() || since.Before(oldest) {
+			oldest = since
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("⚠ cached: %s %s old", strings.Join(names, ", "), formatStaleAge(now.Sub(oldest)))
+}
+
+func formatStaleAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
 func (m Model) renderStatusBar() string {
 	var viewTabs []string
 	if m.activeSeries == SeriesF1 {
@@ -713,7 +960,7 @@ func (m Model) renderStatusBar() string {
 		seriesLabel = "F1"
 	}
 
-	left := fmt.Sprintf("[%s] %s  s:series  q:quit", seriesLabel, strings.Join(viewTabs, " "))
+	left := fmt.Sprintf("[%s] %s  s:series  +/-:delay  q:quit", seriesLabel, strings.Join(viewTabs, " "))
 	if m.activeView == ViewLeaderboard {
 		left += "  j/k:scroll  /:search  tab:sort  f:fav"
 	}
@@ -722,11 +969,23 @@ func (m Model) renderStatusBar() string {
 	}
 
 	right := ""
-	if m.activeSeries == SeriesNASCAR && m.favDriver != "" && m.feed != nil {
+	if m.activeSeries == SeriesNASCAR && m.favDriver != "" && m.feed != nil && m.hiddenRace() == "" {
 		if v := m.feed.FindDriver(m.favDriver); v != nil {
 			right = fmt.Sprintf("#%s %s P%d", v.VehicleNumber, v.Driver.LastName, v.RunningPosition)
 		}
 	}
+	if m.delay > 0 {
+		if right != "" {
+			right += "  "
+		}
+		right += fmt.Sprintf("⏱ %s behind", m.delay)
+	}
+	if label := m.staleLabel(time.Now()); label != "" {
+		if right != "" {
+			right += "  "
+		}
+		right += label
+	}
 
 	gap := m.width - lipgloss.Width(left) - lipgloss.Width(right)
 	if gap < 0 {
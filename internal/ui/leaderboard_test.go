@@ -1,11 +1,18 @@
 package ui
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jfmyers/tmux-raceday/internal/config"
+	"github.com/jfmyers/tmux-raceday/internal/delay"
 	"github.com/jfmyers/tmux-raceday/internal/nascar"
+	"github.com/jfmyers/tmux-raceday/internal/schema"
 	"github.com/jfmyers/tmux-raceday/internal/series"
+	"github.com/jfmyers/tmux-raceday/internal/spoiler"
 	"github.com/jfmyers/tmux-raceday/internal/weather"
 )
 
@@ -112,3 +119,105 @@ func testUpdateReturnsModel(t *testing.T, m Model, msg tea.Msg) Model {
 	result, _ := m.Update(msg)
 	return result.(Model)
 }
+
+func TestSourceMsgTracksStaleness(t *testing.T) {
+	now := time.Date(2026, 5, 24, 18, 0, 0, 0, time.UTC)
+	m := Model{}
+
+	m = testUpdateReturnsModel(t, m, sourceMsg{source: sourceFeed, stale: now.Add(-12 * time.Minute), msg: feedMsg(liveFeed())})
+	if m.feed == nil {
+		t.Fatal("inner feedMsg was not applied")
+	}
+	if got, want := m.staleLabel(now), "⚠ cached: feed 12m old"; got != want {
+		t.Errorf("staleLabel = %q, want %q", got, want)
+	}
+
+	m.activeView = ViewStandings
+	if got := m.staleLabel(now); got != "" {
+		t.Errorf("standings view should not show feed staleness, got %q", got)
+	}
+
+	m.activeView = ViewLeaderboard
+	m = testUpdateReturnsModel(t, m, sourceMsg{source: sourceFeed, msg: feedMsg(liveFeed())})
+	if got := m.staleLabel(now); got != "" {
+		t.Errorf("fresh data should clear the marker, got %q", got)
+	}
+}
+
+func TestDriftWarning(t *testing.T) {
+	m := Model{width: 200, height: 30}
+	if m.renderDriftWarning() != "" {
+		t.Error("no warning expected without schema problems")
+	}
+	rows := m.visibleRows()
+
+	m.drift = []*schema.Error{{Feed: "nascar live feed", Issues: []schema.Issue{{Path: "lap_number", Msg: "missing"}}}}
+	got := m.renderDriftWarning()
+	for _, want := range []string{"nascar live feed", "lap_number: missing", "doctor --feeds"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("warning %q does not mention %q", got, want)
+		}
+	}
+	if m.visibleRows() != rows-1 {
+		t.Errorf("visibleRows = %d, want %d to make room for the warning", m.visibleRows(), rows-1)
+	}
+}
+
+func TestSpoilerFreeView(t *testing.T) {
+	feed := liveFeed()
+	feed.RunName = "Würth 400"
+	feed.TrackName = "Dover Motor Speedway"
+	feed.Vehicles = []nascar.Vehicle{{VehicleNumber: "24", RunningPosition: 1, Driver: nascar.DriverInfo{LastName: "Byron"}}}
+	filter := &spoiler.Filter{Config: config.SpoilerFree{Races: []string{"dover"}}}
+	m := NewModel(24, filter, 0, series.DefaultHorizon)
+	m.feed, m.width, m.height = feed, 120, 30
+
+	for _, view := range []int{ViewLeaderboard, ViewEntryList, ViewStandings} {
+		m.activeView = view
+		got := m.View()
+		if !strings.Contains(got, "Würth 400 is spoiler-free") || strings.Contains(got, "Byron") {
+			t.Errorf("view %d shows spoilers:\n%s", view, got)
+		}
+	}
+	m.activeView = ViewSchedule
+	if strings.Contains(m.View(), "spoiler-free") {
+		t.Error("schedule view hidden")
+	}
+
+	filter.Watch(spoiler.Race{Year: time.Now().Year(), Name: "Würth 400"})
+	m.activeView = ViewLeaderboard
+	if got := m.View(); !strings.Contains(got, "Byron") {
+		t.Errorf("watched race still hidden:\n%s", got)
+	}
+}
+
+func TestBroadcastDelay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "delay.json")
+	delaySettingPath = func() string { return path }
+	t.Cleanup(func() { delaySettingPath = delay.SettingPath })
+
+	m := NewModel(0, nil, 30*time.Second, series.DefaultHorizon)
+	old, cur := liveFeed(), liveFeed()
+	old.LapNumber, cur.LapNumber = 48, 50
+	m.feedBuf.Push(time.Now().Add(-40*time.Second), old)
+	m = testUpdateReturnsModel(t, m, feedMsg(cur))
+	if m.feed != old {
+		t.Fatalf("lap %d shown, want the state from 30s ago (lap 48)", m.feed.LapNumber)
+	}
+	if bar := m.renderStatusBar(); !strings.Contains(bar, "30s behind") {
+		t.Errorf("status bar = %q", bar)
+	}
+
+	// Dropping the delay live replays the newer state and saves it.
+	for range 6 {
+		result, cmd := m.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}})
+		m = result.(Model)
+		cmd()
+	}
+	if m.delay != 0 || m.feed != cur {
+		t.Errorf("delay %s, lap %d; want 0 and lap 50", m.delay, m.feed.LapNumber)
+	}
+	if got := delay.Setting(path, time.Minute); got != 0 {
+		t.Errorf("saved delay = %s, want 0", got)
+	}
+}
@@ -0,0 +1,235 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// tmux style sequences such as #[fg=black,bg=yellow] change how the
+// status line is drawn but take up no columns. The helpers here measure
+// and cut styled text by what is actually displayed.
+
+// StyleDefault resets tmux styling to the status line default.
+const StyleDefault = "#[default]"
+
+// StripStyles removes tmux #[...] style sequences from s.
+func StripStyles(s string) string {
+	if !strings.Contains(s, "#[") {
+		return s
+	}
+	var b strings.Builder
+	for _, c := range styledCells(s) {
+		b.WriteRune(c.r)
+	}
+	return b.String()
+}
+
+// DisplayWidth returns the number of columns s occupies once tmux style
+// sequences are removed.
+func DisplayWidth(s string) int {
+	return runewidth.StringWidth(StripStyles(s))
+}
+
+// Styled wraps text in a tmux style, resetting to the default after it.
+// An empty style returns text unchanged.
+func Styled(style, text string) string {
+	if style == "" || text == "" {
+		return text
+	}
+	return "#[" + style + "]" + text + StyleDefault
+}
+
+// cell is one displayed rune and the style sequences in effect for it.
+type cell struct {
+	r     rune
+	style string
+}
+
+// styledCells splits s into displayed runes, each carrying the style
+// sequences set since the last #[default].
+func styledCells(s string) []cell {
+	var cells []cell
+	style := ""
+	for len(s) > 0 {
+		if strings.HasPrefix(s, "#[") {
+			if end := strings.IndexByte(s, ']'); end > 0 {
+				seq := s[:end+1]
+				if seq == StyleDefault {
+					style = ""
+				} else {
+					style += seq
+				}
+				s = s[end+1:]
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(s)
+		cells = append(cells, cell{r, style})
+		s = s[size:]
+	}
+	return cells
+}
+
+// joinCells renders cells back to text, emitting style sequences only
+// where the style changes and resetting at the end.
+func joinCells(cells []cell) string {
+	var b strings.Builder
+	cur := ""
+	for _, c := range cells {
+		if c.style != cur {
+			if cur != "" {
+				b.WriteString(StyleDefault)
+			}
+			b.WriteString(c.style)
+			cur = c.style
+		}
+		b.WriteRune(c.r)
+	}
+	if cur != "" {
+		b.WriteString(StyleDefault)
+	}
+	return b.String()
+}
+
+// fitCells returns the longest prefix of cells that fits in width columns.
+func fitCells(cells []cell, width int) []cell {
+	w := 0
+	for i, c := range cells {
+		rw := runewidth.RuneWidth(c.r)
+		if w+rw > width {
+			return cells[:i]
+		}
+		w += rw
+	}
+	return cells
+}
+
+// padStyled is PadToWidth for text containing style sequences.
+func padStyled(text string, width int) string {
+	cells := styledCells(text)
+	out := text
+	if DisplayWidth(text) > width {
+		const ellipsis = "..."
+		if width <= len(ellipsis) {
+			return ellipsis[:width]
+		}
+		out = joinCells(fitCells(cells, width-len(ellipsis))) + ellipsis
+	}
+	if w := DisplayWidth(out); w < width {
+		out += strings.Repeat(" ", width-w)
+	}
+	return out
+}
+
+// marqueeStyled is MarqueeText for text containing style sequences. The
+// window scrolls over displayed runes; styles travel with their runes.
+func marqueeStyled(text string, width int, position int64, separator string) string {
+	cells := append(styledCells(text), styledCells(separator)...)
+	cells = append(cells, styledCells(text)...)
+	start := int(position % int64(len(cells)))
+	window := make([]cell, 0, len(cells))
+	for i := range cells {
+		window = append(window, cells[(start+i)%len(cells)])
+	}
+	out := joinCells(fitCells(window, width))
+	if w := DisplayWidth(out); w < width {
+		out += strings.Repeat(" ", width-w)
+	}
+	return out
+}
+
+// ConvertStyles rewrites s for another status bar: each tmux style
+// sequence is replaced by style(spec), where spec is the text between
+// "#[" and "]" ("default" for a reset), and the text between sequences
+// by text(run). A nil text keeps runs unchanged.
+func ConvertStyles(s string, style func(spec string) string, text func(run string) string) string {
+	if text == nil {
+		text = func(run string) string { return run }
+	}
+	var b strings.Builder
+	for len(s) > 0 {
+		i := strings.Index(s, "#[")
+		end := -1
+		if i >= 0 {
+			end = strings.IndexByte(s[i:], ']')
+		}
+		if i < 0 || end < 0 {
+			b.WriteString(text(s))
+			break
+		}
+		b.WriteString(text(s[:i]))
+		b.WriteString(style(s[i+2 : i+end]))
+		s = s[i+end+1:]
+	}
+	return b.String()
+}
+
+// StyleColors returns the foreground and background set by a tmux style
+// such as "fg=black,bg=yellow,bold", as #rrggbb. Unset or unknown
+// colours are empty.
+func StyleColors(spec string) (fg, bg string) {
+	for _, attr := range strings.Split(spec, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(attr), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "fg":
+			fg = HexColor(val)
+		case "bg":
+			bg = HexColor(val)
+		}
+	}
+	return fg, bg
+}
+
+// ansiColors are xterm's default values for the 16 named colours.
+var ansiColors = []string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+var colorNames = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// HexColor converts a tmux colour — a name such as "yellow" or
+// "brightred", "colour208", or "#ff8700" — to #rrggbb. It returns ""
+// for "default" and anything it doesn't recognise.
+func HexColor(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if strings.HasPrefix(name, "#") && len(name) == 7 {
+		return name
+	}
+	for i, n := range colorNames {
+		switch name {
+		case n:
+			return ansiColors[i]
+		case "bright" + n:
+			return ansiColors[i+8]
+		}
+	}
+	num, ok := strings.CutPrefix(name, "colour")
+	if !ok {
+		num, ok = strings.CutPrefix(name, "color")
+	}
+	if !ok {
+		return ""
+	}
+	var n int
+	if _, err := fmt.Sscanf(num, "%d", &n); err != nil || n < 0 || n > 255 {
+		return ""
+	}
+	switch {
+	case n < 16:
+		return ansiColors[n]
+	case n < 232:
+		levels := []int{0, 95, 135, 175, 215, 255}
+		n -= 16
+		return fmt.Sprintf("#%02x%02x%02x", levels[n/36], levels[n/6%6], levels[n%6])
+	default:
+		v := 8 + 10*(n-232)
+		return fmt.Sprintf("#%02x%02x%02x", v, v, v)
+	}
+}
@@ -10,11 +10,15 @@ import (
 // PadToWidth pads or truncates text to a fixed display width.
 // Uses display columns (not bytes/runes), so emoji and CJK are handled correctly.
 // Text longer than width is truncated with "..." suffix.
-// Text shorter than width is padded with spaces.
+// Text shorter than width is padded with spaces. tmux #[...] style
+// sequences take up no width and are kept intact.
 func PadToWidth(text string, width int) string {
 	if width <= 0 {
 		return text
 	}
+	if strings.Contains(text, "#[") {
+		return padStyled(text, width)
+	}
 
 	currentWidth := runewidth.StringWidth(text)
 
@@ -47,17 +51,19 @@ func PadToWidth(text string, width int) string {
 
 // MarqueeText creates a scrolling marquee effect for text exceeding the target width.
 // Position is derived from wall-clock time (stateless), so each tmux refresh
-// advances the scroll by speed * status-interval characters.
+// advances the scroll by speed * status-interval characters. tmux style
+// sequences scroll along with the text they apply to.
 func MarqueeText(text string, width int, speed int, separator string) string {
 	if width <= 0 {
 		return text
 	}
 
-	textWidth := runewidth.StringWidth(text)
-
-	if textWidth <= width {
+	if DisplayWidth(text) <= width {
 		return PadToWidth(text, width)
 	}
+	if strings.Contains(text, "#[") {
+		return marqueeStyled(text, width, time.Now().Unix()*int64(speed), separator)
+	}
 
 	extended := text + separator + text
 	extendedRunes := []rune(extended)
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/mattn/go-runewidth"
@@ -68,3 +69,84 @@ func TestMarqueeText_Deterministic(t *testing.T) {
 		t.Errorf("wrong width: %d", runewidth.StringWidth(r1))
 	}
 }
+
+func TestStyledWidth(t *testing.T) {
+	s := Styled("fg=black,bg=yellow", "🟡 DAYTONA 500") + " | " + Styled("fg=cyan", "#24 P6")
+	if got := StripStyles(s); got != "🟡 DAYTONA 500 | #24 P6" {
+		t.Errorf("StripStyles = %q", got)
+	}
+	if got := DisplayWidth(s); got != runewidth.StringWidth("🟡 DAYTONA 500 | #24 P6") {
+		t.Errorf("DisplayWidth = %d", got)
+	}
+	if got := Styled("", "plain"); got != "plain" {
+		t.Errorf("Styled with no style = %q", got)
+	}
+}
+
+func TestPadToWidthStyled(t *testing.T) {
+	s := "#[bg=yellow]CAUTION#[default] Lap 12"
+	tests := []struct {
+		width int
+		want  string
+	}{
+		{20, "#[bg=yellow]CAUTION#[default] Lap 12      "},
+		{10, "#[bg=yellow]CAUTION#[default]..."},
+		{5, "#[bg=yellow]CA#[default]..."},
+	}
+	for _, tt := range tests {
+		got := PadToWidth(s, tt.width)
+		if got != tt.want {
+			t.Errorf("PadToWidth(%d) = %q, want %q", tt.width, got, tt.want)
+		}
+		if w := DisplayWidth(got); w != tt.width {
+			t.Errorf("PadToWidth(%d) display width = %d", tt.width, w)
+		}
+	}
+}
+
+func TestMarqueeStyled(t *testing.T) {
+	s := "#[fg=red]RED#[default] FLAG"
+	got := marqueeStyled(s, 6, 1, " ")
+	if got != "#[fg=red]ED#[default] FLA" {
+		t.Errorf("position 1 = %q", got)
+	}
+	got = marqueeStyled(s, 6, 7, " ")
+	if got != "G #[fg=red]RED#[default] " {
+		t.Errorf("position 7 = %q", got)
+	}
+	if w := DisplayWidth(got); w != 6 {
+		t.Errorf("display width = %d, want 6", w)
+	}
+}
+
+func TestConvertStyles(t *testing.T) {
+	s := "#[fg=black,bg=yellow]A&B#[default] | C"
+	got := ConvertStyles(s, func(spec string) string { return "<" + spec + ">" }, strings.ToLower)
+	if got != "<fg=black,bg=yellow>a&b<default> | c" {
+		t.Errorf("ConvertStyles = %q", got)
+	}
+	if got := ConvertStyles("no styles", nil, nil); got != "no styles" {
+		t.Errorf("plain text = %q", got)
+	}
+}
+
+func TestHexColor(t *testing.T) {
+	tests := map[string]string{
+		"yellow":    "#cdcd00",
+		"brightred": "#ff0000",
+		"colour208": "#ff8700",
+		"color244":  "#808080",
+		"colour3":   "#cdcd00",
+		"#AbCdEf":   "#abcdef",
+		"default":   "",
+		"colour300": "",
+	}
+	for in, want := range tests {
+		if got := HexColor(in); got != want {
+			t.Errorf("HexColor(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if fg, bg := StyleColors("fg=black,bg=yellow,bold"); fg != "#000000" || bg != "#cdcd00" {
+		t.Errorf("StyleColors = %q, %q", fg, bg)
+	}
+}
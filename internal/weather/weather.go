@@ -1,20 +1,20 @@
 package weather
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"strings"
 	"time"
 
 	"github.com/jfmyers/tmux-raceday/internal/cache"
+	"github.com/jfmyers/tmux-raceday/internal/fetch"
 )
 
 const cacheTTL = 10 * time.Minute
 
 var (
-	httpClient = &http.Client{Timeout: 10 * time.Second}
-	fileCache  = cache.New("")
+	fileCache = cache.New("")
 )
 
 type Conditions struct {
@@ -40,7 +40,7 @@ type apiResponse struct {
 }
 
 // FetchCurrent retrieves current weather conditions from Open-Meteo.
-func FetchCurrent(lat, lon float64) (*Conditions, error) {
+func FetchCurrent(ctx context.Context, lat, lon float64) (*Conditions, error) {
 	key := fmt.Sprintf("weather_%.4f_%.4f.json", lat, lon)
 
 	if data, ok := fileCache.Read(key, cacheTTL); ok {
@@ -50,27 +50,64 @@ func FetchCurrent(lat, lon float64) (*Conditions, error) {
 		}
 	}
 
+	data, err := fileCache.Refresh(ctx, key, cacheTTL, func() ([]byte, error) {
+		c, err := fetchConditions(ctx, lat, lon)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(c)
+	})
+	if err != nil {
+		// Fall back to stale cache on API failure.
+		stale := fileCache.Fallback(ctx, key)
+		if stale == nil {
+			return nil, err
+		}
+		data = stale
+	}
+
+	var c Conditions
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("weather parse: %w", err)
+	}
+	return &c, nil
+}
+
+// defaultBaseURL is the Open-Meteo API root.
+const defaultBaseURL = "https://api.open-meteo.com/v1"
+
+// baseURL is where weather requests go. Override it with SetBaseURL.
+var baseURL = defaultBaseURL
+
+// SetBaseURL points weather requests at url, e.g. a mirror or fixture
+// server, and caches their responses apart from Open-Meteo's. An empty
+// url keeps the current one.
+func SetBaseURL(url string) {
+	if url != "" {
+		baseURL = strings.TrimSuffix(url, "/")
+		fileCache = cache.NewFor("", baseURL, defaultBaseURL)
+	}
+}
+
+// ProbeURL returns a lightweight URL for checking that Open-Meteo is
+// reachable.
+func ProbeURL() string {
+	return baseURL + "/forecast?latitude=0&longitude=0&current=temperature_2m"
+}
+
+// fetchConditions queries Open-Meteo for the current conditions at lat/lon.
+func fetchConditions(ctx context.Context, lat, lon float64) (*Conditions, error) {
 	url := fmt.Sprintf(
-		"https://api.open-meteo.com/v1/forecast?latitude=%.4f&longitude=%.4f"+
+		"%s/forecast?latitude=%.4f&longitude=%.4f"+
 			"&current=temperature_2m,weather_code,wind_speed_10m,wind_gusts_10m,precipitation,wind_direction_10m,apparent_temperature"+
 			"&temperature_unit=fahrenheit&wind_speed_unit=mph&precipitation_unit=inch",
-		lat, lon,
+		baseURL, lat, lon,
 	)
 
-	resp, err := httpClient.Get(url)
+	body, err := fetch.Get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("weather fetch: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("weather fetch: status %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("weather read body: %w", err)
-	}
 
 	var api apiResponse
 	if err := json.Unmarshal(body, &api); err != nil {
@@ -87,10 +124,6 @@ func FetchCurrent(lat, lon float64) (*Conditions, error) {
 		FeelsLike:     api.Current.FeelsLike,
 	}
 
-	if data, err := json.Marshal(c); err == nil {
-		_ = fileCache.Write(key, data)
-	}
-
 	return c, nil
 }
 
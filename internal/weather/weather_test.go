@@ -1,6 +1,7 @@
 package weather
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
@@ -92,7 +93,7 @@ func TestFetchCurrent_Integration(t *testing.T) {
 	}
 
 	// Daytona International Speedway
-	c, err := FetchCurrent(29.1872, -81.0715)
+	c, err := FetchCurrent(context.Background(), 29.1872, -81.0715)
 	if err != nil {
 		t.Fatalf("FetchCurrent: %v", err)
 	}